@@ -50,18 +50,17 @@ func llvmBenchRoot(b *testing.B) string {
 
 func llvmBenchProducerConfig(root string) candidate.ProducerConfig {
 	return candidate.ProducerConfig{
-		Root:         root,
-		Pattern:      candidate.DefaultRGPattern,
-		ExcludeTests: true,
+		Root:    root,
+		Pattern: candidate.DefaultRGPattern,
 	}
 }
 
-func withIndexCachePathForBench(b *testing.B, path string) {
+func withIndexCacheDirForBench(b *testing.B, dir string) {
 	b.Helper()
-	old := indexCachePathOverride
-	indexCachePathOverride = path
+	old := indexCacheDirOverride
+	indexCacheDirOverride = dir
 	b.Cleanup(func() {
-		indexCachePathOverride = old
+		indexCacheDirOverride = old
 	})
 }
 
@@ -78,8 +77,8 @@ func loadCandidatesForRoot(b *testing.B, root string) []candidate.Candidate {
 		candidates = append(candidates, batch...)
 	}
 
-	if err, ok := <-done; ok && err != nil {
-		b.Fatalf("producer failed: %v", err)
+	if result, ok := <-done; ok && result.Err != nil {
+		b.Fatalf("producer failed: %v", result.Err)
 	}
 	if len(candidates) == 0 {
 		b.Fatalf("producer returned zero candidates")
@@ -351,8 +350,7 @@ func BenchmarkLLVMIndexCacheSave(b *testing.B) {
 	root := llvmBenchRoot(b)
 	cfg := llvmBenchProducerConfig(root)
 	candidates := loadCandidatesForRoot(b, root)
-	cachePath := filepath.Join(b.TempDir(), "last_index.gob")
-	withIndexCachePathForBench(b, cachePath)
+	withIndexCacheDirForBench(b, b.TempDir())
 
 	b.ReportAllocs()
 	b.ResetTimer()
@@ -370,8 +368,7 @@ func BenchmarkLLVMIndexCacheLoad(b *testing.B) {
 	root := llvmBenchRoot(b)
 	cfg := llvmBenchProducerConfig(root)
 	candidates := loadCandidatesForRoot(b, root)
-	cachePath := filepath.Join(b.TempDir(), "last_index.gob")
-	withIndexCachePathForBench(b, cachePath)
+	withIndexCacheDirForBench(b, b.TempDir())
 
 	if err := SaveIndexCache(cfg, candidates); err != nil {
 		b.Fatalf("SaveIndexCache setup failed: %v", err)
@@ -400,8 +397,7 @@ func BenchmarkLLVMWarmStartFirstQuery(b *testing.B) {
 	root := llvmBenchRoot(b)
 	cfg := llvmBenchProducerConfig(root)
 	candidates := loadCandidatesForRoot(b, root)
-	cachePath := filepath.Join(b.TempDir(), "last_index.gob")
-	withIndexCachePathForBench(b, cachePath)
+	withIndexCacheDirForBench(b, b.TempDir())
 	query := "LLVMContext"
 
 	if err := SaveIndexCache(cfg, candidates); err != nil {
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"snav/internal/candidate"
+	"snav/internal/gitrev"
+	"snav/internal/highlighter"
+	"snav/internal/readfile"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// symbolDiff is the symbols that changed between two revisions of Root.
+// From and To are the revisions as given, FromRev and ToRev their commits.
+type symbolDiff struct {
+	Root    string
+	From    string
+	To      string
+	FromRev string
+	ToRev   string
+	Changes []candidate.SymbolChange
+}
+
+type diffOptions struct {
+	root         string
+	json         bool
+	tui          bool
+	excludeTests bool
+	members      bool
+	theme        string
+}
+
+func runDiffCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	var opts diffOptions
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.root, "root", ".", "repository root")
+	fs.BoolVar(&opts.json, "json", false, "print the changes as JSON")
+	fs.BoolVar(&opts.tui, "tui", false, "browse the changes, with a preview of both versions")
+	fs.BoolVar(&opts.excludeTests, "exclude-tests", false, "leave test code out of the diff")
+	fs.BoolVar(&opts.members, "members", false, "also compare exported struct fields and interface methods")
+	fs.StringVar(&opts.theme, "theme", "nord", "color theme for --tui")
+	fs.Usage = func() {
+		printDiffUsage(stderr, fs)
+	}
+
+	// Flags may follow the revisions, as in `snav diff v1.4 HEAD --json`.
+	var revs []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		revs = append(revs, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(revs) != 2 {
+		return fmt.Errorf("diff takes two revisions, such as: snav diff v1.4 HEAD")
+	}
+	if opts.json && opts.tui {
+		return fmt.Errorf("diff accepts --json or --tui, not both")
+	}
+	root, err := filepath.Abs(opts.root)
+	if err != nil {
+		return fmt.Errorf("resolve root %s: %w", opts.root, err)
+	}
+
+	d, err := loadSymbolDiff(ctx, root, revs[0], revs[1], opts)
+	if err != nil {
+		return err
+	}
+	switch {
+	case opts.tui:
+		return runDiffTUI(ctx, d, opts.theme)
+	case opts.json:
+		return writeSymbolDiffJSON(stdout, d)
+	default:
+		return writeSymbolDiff(stdout, d)
+	}
+}
+
+func printDiffUsage(out io.Writer, fs *flag.FlagSet) {
+	if _, err := fmt.Fprintln(out, "Usage of snav diff:"); err != nil {
+		fatalf("write usage: %v", err)
+	}
+	if _, err := fmt.Fprintln(out, "  snav diff [flags] <from> <to>\n\nList the symbols added, removed, changed or moved between two git revisions.\n\nFlags:"); err != nil {
+		fatalf("write usage: %v", err)
+	}
+	var b strings.Builder
+	fs.SetOutput(&b)
+	fs.PrintDefaults()
+	fs.SetOutput(out)
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		if strings.HasPrefix(line, "  -") {
+			line = "  --" + strings.TrimPrefix(line, "  -")
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			fatalf("write usage: %v", err)
+		}
+	}
+}
+
+// loadSymbolDiff indexes both revisions of root, in parallel and through the
+// index cache, and compares their symbols.
+func loadSymbolDiff(ctx context.Context, root string, from string, to string, opts diffOptions) (*symbolDiff, error) {
+	d := &symbolDiff{Root: root, From: from, To: to}
+	var err error
+	if d.FromRev, err = gitrev.Resolve(ctx, root, from); err != nil {
+		return nil, err
+	}
+	if d.ToRev, err = gitrev.Resolve(ctx, root, to); err != nil {
+		return nil, err
+	}
+
+	cfg := candidate.ProducerConfig{
+		Root:              root,
+		Pattern:           candidate.DefaultRGPattern,
+		Members:           opts.members,
+		MaxFileSize:       candidate.DefaultMaxFileSize,
+		MaxFileCandidates: candidate.DefaultMaxFileCandidates,
+	}
+	fromCfg, toCfg := cfg, cfg
+	fromCfg.Rev, toCfg.Rev = d.FromRev, d.ToRev
+
+	type indexed struct {
+		cands []candidate.Candidate
+		err   error
+	}
+	fromDone := make(chan indexed, 1)
+	go func() {
+		cands, err := revisionCandidates(ctx, fromCfg)
+		fromDone <- indexed{cands, err}
+	}()
+	toCands, toErr := revisionCandidates(ctx, toCfg)
+	fromResult := <-fromDone
+	if fromResult.err != nil {
+		return nil, fmt.Errorf("index %s: %w", from, fromResult.err)
+	}
+	if toErr != nil {
+		return nil, fmt.Errorf("index %s: %w", to, toErr)
+	}
+
+	fromCands := fromResult.cands
+	if opts.excludeTests {
+		fromCands, toCands = dropTests(fromCands), dropTests(toCands)
+	}
+	d.Changes = candidate.DiffCandidates(fromCands, toCands)
+	return d, nil
+}
+
+// revisionCandidates returns the index of a revision, scanning it when the
+// index cache has none.
+func revisionCandidates(ctx context.Context, cfg candidate.ProducerConfig) ([]candidate.Candidate, error) {
+	if cached, ok, err := LoadIndexCache(cfg); err == nil && ok {
+		return cached, nil
+	}
+
+	out, done := candidate.StartProducer(ctx, cfg)
+	var cands []candidate.Candidate
+	for batch := range out {
+		cands = append(cands, batch...)
+	}
+	if result := <-done; result.Err != nil {
+		return nil, result.Err
+	}
+	_ = SaveIndexCache(cfg, cands)
+	return cands, nil
+}
+
+func dropTests(cands []candidate.Candidate) []candidate.Candidate {
+	kept := cands[:0:0]
+	for _, cand := range cands {
+		if !cand.Test {
+			kept = append(kept, cand)
+		}
+	}
+	return kept
+}
+
+// changeCounts summarizes the changes, as in "2 added, 1 removed".
+func (d *symbolDiff) changeCounts() string {
+	counts := make(map[candidate.Change]int)
+	for _, change := range d.Changes {
+		counts[change.Change]++
+	}
+	var parts []string
+	for _, kind := range []candidate.Change{candidate.ChangeAdded, candidate.ChangeRemoved, candidate.ChangeChanged, candidate.ChangeMoved} {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[kind], kind))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// writeSymbolDiff prints one change per line, followed by the old and new
+// declaration of changed symbols.
+func writeSymbolDiff(w io.Writer, d *symbolDiff) error {
+	if _, err := fmt.Fprintf(w, "%s..%s: %s\n", d.From, d.To, d.changeCounts()); err != nil {
+		return fmt.Errorf("write diff: %w", err)
+	}
+	nameW := 0
+	for _, change := range d.Changes {
+		nameW = max(nameW, utf8.RuneCountInString(symbolLabel(change.Symbol())))
+	}
+	nameW = min(nameW, 40)
+
+	for _, change := range d.Changes {
+		sym := change.Symbol()
+		line := fmt.Sprintf("%-8s %-*s  %s", change.Change, nameW, symbolLabel(sym), symbolLocation(sym))
+		if change.Change == candidate.ChangeMoved {
+			line += " (was " + symbolLocation(*change.Old) + ")"
+		}
+		if change.Change == candidate.ChangeChanged {
+			line += "\n         - " + symbolDeclaration(*change.Old) + "\n         + " + symbolDeclaration(*change.New)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("write diff: %w", err)
+		}
+	}
+	return nil
+}
+
+type diffReportJSON struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	FromRev string           `json:"from_rev"`
+	ToRev   string           `json:"to_rev"`
+	Changes []diffChangeJSON `json:"changes"`
+}
+
+type diffChangeJSON struct {
+	Change    string          `json:"change"`
+	Key       string          `json:"key"`
+	Kind      string          `json:"kind"`
+	Container string          `json:"container,omitempty"`
+	Old       *diffSymbolJSON `json:"old,omitempty"`
+	New       *diffSymbolJSON `json:"new,omitempty"`
+}
+
+type diffSymbolJSON struct {
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Col         int    `json:"col"`
+	Cell        int    `json:"cell,omitempty"`
+	Declaration string `json:"declaration"`
+}
+
+func writeSymbolDiffJSON(w io.Writer, d *symbolDiff) error {
+	report := diffReportJSON{From: d.From, To: d.To, FromRev: d.FromRev, ToRev: d.ToRev, Changes: []diffChangeJSON{}}
+	for _, change := range d.Changes {
+		sym := change.Symbol()
+		kind := string(sym.Kind)
+		if sym.Kind == candidate.KindCode {
+			kind = "code"
+		}
+		report.Changes = append(report.Changes, diffChangeJSON{
+			Change:    string(change.Change),
+			Key:       sym.Key,
+			Kind:      kind,
+			Container: sym.Container,
+			Old:       diffSymbolOf(change.Old),
+			New:       diffSymbolOf(change.New),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("write diff: %w", err)
+	}
+	return nil
+}
+
+func diffSymbolOf(cand *candidate.Candidate) *diffSymbolJSON {
+	if cand == nil {
+		return nil
+	}
+	return &diffSymbolJSON{File: cand.File, Line: cand.Line, Col: cand.Col, Cell: cand.Cell, Declaration: symbolDeclaration(*cand)}
+}
+
+// symbolLabel names a symbol with its kind when it is not code.
+func symbolLabel(cand candidate.Candidate) string {
+	if cand.Kind == candidate.KindCode {
+		return cand.Key
+	}
+	return string(cand.Kind) + " " + cand.Key
+}
+
+func symbolLocation(cand candidate.Candidate) string {
+	if cand.Cell > 0 {
+		return fmt.Sprintf("%s:cell %d:%d", cand.File, cand.Cell, cand.Line)
+	}
+	return fmt.Sprintf("%s:%d", cand.File, cand.Line)
+}
+
+func symbolDeclaration(cand candidate.Candidate) string {
+	if cand.Signature != "" {
+		return cand.Signature
+	}
+	return strings.TrimSpace(cand.Text)
+}
+
+// runDiffTUI browses the changes in the symbol list. Each change is labelled
+// with its kind, so change:added narrows the list, and the preview shows the
+// symbol in both revisions.
+func runDiffTUI(ctx context.Context, d *symbolDiff, theme string) error {
+	if err := SetTheme(theme); err != nil {
+		return fmt.Errorf("invalid --theme: %w", err)
+	}
+	cfg := config{
+		Root:          d.Root,
+		Preview:       true,
+		CacheSize:     20000,
+		Workers:       max(1, runtime.GOMAXPROCS(0)-1),
+		Debounce:      100 * time.Millisecond,
+		VisibleBuffer: 30,
+		HighlightMode: highlighter.HighlightContextFile,
+		ContextRadius: 40,
+		Theme:         theme,
+	}
+	m := newModel(cfg, nil, nil, nil)
+	m.roots = []workspaceRoot{{Path: d.Root, Rev: d.ToRev}}
+	m.showSymbolDiff(d)
+	roots := m.roots
+	m.highlighter = highlighter.NewHighlighter(highlighter.HighlighterConfig{
+		CacheSize:     cfg.CacheSize,
+		Workers:       cfg.Workers,
+		Root:          cfg.Root,
+		DefaultMode:   cfg.HighlightMode,
+		ContextRadius: cfg.ContextRadius,
+		ReadLines: func(path string) ([]string, error) {
+			data, err := readRootFile(ctx, roots, path)
+			if err != nil {
+				return nil, err
+			}
+			return readfile.SplitLinesNormalized(data), nil
+		},
+	})
+
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("snav failed: %w", err)
+	}
+	return nil
+}
+
+// showSymbolDiff lists the changed symbols, at their newer location or, for
+// removed ones, their older one.
+func (m *model) showSymbolDiff(d *symbolDiff) {
+	m.diff = d
+	m.diffChanges = make(map[int]candidate.SymbolChange, len(d.Changes))
+	for _, change := range d.Changes {
+		cand := change.Symbol()
+		cand.Change = change.Change
+		m.appendCandidate(cand)
+		m.diffChanges[m.nextID] = change
+	}
+	m.scanDone = true
+	m.status = d.changeCounts()
+}
+
+// updateDiffPreview shows the selected symbol in the older revision above
+// the newer one. Only the newer file is highlighted in context, since the
+// highlighter reads files from that revision.
+func (m *model) updateDiffPreview(cand candidate.Candidate) {
+	change, ok := m.diffChanges[cand.ID]
+	if !ok {
+		m.preview, m.diffPreview = previewState{}, previewState{}
+		return
+	}
+	_, _, _, previewH := m.layout()
+	visible := max(1, previewH/2-1)
+	m.diffPreview = m.revisionPreview(change.Old, m.diff.FromRev, m.diff.From, visible)
+	m.preview = m.revisionPreview(change.New, m.diff.ToRev, m.diff.To, visible)
+	if change.New != nil {
+		m.preview.Path = m.candidatePath(*change.New)
+	}
+}
+
+// revisionPreview previews a symbol as of rev, shown as ref.
+func (m *model) revisionPreview(cand *candidate.Candidate, rev string, ref string, visible int) previewState {
+	if cand == nil {
+		return previewState{Err: "not in " + ref}
+	}
+	if cand.Cell > 0 {
+		return previewState{File: cand.File, Err: "notebook cells are not previewed in diffs"}
+	}
+	key := rev + ":" + cand.File
+	fileLines, ok := m.fileCache[key]
+	if !ok {
+		data, err := gitrev.ReadFile(context.Background(), m.cfg.Root, rev, cand.File)
+		if err != nil {
+			return previewState{File: cand.File, Err: err.Error()}
+		}
+		fileLines = readfile.SplitLinesNormalized(data)
+		m.fileCache[key] = fileLines
+	}
+
+	start, end := previewWindowLines(cand.Line, len(fileLines), visible)
+	if start > end {
+		return previewState{File: cand.File, Err: "empty file"}
+	}
+	return previewState{
+		File:         cand.File,
+		Lang:         cand.LangID,
+		StartLine:    start,
+		Lines:        fileLines[start-1 : end],
+		SelectedLine: cand.Line,
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"snav/internal/candidate"
+)
+
+func newDiffRepo(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=snav", "-c", "user.email=snav@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name string, content string) {
+		t.Helper()
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	git("init", "-q")
+	write("server.go", "package app\n\nfunc Serve(addr string) error { return nil }\n\nfunc Legacy() {}\n")
+	write("util.go", "package app\n\nfunc Helper() {}\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	git("tag", "v1")
+	write("server.go", "package app\n\nfunc Serve(ctx context.Context, addr string) error { return nil }\n")
+	if err := os.Remove(filepath.Join(repo, "util.go")); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	write("helpers/util.go", "package helpers\n\nfunc Helper() {}\n\nfunc Dial() {}\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "second")
+	return repo
+}
+
+func TestRunDiffCommandPrintsJSON(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+	repo := newDiffRepo(t)
+
+	var stdout bytes.Buffer
+	if err := runDiffCommand(context.Background(), []string{"--root", repo, "v1", "HEAD", "--json"}, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("runDiffCommand: %v", err)
+	}
+	var report diffReportJSON
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+	}
+	got := map[string]diffChangeJSON{}
+	for _, change := range report.Changes {
+		got[change.Key] = change
+	}
+	if c := got["Serve"]; c.Change != "changed" || c.Old == nil || c.New == nil || !strings.Contains(c.New.Declaration, "ctx context.Context") {
+		t.Fatalf("Serve = %#v, want a changed signature", c)
+	}
+	if c := got["Helper"]; c.Change != "moved" || c.Old.File != "util.go" || c.New.File != "helpers/util.go" {
+		t.Fatalf("Helper = %#v, want moved from util.go", c)
+	}
+	if c := got["Legacy"]; c.Change != "removed" || c.New != nil {
+		t.Fatalf("Legacy = %#v, want removed", c)
+	}
+	if c := got["Dial"]; c.Change != "added" || c.Old != nil || c.Kind != "code" {
+		t.Fatalf("Dial = %#v, want added", c)
+	}
+	if report.From != "v1" || report.To != "HEAD" || len(report.ToRev) != 40 {
+		t.Fatalf("report revisions = %q %q %q", report.From, report.To, report.ToRev)
+	}
+}
+
+func TestRunDiffCommandPrintsText(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+	repo := newDiffRepo(t)
+
+	var stdout bytes.Buffer
+	if err := runDiffCommand(context.Background(), []string{"--root", repo, "v1", "HEAD"}, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("runDiffCommand: %v", err)
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		"v1..HEAD: 1 added, 1 removed, 1 changed, 1 moved\n",
+		"moved    Helper  helpers/util.go:3 (was util.go:3)\n",
+		"         - func Serve(addr string) error { return nil }\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	if err := runDiffCommand(context.Background(), []string{"--root", repo, "v1"}, &stdout, &bytes.Buffer{}); err == nil {
+		t.Fatalf("expected an error for a single revision")
+	}
+}
+
+func TestDiffPreviewShowsBothRevisions(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+	repo := newDiffRepo(t)
+	d, err := loadSymbolDiff(context.Background(), repo, "v1", "HEAD", diffOptions{})
+	if err != nil {
+		t.Fatalf("loadSymbolDiff: %v", err)
+	}
+
+	m := newModel(config{Root: repo, Preview: true}, nil, nil, nil)
+	m.roots = []workspaceRoot{{Path: repo, Rev: d.ToRev}}
+	m.width, m.height = 120, 30
+	m.showSymbolDiff(d)
+	if len(m.filtered) != len(d.Changes) || !m.scanDone {
+		t.Fatalf("listed %d of %d changes", len(m.filtered), len(d.Changes))
+	}
+
+	for i := range m.filtered {
+		cand, _ := m.candidateForFiltered(i)
+		if cand.Key != "Helper" {
+			continue
+		}
+		if cand.Change != candidate.ChangeMoved || cand.Root != "" {
+			t.Fatalf("Helper is labelled %q in root %q, want moved", cand.Change, cand.Root)
+		}
+		if path := m.candidatePath(cand); path != "helpers/util.go" {
+			t.Fatalf("candidatePath = %q, want helpers/util.go", path)
+		}
+		m.cursor = i
+	}
+	m.updatePreview()
+	if m.diffPreview.File != "util.go" || m.preview.File != "helpers/util.go" {
+		t.Fatalf("previews = %q and %q, want util.go before and helpers/util.go after", m.diffPreview.File, m.preview.File)
+	}
+	if got := m.preview.Lines[m.preview.SelectedLine-m.preview.StartLine]; got != "func Helper() {}" {
+		t.Fatalf("after preview selects %q", got)
+	}
+}
@@ -4,29 +4,54 @@ import (
 	"bufio"
 	"encoding/gob"
 	"errors"
+	"fmt"
+	"hash/fnv"
 	"os"
 	"path/filepath"
 	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"snav/internal/candidate"
 )
 
-const indexCacheVersion = 2
+const indexCacheVersion = 25
 
-var indexCachePathOverride string
+// Index files unused for indexCacheMaxAge are deleted, and each cache
+// directory keeps the indexCacheMaxFiles most recently used ones. A root
+// keeps at most indexCacheMaxConfigs indexes, one per set of scan options,
+// enough for every --no-ignore and --hidden combination.
+const (
+	indexCacheMaxAge     = 30 * 24 * time.Hour
+	indexCacheMaxFiles   = 64
+	indexCacheMaxConfigs = 4
+)
+
+var indexCacheDirOverride string
 
 type diskIndexCache struct {
-	Version      int
-	Root         string
-	Pattern      string
-	NoIgnore     bool
-	ExcludeTests bool
-	Excludes     []string
-	Candidates   []candidate.Candidate
+	Version int
+	Root    string
+	// Dependency is the name@version of a dependency root.
+	Dependency string
+	// Rev is the commit indexed instead of the working tree.
+	Rev      string
+	Pattern  string
+	NoIgnore bool
+	Hidden   bool
+	// ExcludeGenerated is part of the cache key since it changes which
+	// files are indexed.
+	ExcludeGenerated  bool
+	Members           bool
+	MaxFileSize       string
+	MaxFileCandidates int
+	Excludes          []string
+	Candidates        []candidate.Candidate
 }
 
 func LoadIndexCache(cfg candidate.ProducerConfig) (candidates []candidate.Candidate, ok bool, err error) {
-	path, err := indexCachePath()
+	path, err := indexCachePath(cfg)
 	if err != nil {
 		return nil, false, err
 	}
@@ -49,15 +74,21 @@ func LoadIndexCache(cfg candidate.ProducerConfig) (candidates []candidate.Candid
 		return nil, false, err
 	}
 
+	if disk.Version != indexCacheVersion {
+		_ = os.Remove(path)
+		return nil, false, nil
+	}
 	if !indexCacheMatches(disk, cfg) {
 		return nil, false, nil
 	}
 
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
 	return disk.Candidates, true, nil
 }
 
 func SaveIndexCache(cfg candidate.ProducerConfig, candidates []candidate.Candidate) error {
-	path, err := indexCachePath()
+	path, err := indexCachePath(cfg)
 	if err != nil {
 		return err
 	}
@@ -84,13 +115,19 @@ func SaveIndexCache(cfg candidate.ProducerConfig, candidates []candidate.Candida
 
 	writer := bufio.NewWriterSize(f, 1<<20)
 	disk := diskIndexCache{
-		Version:      indexCacheVersion,
-		Root:         filepath.Clean(cfg.Root),
-		Pattern:      cfg.Pattern,
-		NoIgnore:     cfg.NoIgnore,
-		ExcludeTests: cfg.ExcludeTests,
-		Excludes:     append([]string(nil), cfg.Excludes...),
-		Candidates:   candidates,
+		Version:           indexCacheVersion,
+		Root:              filepath.Clean(cfg.Root),
+		Dependency:        cfg.Dependency,
+		Rev:               cfg.Rev,
+		Pattern:           cfg.Pattern,
+		NoIgnore:          cfg.NoIgnore,
+		Hidden:            cfg.Hidden,
+		ExcludeGenerated:  cfg.ExcludeGenerated,
+		Members:           cfg.Members,
+		MaxFileSize:       cfg.MaxFileSize,
+		MaxFileCandidates: cfg.MaxFileCandidates,
+		Excludes:          append([]string(nil), cfg.Excludes...),
+		Candidates:        candidates,
 	}
 	if err := gob.NewEncoder(writer).Encode(&disk); err != nil {
 		return failWithClose(err)
@@ -107,30 +144,103 @@ func SaveIndexCache(cfg candidate.ProducerConfig, candidates []candidate.Candida
 	}
 	cleanup = false
 
+	pruneIndexCache(filepath.Dir(path), time.Now())
 	return nil
 }
 
+// pruneIndexCache deletes the index files of dir that went unused for
+// indexCacheMaxAge, then the least recently used beyond indexCacheMaxConfigs
+// for one root or indexCacheMaxFiles in all. Loading an index marks it used.
+func pruneIndexCache(dir string, now time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type indexFile struct {
+		path string
+		used time.Time
+	}
+	var kept []indexFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "index-") || filepath.Ext(name) != ".gob" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if now.Sub(info.ModTime()) > indexCacheMaxAge {
+			_ = os.Remove(path)
+			continue
+		}
+		kept = append(kept, indexFile{path: path, used: info.ModTime()})
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].used.After(kept[j].used) })
+	perRoot := make(map[string]int)
+	n := 0
+	for _, f := range kept {
+		root := indexCacheRootID(filepath.Base(f.path))
+		if perRoot[root] >= indexCacheMaxConfigs || n >= indexCacheMaxFiles {
+			_ = os.Remove(f.path)
+			continue
+		}
+		perRoot[root]++
+		n++
+	}
+}
+
+// indexCacheRootID returns the root part of an index file name,
+// index-<root>-<options>.gob.
+func indexCacheRootID(name string) string {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "index-"), ".gob")
+	root, _, _ := strings.Cut(name, "-")
+	return root
+}
+
 func indexCacheMatches(disk diskIndexCache, cfg candidate.ProducerConfig) bool {
 	if disk.Version != indexCacheVersion {
 		return false
 	}
-	if filepath.Clean(disk.Root) != filepath.Clean(cfg.Root) {
+	if filepath.Clean(disk.Root) != filepath.Clean(cfg.Root) || disk.Dependency != cfg.Dependency || disk.Rev != cfg.Rev {
 		return false
 	}
-	if disk.Pattern != cfg.Pattern || disk.NoIgnore != cfg.NoIgnore || disk.ExcludeTests != cfg.ExcludeTests {
+	if disk.Pattern != cfg.Pattern || disk.NoIgnore != cfg.NoIgnore || disk.Hidden != cfg.Hidden || disk.Members != cfg.Members {
+		return false
+	}
+	if disk.ExcludeGenerated != cfg.ExcludeGenerated || disk.MaxFileSize != cfg.MaxFileSize || disk.MaxFileCandidates != cfg.MaxFileCandidates {
 		return false
 	}
 	return slices.Equal(disk.Excludes, cfg.Excludes)
 }
 
-func indexCachePath() (string, error) {
-	if indexCachePathOverride != "" {
-		return indexCachePathOverride, nil
+// indexCachePath returns the cache file of a root scanned with cfg. Each
+// root keeps one index per set of scan options, so toggling --no-ignore or
+// --hidden back reuses the earlier index, and the roots of a workspace
+// refresh independently. Dependency sources rarely change and commits never
+// do, so their indexes are kept apart, one per version or commit, and reused
+// without a rescan. pruneIndexCache bounds how many accumulate.
+func indexCachePath(cfg candidate.ProducerConfig) (string, error) {
+	dir := indexCacheDirOverride
+	if dir == "" {
+		cacheRoot, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(cacheRoot, "snav")
 	}
-
-	root, err := os.UserCacheDir()
-	if err != nil {
-		return "", err
+	root := fnv.New64a()
+	_, _ = root.Write([]byte(filepath.Clean(cfg.Root)))
+	if cfg.Dependency != "" {
+		dir = filepath.Join(dir, "deps")
+		_, _ = root.Write([]byte("@" + cfg.Dependency))
+	}
+	if cfg.Rev != "" {
+		dir = filepath.Join(dir, "revs")
+		_, _ = root.Write([]byte("@" + cfg.Rev))
 	}
-	return filepath.Join(root, "snav", "last_index.gob"), nil
+	options := fnv.New64a()
+	_, _ = options.Write([]byte(scanIndexKey(cfg)))
+	return filepath.Join(dir, fmt.Sprintf("index-%016x-%016x.gob", root.Sum64(), options.Sum64())), nil
 }
@@ -1,32 +1,35 @@
 package main
 
 import (
+	"encoding/gob"
+	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"snav/internal/candidate"
 )
 
-func withIndexCachePath(t *testing.T, path string) {
+func withIndexCacheDir(t *testing.T, dir string) {
 	t.Helper()
-	old := indexCachePathOverride
-	indexCachePathOverride = path
+	old := indexCacheDirOverride
+	indexCacheDirOverride = dir
 	t.Cleanup(func() {
-		indexCachePathOverride = old
+		indexCacheDirOverride = old
 	})
 }
 
 func TestIndexCacheRoundTrip(t *testing.T) {
-	cachePath := filepath.Join(t.TempDir(), "last_index.gob")
-	withIndexCachePath(t, cachePath)
+	withIndexCacheDir(t, t.TempDir())
 
 	cfg := candidate.ProducerConfig{
-		Root:         "/repo/project",
-		Pattern:      candidate.DefaultRGPattern,
-		NoIgnore:     false,
-		ExcludeTests: true,
-		Excludes:     []string{"vendor/**"},
+		Root:     "/repo/project",
+		Pattern:  candidate.DefaultRGPattern,
+		NoIgnore: false,
+		Members:  true,
+		Excludes: []string{"vendor/**"},
 	}
 	candidates := []candidate.Candidate{
 		{ID: 1, File: "a.go", Line: 10, Col: 2, Text: "func A() {}", Key: "A", LangID: candidate.LangGo},
@@ -49,9 +52,8 @@ func TestIndexCacheRoundTrip(t *testing.T) {
 	}
 }
 
-func TestIndexCacheOnlyKeepsLastIndex(t *testing.T) {
-	cachePath := filepath.Join(t.TempDir(), "last_index.gob")
-	withIndexCachePath(t, cachePath)
+func TestIndexCacheKeepsOneIndexPerRootAndOptions(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
 
 	cfgA := candidate.ProducerConfig{Root: "/repo/a", Pattern: candidate.DefaultRGPattern}
 	cfgB := candidate.ProducerConfig{Root: "/repo/b", Pattern: candidate.DefaultRGPattern}
@@ -63,20 +65,246 @@ func TestIndexCacheOnlyKeepsLastIndex(t *testing.T) {
 		t.Fatalf("SaveIndexCache B failed: %v", err)
 	}
 
-	if _, ok, err := LoadIndexCache(cfgA); err != nil {
-		t.Fatalf("LoadIndexCache A failed: %v", err)
+	for _, tc := range []struct {
+		cfg  candidate.ProducerConfig
+		file string
+	}{{cfgA, "a.go"}, {cfgB, "b.go"}} {
+		got, ok, err := LoadIndexCache(tc.cfg)
+		if err != nil {
+			t.Fatalf("LoadIndexCache %s failed: %v", tc.cfg.Root, err)
+		}
+		if !ok || len(got) != 1 || got[0].File != tc.file {
+			t.Fatalf("LoadIndexCache %s = %#v, %v; want %s", tc.cfg.Root, got, ok, tc.file)
+		}
+	}
+
+	hidden := cfgA
+	hidden.Hidden = true
+	if err := SaveIndexCache(hidden, []candidate.Candidate{{ID: 1, File: ".config.go", Key: "Hidden"}}); err != nil {
+		t.Fatalf("SaveIndexCache hidden failed: %v", err)
+	}
+	if got, ok, err := LoadIndexCache(cfgA); err != nil || !ok || got[0].Key != "A" {
+		t.Fatalf("LoadIndexCache A = %#v, %v, %v; want it kept next to the hidden index", got, ok, err)
+	}
+	if got, ok, err := LoadIndexCache(hidden); err != nil || !ok || got[0].Key != "Hidden" {
+		t.Fatalf("LoadIndexCache hidden = %#v, %v, %v", got, ok, err)
+	}
+}
+
+func TestScanIndexKeyIgnoresRootName(t *testing.T) {
+	cfg := candidate.ProducerConfig{Root: "/repo/api", RootName: "api", Excludes: []string{"vendor/**"}}
+	renamed := cfg
+	renamed.RootName = "backend"
+	if scanIndexKey(cfg) != scanIndexKey(renamed) {
+		t.Fatalf("scanIndexKey depends on the root label")
+	}
+	for _, change := range []func(*candidate.ProducerConfig){
+		func(c *candidate.ProducerConfig) { c.NoIgnore = true },
+		func(c *candidate.ProducerConfig) { c.Hidden = true },
+		func(c *candidate.ProducerConfig) { c.Excludes = []string{"vendor/**", "dist/**"} },
+		func(c *candidate.ProducerConfig) { c.MaxFileSize = "2M" },
+	} {
+		other := cfg
+		change(&other)
+		if scanIndexKey(cfg) == scanIndexKey(other) {
+			t.Fatalf("scanIndexKey(%+v) matches %+v", other, cfg)
+		}
+	}
+}
+
+func TestIndexCacheKeyIncludesExcludeGenerated(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+
+	cfg := candidate.ProducerConfig{Root: "/repo/project", Pattern: candidate.DefaultRGPattern}
+	if err := SaveIndexCache(cfg, []candidate.Candidate{{ID: 1, File: "a.pb.go", Key: "A", Generated: true}}); err != nil {
+		t.Fatalf("SaveIndexCache failed: %v", err)
+	}
+
+	excluding := cfg
+	excluding.ExcludeGenerated = true
+	if _, ok, err := LoadIndexCache(excluding); err != nil {
+		t.Fatalf("LoadIndexCache failed: %v", err)
 	} else if ok {
-		t.Fatalf("expected cache miss for older root")
+		t.Fatalf("expected cache miss when --exclude-generated changes")
 	}
+}
+
+func TestIndexCacheKeyIncludesHidden(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+
+	cfg := candidate.ProducerConfig{Root: "/repo/project", Pattern: candidate.DefaultRGPattern}
+	if err := SaveIndexCache(cfg, []candidate.Candidate{{ID: 1, File: "a.go", Key: "A"}}); err != nil {
+		t.Fatalf("SaveIndexCache failed: %v", err)
+	}
+
+	hidden := cfg
+	hidden.Hidden = true
+	if _, ok, err := LoadIndexCache(hidden); err != nil {
+		t.Fatalf("LoadIndexCache failed: %v", err)
+	} else if ok {
+		t.Fatalf("expected cache miss when --hidden changes")
+	}
+}
+
+func TestIndexCacheKeepsDependencyIndexesApart(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+
+	project := candidate.ProducerConfig{Root: "/repo/node_modules/react", Pattern: candidate.DefaultRGPattern}
+	dep := project
+	dep.Dependency = "react@18.2.0"
+	if err := SaveIndexCache(project, []candidate.Candidate{{ID: 1, File: "a.js", Key: "A"}}); err != nil {
+		t.Fatalf("SaveIndexCache project failed: %v", err)
+	}
+	if err := SaveIndexCache(dep, []candidate.Candidate{{ID: 1, File: "index.js", Key: "useState", Dependency: true}}); err != nil {
+		t.Fatalf("SaveIndexCache dependency failed: %v", err)
+	}
+
+	if got, ok, err := LoadIndexCache(project); err != nil || !ok || got[0].Key != "A" {
+		t.Fatalf("LoadIndexCache project = %#v, %v, %v", got, ok, err)
+	}
+	upgraded := dep
+	upgraded.Dependency = "react@18.3.0"
+	if _, ok, err := LoadIndexCache(upgraded); err != nil {
+		t.Fatalf("LoadIndexCache upgraded failed: %v", err)
+	} else if ok {
+		t.Fatalf("expected cache miss when the dependency version changes")
+	}
+	if err := SaveIndexCache(upgraded, []candidate.Candidate{{ID: 1, File: "index.js", Key: "use", Dependency: true}}); err != nil {
+		t.Fatalf("SaveIndexCache upgraded failed: %v", err)
+	}
+	if got, ok, err := LoadIndexCache(dep); err != nil || !ok || got[0].Key != "useState" {
+		t.Fatalf("LoadIndexCache older version = %#v, %v, %v; want it kept", got, ok, err)
+	}
+}
 
-	gotB, ok, err := LoadIndexCache(cfgB)
+func TestIndexCacheKeepsRevisionIndexesApart(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+
+	working := candidate.ProducerConfig{Root: "/repo", Pattern: candidate.DefaultRGPattern}
+	release := working
+	release.Rev = "1111111111111111111111111111111111111111"
+	if err := SaveIndexCache(working, []candidate.Candidate{{ID: 1, File: "a.go", Key: "Listen"}}); err != nil {
+		t.Fatalf("SaveIndexCache working tree failed: %v", err)
+	}
+	if err := SaveIndexCache(release, []candidate.Candidate{{ID: 1, File: "a.go", Key: "Serve"}}); err != nil {
+		t.Fatalf("SaveIndexCache revision failed: %v", err)
+	}
+
+	if got, ok, err := LoadIndexCache(working); err != nil || !ok || got[0].Key != "Listen" {
+		t.Fatalf("LoadIndexCache working tree = %#v, %v, %v", got, ok, err)
+	}
+	if got, ok, err := LoadIndexCache(release); err != nil || !ok || got[0].Key != "Serve" {
+		t.Fatalf("LoadIndexCache revision = %#v, %v, %v", got, ok, err)
+	}
+	other := release
+	other.Rev = "2222222222222222222222222222222222222222"
+	if _, ok, err := LoadIndexCache(other); err != nil || ok {
+		t.Fatalf("LoadIndexCache other revision = %v, %v, want a miss", ok, err)
+	}
+}
+
+func TestPruneIndexCacheDropsStaleAndLeastRecentlyUsedIndexes(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	write := func(name string, used time.Time) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if err := os.Chtimes(path, used, used); err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+		return path
+	}
+	stale := write("index-stale.gob", now.Add(-indexCacheMaxAge-time.Hour))
+	for i := 0; i < indexCacheMaxFiles; i++ {
+		write(fmt.Sprintf("index-%03d.gob", i), now.Add(-time.Duration(i)*time.Minute))
+	}
+	oldest := write("index-oldest.gob", now.Add(-24*time.Hour))
+	other := write("notes.txt", now.Add(-indexCacheMaxAge-time.Hour))
+
+	pruneIndexCache(dir, now)
+
+	for _, path := range []string{stale, oldest} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s was kept", filepath.Base(path))
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("non-index file was removed: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		t.Fatalf("LoadIndexCache B failed: %v", err)
+		t.Fatalf("ReadDir: %v", err)
 	}
-	if !ok {
-		t.Fatalf("expected cache hit for latest root")
+	if len(entries) != indexCacheMaxFiles+1 {
+		t.Fatalf("kept %d files, want %d", len(entries), indexCacheMaxFiles+1)
+	}
+}
+
+func TestLoadIndexCacheDropsOtherVersions(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+	cfg := candidate.ProducerConfig{Root: "/repo", Pattern: candidate.DefaultRGPattern}
+	path, err := indexCachePath(cfg)
+	if err != nil {
+		t.Fatalf("indexCachePath: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := gob.NewEncoder(f).Encode(&diskIndexCache{Version: indexCacheVersion - 1, Root: "/repo"}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, ok, err := LoadIndexCache(cfg); err != nil || ok {
+		t.Fatalf("LoadIndexCache = %v, %v; want a miss", ok, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("index of another version was kept")
+	}
+}
+
+func TestPruneIndexCacheBoundsIndexesPerRoot(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	var paths []string
+	for i := 0; i < indexCacheMaxConfigs+1; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("index-%016x-%016x.gob", 1, i))
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		used := now.Add(-time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, used, used); err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+		paths = append(paths, path)
+	}
+	other := filepath.Join(dir, fmt.Sprintf("index-%016x-%016x.gob", 2, 0))
+	if err := os.WriteFile(other, []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	old := now.Add(-time.Hour)
+	if err := os.Chtimes(other, old, old); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	pruneIndexCache(dir, now)
+
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		if last := i == len(paths)-1; last != os.IsNotExist(err) {
+			t.Fatalf("index %d kept = %v", i, err == nil)
+		}
 	}
-	if len(gotB) != 1 || gotB[0].File != "b.go" {
-		t.Fatalf("unexpected cache payload for latest root")
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("another root's index was removed: %v", err)
 	}
 }
@@ -0,0 +1,143 @@
+package candidate
+
+import (
+	"sort"
+	"strings"
+)
+
+// Change classifies how a symbol differs between two revisions.
+type Change string
+
+const (
+	ChangeAdded   Change = "added"
+	ChangeRemoved Change = "removed"
+	// ChangeChanged is a symbol whose declaration line or signature changed
+	// in place.
+	ChangeChanged Change = "changed"
+	// ChangeMoved is a symbol that is now declared in another file.
+	ChangeMoved Change = "moved"
+)
+
+// SymbolChange is one difference between two revisions. Old is nil for added
+// symbols and New is nil for removed ones.
+type SymbolChange struct {
+	Change Change
+	Old    *Candidate
+	New    *Candidate
+}
+
+// Symbol returns the side of the change that exists in the newer revision,
+// or the removed symbol.
+func (c SymbolChange) Symbol() Candidate {
+	if c.New != nil {
+		return *c.New
+	}
+	return *c.Old
+}
+
+// symbolIdentity is what a symbol is matched by across revisions, besides
+// its file.
+type symbolIdentity struct {
+	Kind      Kind
+	Container string
+	Key       string
+}
+
+type fileSymbol struct {
+	File string
+	symbolIdentity
+}
+
+// DiffCandidates compares the candidates of two revisions. Symbols are first
+// matched by file, kind, container and key, pairing repeated ones in line
+// order; a match whose declaration differs is changed. A symbol left on each
+// side with the same kind, container and key moved to another file, unless
+// the identity is ambiguous. The rest were added or removed.
+func DiffCandidates(old []Candidate, new []Candidate) []SymbolChange {
+	oldByFile := groupSymbols(old, func(c Candidate) fileSymbol { return fileSymbol{File: c.File, symbolIdentity: identityOf(c)} })
+	newByFile := groupSymbols(new, func(c Candidate) fileSymbol { return fileSymbol{File: c.File, symbolIdentity: identityOf(c)} })
+
+	var changes []SymbolChange
+	var oldLeft, newLeft []Candidate
+	for key, olds := range oldByFile {
+		news := newByFile[key]
+		n := min(len(olds), len(news))
+		for i := 0; i < n; i++ {
+			if declarationOf(olds[i]) != declarationOf(news[i]) {
+				changes = append(changes, SymbolChange{Change: ChangeChanged, Old: &olds[i], New: &news[i]})
+			}
+		}
+		oldLeft = append(oldLeft, olds[n:]...)
+	}
+	for key, news := range newByFile {
+		newLeft = append(newLeft, news[min(len(oldByFile[key]), len(news)):]...)
+	}
+
+	oldByID := groupSymbols(oldLeft, identityOf)
+	newByID := groupSymbols(newLeft, identityOf)
+	for key, olds := range oldByID {
+		if news := newByID[key]; len(olds) == 1 && len(news) == 1 {
+			changes = append(changes, SymbolChange{Change: ChangeMoved, Old: &olds[0], New: &news[0]})
+			continue
+		}
+		for i := range olds {
+			changes = append(changes, SymbolChange{Change: ChangeRemoved, Old: &olds[i]})
+		}
+	}
+	for key, news := range newByID {
+		if len(news) == 1 && len(oldByID[key]) == 1 {
+			continue
+		}
+		for i := range news {
+			changes = append(changes, SymbolChange{Change: ChangeAdded, New: &news[i]})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i].Symbol(), changes[j].Symbol()
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return changes[i].Change < changes[j].Change
+	})
+	return changes
+}
+
+func groupSymbols[K comparable](cands []Candidate, keyOf func(Candidate) K) map[K][]Candidate {
+	groups := make(map[K][]Candidate)
+	for _, cand := range cands {
+		key := keyOf(cand)
+		groups[key] = append(groups[key], cand)
+	}
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].File != group[j].File {
+				return group[i].File < group[j].File
+			}
+			if group[i].Cell != group[j].Cell {
+				return group[i].Cell < group[j].Cell
+			}
+			return group[i].Line < group[j].Line
+		})
+	}
+	return groups
+}
+
+func identityOf(c Candidate) symbolIdentity {
+	return symbolIdentity{Kind: c.Kind, Container: c.Container, Key: c.Key}
+}
+
+// declarationOf is the declaration a changed symbol is detected by: its
+// signature, or its line without surrounding whitespace.
+func declarationOf(c Candidate) string {
+	if c.Signature != "" {
+		return c.Signature
+	}
+	return strings.TrimSpace(c.Text)
+}
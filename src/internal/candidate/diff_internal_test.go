@@ -0,0 +1,47 @@
+package candidate
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDiffCandidates(t *testing.T) {
+	old := []Candidate{
+		{File: "server.go", Line: 3, Key: "Serve", Text: "func Serve(addr string) error {"},
+		{File: "server.go", Line: 9, Key: "Listen", Text: "func Listen() {"},
+		{File: "util.go", Line: 4, Key: "Helper", Text: "func Helper() {"},
+		{File: "legacy.go", Line: 2, Key: "Old", Text: "func Old() {"},
+		{File: "a.go", Line: 1, Key: "err", Text: "var err error"},
+		{File: "README.md", Line: 1, Key: "Usage", Kind: KindDoc, Text: "# Usage"},
+	}
+	new := []Candidate{
+		{File: "server.go", Line: 5, Key: "Serve", Text: "func Serve(ctx context.Context, addr string) error {"},
+		{File: "server.go", Line: 12, Key: "Listen", Text: "  func Listen() {"},
+		{File: "helpers/util.go", Line: 7, Key: "Helper", Text: "func Helper() {"},
+		{File: "client.go", Line: 3, Key: "Dial", Text: "func Dial() {"},
+		{File: "b.go", Line: 1, Key: "err", Text: "var err error"},
+		{File: "c.go", Line: 1, Key: "err", Text: "var err error"},
+		{File: "README.md", Line: 1, Key: "Usage", Text: "func Usage() {"},
+	}
+
+	var got []string
+	for _, change := range DiffCandidates(old, new) {
+		sym := change.Symbol()
+		got = append(got, fmt.Sprintf("%s %s %s:%d", change.Change, sym.Key, sym.File, sym.Line))
+	}
+	want := []string{
+		"added Usage README.md:1",
+		"removed Usage README.md:1",
+		"removed err a.go:1",
+		"added err b.go:1",
+		"added err c.go:1",
+		"added Dial client.go:3",
+		"moved Helper helpers/util.go:7",
+		"removed Old legacy.go:2",
+		"changed Serve server.go:5",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DiffCandidates =\n%q\nwant\n%q", got, want)
+	}
+}
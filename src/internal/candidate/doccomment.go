@@ -0,0 +1,235 @@
+package candidate
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDocLen bounds the stored summary so a run-on comment cannot bloat the
+// index.
+const maxDocLen = 200
+
+var (
+	pythonDocOwner  = regexp.MustCompile(`^\s*(?:async\s+)?(?:def|class)\s`)
+	docstringOpener = regexp.MustCompile(`^[rRuUbB]{0,2}("""|''')`)
+	csharpInlineRef = regexp.MustCompile(`<(?:see|seealso|paramref|typeparamref)\s+(?:cref|name|langword)="([^"]*)"\s*/>`)
+	xmlTag          = regexp.MustCompile(`</?[A-Za-z][^>]*>`)
+)
+
+// docComment returns the first sentence of the doc comment attached to the
+// 1-based line: the comment block directly above it, or for Python the
+// docstring of a def or class.
+func docComment(lines []string, line int, id LangID) string {
+	if line < 1 || line > len(lines) || !hasDocComments(id) {
+		return ""
+	}
+	var paragraph []string
+	switch id {
+	case LangPython:
+		paragraph = pythonDocstring(lines, line)
+	case LangGo:
+		paragraph = commentAbove(lines, line, true)
+	default:
+		paragraph = commentAbove(lines, line, false)
+	}
+	return firstSentence(strings.Join(paragraph, " "))
+}
+
+func hasDocComments(id LangID) bool {
+	switch id {
+	case LangGo, LangPython, LangRust, LangCSharp, LangJava, LangKotlin, LangScala,
+		LangPHP, LangSwift, LangDart, LangC, LangCPP, LangObjC, LangZig,
+		LangJavaScript, LangTypeScript, LangTSX:
+		return true
+	}
+	return false
+}
+
+// commentAbove collects the first paragraph of the `///` or `/** */` comment
+// ending directly above line, skipping attributes and annotations in between.
+// Plain `//` comments count only for Go.
+func commentAbove(lines []string, line int, plainLine bool) []string {
+	end := line - 1
+	for end >= 1 && isAttributeLine(strings.TrimSpace(lines[end-1])) {
+		end--
+	}
+	if end < 1 {
+		return nil
+	}
+
+	last := strings.TrimSpace(lines[end-1])
+	if strings.HasSuffix(last, "*/") {
+		return blockCommentAbove(lines, end)
+	}
+
+	marker := "///"
+	if plainLine {
+		marker = "//"
+	}
+	start := end
+	for start >= 1 {
+		trimmed := strings.TrimSpace(lines[start-1])
+		if !strings.HasPrefix(trimmed, marker) || strings.HasPrefix(trimmed, "////") {
+			break
+		}
+		start--
+	}
+	var body []string
+	for n := start + 1; n <= end; n++ {
+		text := strings.TrimPrefix(strings.TrimSpace(lines[n-1]), marker)
+		if plainLine && isGoDirective(text) {
+			continue
+		}
+		body = append(body, text)
+	}
+	return docParagraph(body)
+}
+
+func blockCommentAbove(lines []string, end int) []string {
+	start := end
+	for start >= 1 && !strings.Contains(lines[start-1], "/*") {
+		start--
+	}
+	if start < 1 || !strings.HasPrefix(strings.TrimSpace(lines[start-1]), "/**") {
+		return nil
+	}
+	body := make([]string, 0, end-start+1)
+	for n := start; n <= end; n++ {
+		text := strings.TrimSpace(lines[n-1])
+		if n == start {
+			text = strings.TrimLeft(strings.TrimPrefix(text, "/**"), "*")
+		}
+		if n == end {
+			text = strings.TrimSuffix(text, "*/")
+		}
+		if n != start {
+			text = strings.TrimPrefix(text, "*")
+		}
+		body = append(body, text)
+	}
+	return docParagraph(body)
+}
+
+// pythonDocstring returns the first paragraph of the docstring that opens the
+// body of the def or class on line.
+func pythonDocstring(lines []string, line int) []string {
+	if !pythonDocOwner.MatchString(lines[line-1]) {
+		return nil
+	}
+	n := line
+	for n <= len(lines) && n < line+20 {
+		code, _, _ := strings.Cut(lines[n-1], "#")
+		n++
+		if strings.HasSuffix(strings.TrimSpace(code), ":") {
+			break
+		}
+	}
+	for n <= len(lines) && strings.TrimSpace(lines[n-1]) == "" {
+		n++
+	}
+	if n > len(lines) {
+		return nil
+	}
+
+	first := strings.TrimSpace(lines[n-1])
+	m := docstringOpener.FindStringSubmatchIndex(first)
+	if m == nil {
+		return nil
+	}
+	quote := first[m[2]:m[3]]
+	rest := first[m[1]:]
+	var body []string
+	for {
+		if before, _, ok := strings.Cut(rest, quote); ok {
+			body = append(body, before)
+			break
+		}
+		body = append(body, rest)
+		n++
+		if n > len(lines) {
+			break
+		}
+		rest = strings.TrimSpace(lines[n-1])
+	}
+	return docParagraph(body)
+}
+
+// docParagraph trims comment text to its first paragraph, ending at a blank
+// line, a JSDoc tag, a Markdown heading or fence, or the end of a C# summary.
+func docParagraph(body []string) []string {
+	var out []string
+	for _, text := range body {
+		text = strings.TrimSpace(text)
+		if strings.HasPrefix(text, "<summary>") {
+			text = strings.TrimSpace(strings.TrimPrefix(text, "<summary>"))
+			if text == "" {
+				continue
+			}
+		}
+		summaryEnd := false
+		if before, _, ok := strings.Cut(text, "</summary>"); ok {
+			text, summaryEnd = strings.TrimSpace(before), true
+		}
+		if text == "" || text[0] == '@' || text[0] == '#' || strings.HasPrefix(text, "```") ||
+			(text[0] == '<' && !strings.HasPrefix(text, "<see")) {
+			if len(out) > 0 || summaryEnd {
+				break
+			}
+			continue
+		}
+		out = append(out, text)
+		if summaryEnd {
+			break
+		}
+	}
+	return out
+}
+
+// firstSentence returns text up to the first period followed by a space,
+// ignoring periods after a single capital letter as in initials.
+func firstSentence(text string) string {
+	text = csharpInlineRef.ReplaceAllString(text, "$1")
+	text = xmlTag.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(text), " ")
+	for i := 0; i+1 < len(text); i++ {
+		if text[i] != '.' || text[i+1] != ' ' {
+			continue
+		}
+		if i >= 1 && isUpperByte(text[i-1]) && (i == 1 || text[i-2] == ' ') {
+			continue
+		}
+		text = text[:i+1]
+		break
+	}
+	if len(text) > maxDocLen {
+		cut := maxDocLen
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = strings.TrimSpace(text[:cut]) + "…"
+	}
+	return text
+}
+
+func isAttributeLine(trimmed string) bool {
+	switch {
+	case trimmed == "":
+		return false
+	case strings.HasPrefix(trimmed, "#["), strings.HasPrefix(trimmed, "@"):
+		return true
+	case trimmed[0] == '[':
+		return strings.HasSuffix(trimmed, "]")
+	}
+	return false
+}
+
+func isGoDirective(text string) bool {
+	return strings.HasPrefix(text, "go:") || strings.HasPrefix(text, "line ") ||
+		strings.HasPrefix(text, "export ") || strings.HasPrefix(text, "nolint") ||
+		strings.HasPrefix(text, "+build")
+}
+
+func isUpperByte(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,117 @@
+package candidate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocComment(t *testing.T) {
+	tests := []struct {
+		name string
+		lang LangID
+		src  string
+		line int
+		want string
+	}{
+		{
+			name: "go",
+			lang: LangGo,
+			src:  "// Parse reads a config file. It returns an error when the file is missing.\n//\n// More detail.\n//go:noinline\nfunc Parse(path string) error {",
+			line: 5,
+			want: "Parse reads a config file.",
+		},
+		{
+			name: "go detached comment",
+			lang: LangGo,
+			src:  "// Package config loads settings.\n\nfunc Parse() {}",
+			line: 3,
+		},
+		{
+			name: "go initials",
+			lang: LangGo,
+			src:  "// Sign signs a payload per RFC 7515 by J. Smith. Unused.\nfunc Sign() {}",
+			line: 2,
+			want: "Sign signs a payload per RFC 7515 by J. Smith.",
+		},
+		{
+			name: "jsdoc",
+			lang: LangTypeScript,
+			src:  "/**\n * Parses a query string into terms\n * separated by spaces.\n * @param q the query\n */\n@memoize\nexport function parse(q: string) {",
+			line: 7,
+			want: "Parses a query string into terms separated by spaces.",
+		},
+		{
+			name: "plain block comment",
+			lang: LangJavaScript,
+			src:  "/* not a doc comment */\nfunction parse() {}",
+			line: 2,
+		},
+		{
+			name: "rust",
+			lang: LangRust,
+			src:  "// not a doc\n/// Parses a manifest.\n///\n/// # Errors\n#[inline]\npub fn parse() {}",
+			line: 6,
+			want: "Parses a manifest.",
+		},
+		{
+			name: "rust plain comment",
+			lang: LangRust,
+			src:  "// Parses a manifest.\npub fn parse() {}",
+			line: 2,
+		},
+		{
+			name: "csharp",
+			lang: LangCSharp,
+			src:  "/// <summary>\n/// Parses a <see cref=\"Manifest\"/> from text.\n/// </summary>\n/// <param name=\"text\">The text.</param>\n[Obsolete]\npublic static Manifest Parse(string text)",
+			line: 6,
+			want: "Parses a Manifest from text.",
+		},
+		{
+			name: "csharp single line",
+			lang: LangCSharp,
+			src:  "/// <summary>Gets the name.</summary>\npublic string Name { get; }",
+			line: 2,
+			want: "Gets the name.",
+		},
+		{
+			name: "python",
+			lang: LangPython,
+			src:  "def parse(\n    text: str,\n) -> Config:\n    \"\"\"\n    Parse a config file. Raises on errors.\n    \"\"\"\n    return Config()",
+			line: 1,
+			want: "Parse a config file.",
+		},
+		{
+			name: "python single line",
+			lang: LangPython,
+			src:  "class Loader:\n    r'''Loads configs.'''",
+			line: 1,
+			want: "Loads configs.",
+		},
+		{
+			name: "python assignment",
+			lang: LangPython,
+			src:  "TIMEOUT = 5\n\"\"\"Seconds to wait.\"\"\"",
+			line: 1,
+		},
+		{
+			name: "unsupported language",
+			lang: LangRuby,
+			src:  "# Parses things.\ndef parse\nend",
+			line: 2,
+		},
+	}
+	for _, tt := range tests {
+		got := docComment(strings.Split(tt.src, "\n"), tt.line, tt.lang)
+		if got != tt.want {
+			t.Fatalf("%s: docComment() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDocCommentTruncatesLongSummaries(t *testing.T) {
+	src := "// " + strings.Repeat("é", maxDocLen) + "\nfunc Long() {}"
+	got := docComment(strings.Split(src, "\n"), 2, LangGo)
+	if !strings.HasSuffix(got, "…") || len(got) > maxDocLen+len("…") {
+		t.Fatalf("docComment() = %q, want a truncated summary", got)
+	}
+}
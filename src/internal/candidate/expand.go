@@ -0,0 +1,169 @@
+package candidate
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// expandCandidate rewrites a raw rg match using the surrounding source when a
+// single line is not enough to describe the declaration. It returns false when
+// the match should be emitted unchanged.
+func expandCandidate(cand Candidate, src *sourceFile, cfg ProducerConfig) ([]Candidate, bool) {
+	if kind := detectTaskFile(cand.File); kind != taskFileNone {
+		if out, ok := expandTaskEntry(cand, src, kind); ok {
+			return out, true
+		}
+	}
+
+	switch cand.LangID {
+	case LangGo:
+		return expandGoDeclaration(cand, src, cfg)
+	case LangJavaScript, LangTypeScript, LangTSX:
+		return expandJSDeclaration(cand, src)
+	case LangPython:
+		return expandPythonDeclaration(cand, src)
+	case LangMarkdown, LangRST, LangAsciiDoc:
+		return expandDocHeading(cand, src)
+	case LangProtobuf:
+		return expandProtoDeclaration(cand, src)
+	case LangGraphQL:
+		return expandGraphQLDeclaration(cand, src)
+	case LangSQL:
+		return expandSQLDeclaration(cand)
+	case LangYAML, LangJSON:
+		if cand.LangID == LangYAML {
+			if out, ok := expandManifestEntry(cand, src); ok {
+				return out, true
+			}
+		}
+		if out, ok := expandOpenAPIEntry(cand, src); ok {
+			return out, true
+		}
+		return expandConfigEntry(cand, src)
+	case LangTOML:
+		return expandConfigEntry(cand, src)
+	case LangHCL:
+		return expandHCLEntry(cand, src)
+	case LangJupyter:
+		return expandNotebook(cand, src)
+	case LangVue, LangSvelte, LangAstro:
+		return expandComponentEntry(cand, src)
+	case LangLua, LangElixir, LangScala, LangHaskell, LangOCaml, LangDart, LangObjC:
+		return expandLanguageDeclaration(cand)
+	default:
+		return nil, false
+	}
+}
+
+func trimIndent(line string) (string, int) {
+	trimmed := strings.TrimLeft(line, " \t")
+	return trimmed, len(line) - len(trimmed)
+}
+
+func sourceLineAt(lines []string, line int) (string, bool) {
+	if line < 1 || line > len(lines) {
+		return "", false
+	}
+	return lines[line-1], true
+}
+
+func isIdentStartByte(b byte) bool {
+	return (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || b == '_'
+}
+
+func isIdentByte(b byte) bool {
+	return isIdentStartByte(b) || (b >= '0' && b <= '9')
+}
+
+func leadingIdentifier(s string) (string, string) {
+	i := identifierEnd(s, 0, "")
+	return s[:i], s[i:]
+}
+
+// identifierEnd returns the end of the identifier starting at s[start],
+// accepting the ASCII bytes in extra as identifier characters too. It returns
+// start when no identifier starts there. ASCII is checked without decoding.
+func identifierEnd(s string, start int, extra string) int {
+	i := start
+	for i < len(s) {
+		if b := s[i]; b < utf8.RuneSelf {
+			if !isIdentByte(b) && strings.IndexByte(extra, b) < 0 || (i == start && b >= '0' && b <= '9') {
+				break
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !isIdentRune(r) || (i == start && !unicode.IsLetter(r)) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// bracketScanner tracks bracket depth across lines of C-like source, ignoring
+// brackets inside comments and string literals. Backtick literals may span
+// lines.
+type bracketScanner struct {
+	depth     int
+	inComment bool
+	inRaw     bool
+}
+
+func (s *bracketScanner) idle() bool {
+	return !s.inComment && !s.inRaw
+}
+
+func (s *bracketScanner) scan(line string) {
+	for i := 0; i < len(line); i++ {
+		if s.inComment {
+			if line[i] == '*' && i+1 < len(line) && line[i+1] == '/' {
+				s.inComment = false
+				i++
+			}
+			continue
+		}
+		if s.inRaw {
+			if line[i] == '\\' {
+				i++
+			} else if line[i] == '`' {
+				s.inRaw = false
+			}
+			continue
+		}
+
+		switch b := line[i]; b {
+		case '/':
+			if i+1 < len(line) && line[i+1] == '/' {
+				return
+			}
+			if i+1 < len(line) && line[i+1] == '*' {
+				s.inComment = true
+				i++
+			}
+		case '`':
+			s.inRaw = true
+		case '"', '\'':
+			i = skipQuoted(line, i, b)
+		case '(', '{', '[':
+			s.depth++
+		case ')', '}', ']':
+			s.depth--
+		}
+	}
+}
+
+func skipQuoted(line string, start int, quote byte) int {
+	for i := start + 1; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == quote {
+			return i
+		}
+	}
+	return len(line)
+}
@@ -0,0 +1,126 @@
+package candidate
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"snav/internal/lang"
+)
+
+// componentOutline describes a Vue, Svelte or Astro single-file component.
+type componentOutline struct {
+	regions []lang.Region
+	// line is the first top-level line of the component, where the component
+	// itself is emitted.
+	line int
+	// nameLine holds a `name:` component option, if any.
+	nameLine int
+	name     string
+}
+
+var (
+	componentOptionsOpener = regexp.MustCompile(`^(?:export\s+default\s+(?:defineComponent\s*\(\s*)?|defineComponent\s*\(\s*|defineOptions\s*\(\s*)\{`)
+	componentNameOption    = regexp.MustCompile(`^name\s*:\s*(?:"([^"]+)"|'([^']+)'|` + "`([^`$]+)`" + `)`)
+)
+
+// expandComponentEntry indexes the component a file declares and the
+// declarations of its script blocks, which take the language of their block.
+// Markup and style lines are dropped.
+func expandComponentEntry(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	outline := src.componentOutline(cand.LangID)
+	if outline == nil {
+		return nil, false
+	}
+
+	if cand.Line == outline.line {
+		return []Candidate{componentCandidate(cand, src, outline)}, true
+	}
+
+	id := lang.RegionLanguage(outline.regions, cand.Line, LangPlain)
+	if id != LangJavaScript && id != LangTypeScript && id != LangTSX {
+		return nil, true
+	}
+	cand.LangID = id
+	if out, ok := expandJSDeclaration(cand, src); ok {
+		return out, true
+	}
+	return []Candidate{cand}, true
+}
+
+func componentCandidate(cand Candidate, src *sourceFile, outline *componentOutline) Candidate {
+	base := filepath.Base(cand.File)
+	cand.Key = strings.TrimSuffix(base, filepath.Ext(base))
+	if outline.name != "" {
+		line, _ := sourceLineAt(src.Lines(), outline.nameLine)
+		text, indent := trimIndent(line)
+		cand.Key = outline.name
+		cand.Line = outline.nameLine
+		cand.Col = indent + 1
+		cand.Text = strings.TrimSpace(text)
+	}
+	cand.LangID = lang.RegionLanguage(outline.regions, cand.Line, lang.HTML)
+	cand.Kind = KindComponent
+	cand.SemanticScore = semanticTypeDeclScore
+	return cand
+}
+
+func (s *sourceFile) componentOutline(id LangID) *componentOutline {
+	if s.component == nil {
+		lines := s.Lines()
+		if lines == nil {
+			return nil
+		}
+		s.component = buildComponentOutline(id, lines)
+	}
+	return s.component
+}
+
+func buildComponentOutline(id LangID, lines []string) *componentOutline {
+	outline := &componentOutline{regions: lang.EmbeddedRegions(id, lines)}
+	for i, line := range lines {
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		if trimmed := strings.TrimSpace(line); trimmed == "---" || (len(trimmed) > 1 && trimmed[0] == '<' && isIdentStartByte(trimmed[1])) {
+			outline.line = i + 1
+			break
+		}
+	}
+
+	for _, r := range outline.regions {
+		if r.Lang == lang.CSS {
+			continue
+		}
+		if line, name, ok := componentNameIn(lines, r); ok {
+			outline.nameLine, outline.name = line, name
+			break
+		}
+	}
+	return outline
+}
+
+// componentNameIn finds a `name:` option directly inside the options object
+// of export default {...}, defineComponent({...}) or defineOptions({...}).
+func componentNameIn(lines []string, r lang.Region) (int, string, bool) {
+	var sc bracketScanner
+	optionsDepth := -1
+	for n := r.First; n <= r.Last && n <= len(lines); n++ {
+		trimmed := strings.TrimSpace(lines[n-1])
+		if optionsDepth < 0 && sc.idle() && componentOptionsOpener.MatchString(trimmed) {
+			sc.scan(trimmed)
+			optionsDepth = sc.depth
+			continue
+		}
+		if optionsDepth >= 0 && sc.depth == optionsDepth && sc.idle() {
+			if m := componentNameOption.FindStringSubmatch(trimmed); m != nil {
+				return n, m[1] + m[2] + m[3], true
+			}
+		}
+		sc.scan(trimmed)
+		if optionsDepth >= 0 && sc.depth < optionsDepth {
+			optionsDepth = -1
+		}
+	}
+	return 0, "", false
+}
@@ -0,0 +1,75 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+
+	"snav/internal/lang"
+)
+
+func TestExpandVueComponent(t *testing.T) {
+	src := testSourceFile("src/UserCard.vue", `<template>
+  <div class="card">{{ user.name }}</div>
+</template>
+
+<script lang="ts">
+export default defineComponent({
+  props: {
+    name: String,
+  },
+  name: 'UserProfileCard',
+})
+
+export function formatUser(u: User): string {
+  return u.name
+}
+</script>
+
+<style scoped>
+.card { color: red; }
+</style>`)
+
+	var got []kindKey
+	var langs []LangID
+	for _, line := range []int{1, 2, 13, 19} {
+		for _, cand := range expandTestMatch(t, src, line, ProducerConfig{}) {
+			got = append(got, kindKeys([]Candidate{cand})...)
+			langs = append(langs, cand.LangID)
+		}
+	}
+	want := []kindKey{
+		{Kind: KindComponent, Key: "UserProfileCard"},
+		{Kind: KindCode, Key: "formatUser"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v\nwant %#v", got, want)
+	}
+	if wantLangs := []LangID{LangTypeScript, LangTypeScript}; !reflect.DeepEqual(langs, wantLangs) {
+		t.Fatalf("langs = %v, want %v", langs, wantLangs)
+	}
+}
+
+func TestExpandComponentNamedByFile(t *testing.T) {
+	tests := []struct {
+		path string
+		src  string
+		line int
+		want string
+		lang LangID
+	}{
+		{path: "lib/Counter.svelte", src: "<script>\n  export let count = 0;\n</script>\n\n<button>{count}</button>", line: 1, want: "Counter", lang: lang.HTML},
+		{path: "pages/index.astro", src: "---\nconst title = 'Home';\n---\n<h1>{title}</h1>", line: 1, want: "index", lang: lang.HTML},
+	}
+	for _, tt := range tests {
+		out := expandTestMatch(t, testSourceFile(tt.path, tt.src), tt.line, ProducerConfig{})
+		if len(out) != 1 || out[0].Kind != KindComponent || out[0].Key != tt.want || out[0].LangID != tt.lang {
+			t.Fatalf("%s: got %#v, want component %q", tt.path, out, tt.want)
+		}
+	}
+
+	src := testSourceFile("pages/index.astro", "---\nconst title = 'Home';\n---\n<h1>{title}</h1>")
+	out := expandTestMatch(t, src, 2, ProducerConfig{})
+	if len(out) != 1 || out[0].Key != "title" || out[0].LangID != LangTypeScript {
+		t.Fatalf("frontmatter: got %#v", out)
+	}
+}
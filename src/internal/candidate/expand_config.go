@@ -0,0 +1,140 @@
+package candidate
+
+import "strings"
+
+// expandConfigEntry attaches the dotted path of the enclosing mappings or
+// tables to a YAML, JSON or TOML entry, so that nested keys with the same
+// name can be told apart.
+func expandConfigEntry(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	outline := src.configOutline(cand.LangID)
+	if cand.Line < 1 || cand.Line > len(outline) || outline[cand.Line-1] == "" {
+		return nil, false
+	}
+	cand.Container = outline[cand.Line-1]
+	return []Candidate{cand}, true
+}
+
+func (s *sourceFile) configOutline(id LangID) []string {
+	if s.config == nil {
+		lines := s.Lines()
+		switch id {
+		case LangJSON:
+			s.config = buildJSONOutline(lines)
+		case LangTOML:
+			s.config = buildTOMLOutline(lines)
+		default:
+			s.config = buildYAMLOutline(lines)
+		}
+	}
+	return s.config
+}
+
+// buildYAMLOutline derives key paths from indentation. List items nest under
+// the key that holds the list.
+func buildYAMLOutline(lines []string) []string {
+	type frame struct {
+		indent int
+		key    string
+	}
+
+	out := make([]string, len(lines))
+	var stack []frame
+	for i, raw := range lines {
+		trimmed, indent := trimIndent(raw)
+		if trimmed == "---" || strings.HasPrefix(trimmed, "--- ") {
+			stack = stack[:0]
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "-"); ok && (item == "" || item[0] == ' ' || item[0] == '\t') {
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			item, extra := trimIndent(item)
+			trimmed, indent = item, indent+1+extra
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		out[i] = joinConfigPath(stack, func(f frame) string { return f.key })
+
+		if key, _, ok := configEntry(trimmed); ok && key != "" {
+			stack = append(stack, frame{indent: indent, key: key})
+		}
+	}
+	return out
+}
+
+// buildJSONOutline tracks object nesting across lines. Array elements nest
+// under the key that holds the array.
+func buildJSONOutline(lines []string) []string {
+	out := make([]string, len(lines))
+	var stack []string
+	pending := ""
+	for i, raw := range lines {
+		out[i] = joinConfigPath(stack, func(key string) string { return key })
+
+		line := strings.TrimSpace(raw)
+		for j := 0; j < len(line); j++ {
+			switch c := line[j]; c {
+			case '"':
+				end := skipQuoted(line, j, '"')
+				if rest := strings.TrimLeft(line[min(end+1, len(line)):], " \t"); strings.HasPrefix(rest, ":") {
+					pending = line[j+1 : min(end, len(line))]
+				}
+				j = end
+			case '/':
+				if j+1 < len(line) && line[j+1] == '/' {
+					j = len(line)
+				}
+			case '{', '[':
+				stack = append(stack, pending)
+				pending = ""
+			case '}', ']':
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+				pending = ""
+			case ',':
+				pending = ""
+			}
+		}
+	}
+	return out
+}
+
+// buildTOMLOutline uses the current [table] or [[array]] header as the path of
+// the keys below it.
+func buildTOMLOutline(lines []string) []string {
+	out := make([]string, len(lines))
+	table := ""
+	for i, raw := range lines {
+		trimmed := strings.TrimSpace(raw)
+		if strings.HasPrefix(trimmed, "[") {
+			if key, ok := extractConfigKeyFast(trimmed); ok {
+				table = strings.TrimSpace(key)
+			}
+			continue
+		}
+		out[i] = table
+	}
+	return out
+}
+
+func joinConfigPath[T any](stack []T, key func(T) string) string {
+	var b strings.Builder
+	for _, f := range stack {
+		k := key(f)
+		if k == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(k)
+	}
+	return b.String()
+}
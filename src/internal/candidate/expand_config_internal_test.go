@@ -0,0 +1,71 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func configPaths(t *testing.T, src *sourceFile, lines ...int) []string {
+	t.Helper()
+
+	var out []string
+	for _, line := range lines {
+		out = append(out, candidateKeys(expandTestMatch(t, src, line, ProducerConfig{}))...)
+	}
+	return out
+}
+
+func TestExpandYAMLConfigKeyPaths(t *testing.T) {
+	src := testSourceFile("values.yaml", `server:
+  tls:
+    enabled: true
+  # comment
+  image: nginx
+containers:
+  - name: app
+    image: app:latest
+---
+metrics:
+  enabled: false`)
+
+	got := configPaths(t, src, 1, 3, 5, 7, 8, 11)
+	want := []string{"server", "server.tls.enabled", "server.image", "containers.name", "containers.image", "metrics.enabled"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("paths = %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandJSONConfigKeyPaths(t *testing.T) {
+	src := testSourceFile("config.json", `{
+  "server": {
+    "tls": { "enabled": true },
+    "ports": [
+      { "name": "http" }
+    ],
+    "image": "nginx"
+  }
+}`)
+
+	got := configPaths(t, src, 2, 3, 5, 7)
+	want := []string{"server", "server.tls", "server.ports.name", "server.image"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("paths = %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandTOMLConfigKeyPaths(t *testing.T) {
+	src := testSourceFile("pyproject.toml", `name = "demo"
+
+[tool.poetry.dependencies]
+requests = "^2.31"
+
+[[tool.mypy.overrides]]
+module = "tests.*"`)
+
+	for i, line := range []int{1, 4, 7} {
+		want := []string{"name", "tool.poetry.dependencies.requests", "tool.mypy.overrides.module"}[i]
+		if got := configPaths(t, src, line); len(got) != 1 || got[0] != want {
+			t.Fatalf("line %d = %#v, want %q", line, got, want)
+		}
+	}
+}
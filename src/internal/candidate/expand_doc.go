@@ -0,0 +1,275 @@
+package candidate
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// docHeading is a section title in a Markdown, reStructuredText or AsciiDoc
+// file. First and Last span the title together with its adornment lines.
+type docHeading struct {
+	Line      int
+	First     int
+	Last      int
+	Level     int
+	Title     string
+	Container string
+}
+
+const docContainerSeparator = " > "
+
+func expandDocHeading(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	headings := src.docOutline(cand.LangID)
+	i := sort.Search(len(headings), func(i int) bool { return headings[i].Last >= cand.Line })
+	if i == len(headings) || headings[i].First > cand.Line {
+		if looksLikeDocHeading(strings.TrimSpace(cand.Text)) {
+			return nil, true
+		}
+		return nil, false
+	}
+
+	heading := headings[i]
+	lines := src.Lines()
+	trimmed, indent := trimIndent(lines[heading.Line-1])
+	cand.Line = heading.Line
+	cand.Col = indent + 1
+	cand.Text = strings.TrimRight(trimmed, " \t")
+	cand.Key = heading.Title
+	cand.Container = heading.Container
+	cand.Kind = KindDoc
+	cand.SemanticScore = semanticDocScore
+	return []Candidate{cand}, true
+}
+
+func (s *sourceFile) docOutline(id LangID) []docHeading {
+	if s.docs != nil {
+		return s.docs
+	}
+
+	lines := s.Lines()
+	switch id {
+	case LangRST:
+		s.docs = rstHeadings(lines)
+	case LangAsciiDoc:
+		s.docs = asciiDocHeadings(lines)
+	default:
+		s.docs = markdownHeadings(lines)
+	}
+	nestDocHeadings(s.docs)
+	return s.docs
+}
+
+// nestDocHeadings fills in each heading's container from the chain of
+// shallower headings above it.
+func nestDocHeadings(headings []docHeading) {
+	var stack []docHeading
+	for i := range headings {
+		for len(stack) > 0 && stack[len(stack)-1].Level >= headings[i].Level {
+			stack = stack[:len(stack)-1]
+		}
+		titles := make([]string, len(stack))
+		for j, parent := range stack {
+			titles[j] = parent.Title
+		}
+		headings[i].Container = strings.Join(titles, docContainerSeparator)
+		stack = append(stack, headings[i])
+	}
+}
+
+func markdownHeadings(lines []string) []docHeading {
+	out := make([]docHeading, 0, 8)
+	fence := ""
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if trimmed := strings.TrimSpace(lines[i]); trimmed == "---" || trimmed == "..." {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	for i := start; i < len(lines); i++ {
+		trimmed, indent := trimIndent(lines[i])
+		if indent > 3 && fence == "" {
+			continue
+		}
+		if marker := markdownFence(trimmed); marker != "" {
+			switch {
+			case fence == "":
+				fence = marker
+			case strings.HasPrefix(marker, fence) && strings.TrimLeft(trimmed, marker[:1]) == "":
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		if level, title, ok := atxHeading(trimmed, '#', 6); ok {
+			out = append(out, docHeading{Line: i + 1, First: i + 1, Last: i + 1, Level: level, Title: title})
+			continue
+		}
+
+		if i == start || !isRepeated(strings.TrimRight(trimmed, " \t")) {
+			continue
+		}
+		level := 0
+		switch trimmed[0] {
+		case '=':
+			level = 1
+		case '-':
+			level = 2
+		default:
+			continue
+		}
+		prev, prevIndent := trimIndent(lines[i-1])
+		prev = strings.TrimSpace(prev)
+		if prev == "" || prevIndent > 3 || markdownFence(prev) != "" || isRepeated(prev) {
+			continue
+		}
+		if _, _, ok := atxHeading(prev, '#', 6); ok {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Last == i {
+			continue
+		}
+		out = append(out, docHeading{Line: i, First: i, Last: i + 1, Level: level, Title: prev})
+	}
+	return out
+}
+
+func markdownFence(trimmed string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, marker) {
+			end := len(marker)
+			for end < len(trimmed) && trimmed[end] == marker[0] {
+				end++
+			}
+			return trimmed[:end]
+		}
+	}
+	return ""
+}
+
+// atxHeading parses a heading written as a run of marker characters followed
+// by the title, as in Markdown "## Title" or AsciiDoc "== Title".
+func atxHeading(trimmed string, marker byte, maxLevel int) (int, string, bool) {
+	level := 0
+	for level < len(trimmed) && trimmed[level] == marker {
+		level++
+	}
+	if level == 0 || level > maxLevel || level == len(trimmed) || (trimmed[level] != ' ' && trimmed[level] != '\t') {
+		return 0, "", false
+	}
+
+	title := strings.TrimSpace(trimmed[level:])
+	if marker == '#' {
+		if closing := strings.TrimRight(title, "#"); closing == "" || strings.HasSuffix(closing, " ") || strings.HasSuffix(closing, "\t") {
+			title = strings.TrimSpace(closing)
+		}
+	}
+	if title == "" {
+		return 0, "", false
+	}
+	return level, title, true
+}
+
+// rstHeadings finds titles underlined, and optionally overlined, with a run of
+// punctuation. Levels follow the order in which adornment styles first appear.
+func rstHeadings(lines []string) []docHeading {
+	out := make([]docHeading, 0, 8)
+	styles := make(map[string]int)
+
+	for i := 1; i < len(lines); i++ {
+		under := strings.TrimRight(lines[i], " \t")
+		if !isDocAdornment(under) {
+			continue
+		}
+		title := strings.TrimRight(lines[i-1], " \t")
+		overlined := i >= 2 && strings.TrimRight(lines[i-2], " \t") == under
+		if title == "" || isDocAdornment(strings.TrimSpace(title)) {
+			continue
+		}
+		if !overlined && (title[0] == ' ' || title[0] == '\t') {
+			continue
+		}
+		if utf8.RuneCountInString(under) < utf8.RuneCountInString(strings.TrimSpace(title)) {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Last >= i {
+			continue
+		}
+
+		first := i
+		style := under[:1]
+		if overlined {
+			first = i - 1
+			style += "/"
+		}
+		level, ok := styles[style]
+		if !ok {
+			level = len(styles) + 1
+			styles[style] = level
+		}
+		out = append(out, docHeading{Line: i, First: first, Last: i + 1, Level: level, Title: strings.TrimSpace(title)})
+	}
+	return out
+}
+
+// asciiDocHeadings finds "= Title" section titles outside delimited blocks.
+func asciiDocHeadings(lines []string) []docHeading {
+	out := make([]docHeading, 0, 8)
+	block := ""
+
+	for i, raw := range lines {
+		trimmed := strings.TrimRight(raw, " \t")
+		if len(trimmed) >= 4 && isRepeated(trimmed) && strings.ContainsRune("-./+_*=", rune(trimmed[0])) {
+			switch block {
+			case "":
+				block = trimmed
+			case trimmed:
+				block = ""
+			}
+			continue
+		}
+		if block != "" {
+			continue
+		}
+
+		if level, title, ok := atxHeading(trimmed, '=', 6); ok {
+			out = append(out, docHeading{Line: i + 1, First: i + 1, Last: i + 1, Level: level, Title: title})
+		}
+	}
+	return out
+}
+
+// looksLikeDocHeading reports heading syntax that did not turn out to be a
+// heading, such as a comment inside a fenced code block or a thematic break.
+func looksLikeDocHeading(trimmed string) bool {
+	if _, _, ok := atxHeading(trimmed, '#', 6); ok {
+		return true
+	}
+	if _, _, ok := atxHeading(trimmed, '=', 6); ok {
+		return true
+	}
+	return isDocAdornment(trimmed)
+}
+
+func isDocAdornment(s string) bool {
+	return len(s) >= 2 && isRepeated(s) && strings.ContainsRune("=-~^\"'`#*+:._", rune(s[0]))
+}
+
+func isRepeated(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if s[i] != s[0] {
+			return false
+		}
+	}
+	return true
+}
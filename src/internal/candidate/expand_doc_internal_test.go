@@ -0,0 +1,107 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func docCandidates(t *testing.T, src *sourceFile) []string {
+	t.Helper()
+
+	var out []string
+	seen := make(map[int]bool)
+	for line := 1; line <= len(src.Lines()); line++ {
+		text := src.Lines()[line-1]
+		if !looksLikeDocHeading(text) {
+			continue
+		}
+		for _, cand := range expandTestMatch(t, src, line, ProducerConfig{}) {
+			if cand.Kind != KindDoc {
+				t.Fatalf("line %d kind = %q, want %q", line, cand.Kind, KindDoc)
+			}
+			if seen[cand.Line] {
+				continue
+			}
+			seen[cand.Line] = true
+			if cand.Container != "" {
+				out = append(out, cand.Container+" > "+cand.Key)
+			} else {
+				out = append(out, cand.Key)
+			}
+		}
+	}
+	return out
+}
+
+func TestExpandMarkdownHeadings(t *testing.T) {
+	src := testSourceFile("docs/runbook.md", `---
+title: Runbook
+---
+# Deploy
+
+Steps
+=====
+
+## Rollback procedure ##
+
+`+"```bash"+`
+# not a heading
+`+"```"+`
+
+Notes
+-----
+
+---
+
+# Appendix`)
+
+	got := docCandidates(t, src)
+	want := []string{"Deploy", "Steps", "Steps > Rollback procedure", "Steps > Notes", "Appendix"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("headings = %#v, want %#v", got, want)
+	}
+
+	cands := expandTestMatch(t, src, 7, ProducerConfig{})
+	if len(cands) != 1 || cands[0].Line != 6 || cands[0].Text != "Steps" {
+		t.Fatalf("setext underline match = %+v, want title line", cands)
+	}
+}
+
+func TestExpandRSTHeadings(t *testing.T) {
+	src := testSourceFile("docs/guide.rst", `=======
+ Guide
+=======
+
+Install
+-------
+
+From source
+~~~~~~~~~~~
+
+Usage
+-----`)
+
+	got := docCandidates(t, src)
+	want := []string{"Guide", "Guide > Install", "Guide > Install > From source", "Guide > Usage"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("headings = %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandAsciiDocHeadings(t *testing.T) {
+	src := testSourceFile("docs/adr.adoc", `= Decisions
+
+== Storage
+
+----
+== not a heading
+----
+
+=== Retention`)
+
+	got := docCandidates(t, src)
+	want := []string{"Decisions", "Decisions > Storage", "Decisions > Storage > Retention"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("headings = %#v, want %#v", got, want)
+	}
+}
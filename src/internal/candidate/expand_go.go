@@ -0,0 +1,244 @@
+package candidate
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func expandGoDeclaration(cand Candidate, src *sourceFile, cfg ProducerConfig) ([]Candidate, bool) {
+	text := strings.TrimSpace(cand.Text)
+	if keyword, ok := goGroupKeyword(text); ok {
+		lines := src.Lines()
+		if lines == nil {
+			return nil, false
+		}
+		return goGroupMembers(cand, lines, keyword, cfg.Members), true
+	}
+
+	if !cfg.Members {
+		return nil, false
+	}
+	spec, ok := strings.CutPrefix(text, "type ")
+	if !ok {
+		return nil, false
+	}
+	name, body, ok := goTypeSpecBody(spec)
+	if !ok {
+		return nil, false
+	}
+	lines := src.Lines()
+	if lines == nil {
+		return nil, false
+	}
+	out := []Candidate{cand}
+	return append(out, goBodyMembers(cand, lines, cand.Line, name, body)...), true
+}
+
+func goGroupKeyword(text string) (string, bool) {
+	keyword, rest := leadingIdentifier(text)
+	switch keyword {
+	case "const", "var", "type":
+	default:
+		return "", false
+	}
+
+	rest = strings.TrimLeft(rest, " \t")
+	if !strings.HasPrefix(rest, "(") {
+		return "", false
+	}
+	rest = strings.TrimSpace(rest[1:])
+	if rest != "" && !strings.HasPrefix(rest, "//") {
+		return "", false
+	}
+	return keyword, true
+}
+
+// goGroupMembers emits one candidate per spec of a grouped const, var or type
+// block, replacing the block header itself.
+func goGroupMembers(header Candidate, lines []string, keyword string, members bool) []Candidate {
+	out := make([]Candidate, 0, 8)
+	var sc bracketScanner
+
+	for lineNo := header.Line + 1; lineNo <= len(lines); lineNo++ {
+		raw, _ := sourceLineAt(lines, lineNo)
+		trimmed, indent := trimIndent(raw)
+
+		if sc.depth == 0 && sc.idle() {
+			if strings.HasPrefix(trimmed, ")") {
+				break
+			}
+			for _, name := range goSpecNames(keyword, trimmed) {
+				out = append(out, Candidate{
+					File:          header.File,
+					Line:          lineNo,
+					Col:           indent + 1,
+					Text:          trimmed,
+					Key:           name,
+					LangID:        header.LangID,
+					SemanticScore: computeSemanticScore(keyword + " " + trimmed),
+				})
+			}
+			if keyword == "type" && members {
+				if name, body, ok := goTypeSpecBody(trimmed); ok {
+					spec := Candidate{File: header.File, LangID: header.LangID}
+					out = append(out, goBodyMembers(spec, lines, lineNo, name, body)...)
+				}
+			}
+		}
+
+		sc.scan(trimmed)
+		if sc.depth < 0 {
+			break
+		}
+	}
+
+	return out
+}
+
+func goSpecNames(keyword string, spec string) []string {
+	if spec == "" || strings.HasPrefix(spec, "//") || strings.HasPrefix(spec, "/*") {
+		return nil
+	}
+
+	name, rest := leadingIdentifier(spec)
+	if name == "" {
+		return nil
+	}
+	if keyword == "type" {
+		return []string{name}
+	}
+
+	var names []string
+	for name != "" {
+		if name != "_" {
+			names = append(names, name)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		if !strings.HasPrefix(rest, ",") {
+			break
+		}
+		name, rest = leadingIdentifier(strings.TrimLeft(rest[1:], " \t"))
+	}
+	return names
+}
+
+// goTypeSpecBody reports the name of a type spec whose struct or interface body
+// opens on the same line.
+func goTypeSpecBody(spec string) (string, string, bool) {
+	name, rest := leadingIdentifier(strings.TrimLeft(spec, " \t"))
+	if name == "" {
+		return "", "", false
+	}
+
+	rest = strings.TrimLeft(rest, " \t")
+	if strings.HasPrefix(rest, "[") {
+		depth := 0
+		for i := 0; i < len(rest); i++ {
+			if rest[i] == '[' {
+				depth++
+			} else if rest[i] == ']' {
+				depth--
+				if depth == 0 {
+					rest = strings.TrimLeft(rest[i+1:], " \t")
+					break
+				}
+			}
+		}
+	}
+
+	for _, body := range []string{"struct", "interface"} {
+		if !strings.HasPrefix(rest, body) {
+			continue
+		}
+		tail := strings.TrimSpace(rest[len(body):])
+		if strings.HasPrefix(tail, "{") && !strings.Contains(tail, "}") {
+			return name, body, true
+		}
+	}
+	return "", "", false
+}
+
+// goBodyMembers emits the exported fields of a struct or the exported methods
+// of an interface whose body starts on openLine.
+func goBodyMembers(owner Candidate, lines []string, openLine int, container string, body string) []Candidate {
+	out := make([]Candidate, 0, 8)
+	var sc bracketScanner
+
+	for lineNo := openLine + 1; lineNo <= len(lines); lineNo++ {
+		raw, _ := sourceLineAt(lines, lineNo)
+		trimmed, indent := trimIndent(raw)
+
+		if sc.depth == 0 && sc.idle() {
+			if strings.HasPrefix(trimmed, "}") {
+				break
+			}
+			for _, name := range goMemberNames(body, trimmed) {
+				score := semanticFieldScore
+				if body == "interface" {
+					score = semanticMethodScore
+				}
+				out = append(out, Candidate{
+					File:          owner.File,
+					Line:          lineNo,
+					Col:           indent + 1,
+					Text:          trimmed,
+					Key:           name,
+					LangID:        owner.LangID,
+					SemanticScore: score,
+					Container:     container,
+				})
+			}
+		}
+
+		sc.scan(trimmed)
+		if sc.depth < 0 {
+			break
+		}
+	}
+
+	return out
+}
+
+func goMemberNames(body string, line string) []string {
+	name, rest := leadingIdentifier(line)
+	if name == "" {
+		return nil
+	}
+	rest = strings.TrimLeft(rest, " \t")
+
+	if body == "interface" {
+		if !strings.HasPrefix(rest, "(") || !isExportedGoName(name) {
+			return nil
+		}
+		return []string{name}
+	}
+
+	names := []string{name}
+	for strings.HasPrefix(rest, ",") {
+		name, rest = leadingIdentifier(strings.TrimLeft(rest[1:], " \t"))
+		if name == "" {
+			return nil
+		}
+		names = append(names, name)
+		rest = strings.TrimLeft(rest, " \t")
+	}
+
+	// A lone identifier, a qualified name or a tag is an embedded field.
+	if rest == "" || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "`") || strings.HasPrefix(rest, "\"") || strings.HasPrefix(rest, "//") {
+		return nil
+	}
+
+	exported := names[:0]
+	for _, n := range names {
+		if isExportedGoName(n) {
+			exported = append(exported, n)
+		}
+	}
+	return exported
+}
+
+func isExportedGoName(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
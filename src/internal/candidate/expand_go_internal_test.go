@@ -0,0 +1,160 @@
+package candidate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"snav/internal/lang"
+)
+
+func testSourceFile(path string, src string) *sourceFile {
+	return &sourceFile{
+		path:   path,
+		lines:  strings.Split(src, "\n"),
+		loaded: true,
+	}
+}
+
+func expandTestMatch(t *testing.T, src *sourceFile, line int, cfg ProducerConfig) []Candidate {
+	t.Helper()
+
+	lines := src.Lines()
+	text := strings.TrimLeft(lines[line-1], " \t")
+	cand := Candidate{
+		File:          src.path,
+		Line:          line,
+		Col:           1,
+		Text:          text,
+		Key:           ExtractKey(text, src.path),
+		LangID:        lang.Detect(src.path),
+		SemanticScore: computeSemanticScore(text),
+	}
+	out, ok := expandCandidate(cand, src, cfg)
+	if !ok {
+		return []Candidate{cand}
+	}
+	return out
+}
+
+func candidateKeys(cands []Candidate) []string {
+	out := make([]string, 0, len(cands))
+	for _, cand := range cands {
+		key := cand.Key
+		if cand.Container != "" {
+			key = cand.Container + "." + key
+		}
+		out = append(out, key)
+	}
+	return out
+}
+
+func TestExpandGoGroupedConstIotaEnum(t *testing.T) {
+	src := testSourceFile("status.go", `package status
+
+const (
+	// StatusUnknown is the zero value.
+	StatusUnknown Status = iota
+	StatusActive
+	StatusDisabled // soft delete
+
+	maxA, maxB = 1, 2
+)
+`)
+
+	got := expandTestMatch(t, src, 3, ProducerConfig{})
+	want := []string{"StatusUnknown", "StatusActive", "StatusDisabled", "maxA", "maxB"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %#v, want %#v", keys, want)
+	}
+	if got[1].Line != 6 || got[1].Col != 2 || got[1].Text != "StatusActive" {
+		t.Fatalf("StatusActive location = %d:%d %q", got[1].Line, got[1].Col, got[1].Text)
+	}
+	if got[0].SemanticScore != semanticConstScore {
+		t.Fatalf("SemanticScore = %d, want %d", got[0].SemanticScore, semanticConstScore)
+	}
+}
+
+func TestExpandGoGroupedVarSkipsMultiLineValues(t *testing.T) {
+	src := testSourceFile("vars.go", `var (
+	defaultNames = []string{
+		"a",
+		"b",
+	}
+	ErrClosed = errors.New("closed (x")
+)
+`)
+
+	got := expandTestMatch(t, src, 1, ProducerConfig{})
+	want := []string{"defaultNames", "ErrClosed"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %#v, want %#v", keys, want)
+	}
+}
+
+func TestExpandGoGroupedTypesWithMembers(t *testing.T) {
+	src := testSourceFile("types.go", `type (
+	Server struct {
+		Addr, Host string
+		handler    Handler
+		sync.Mutex
+		Options struct {
+			Verbose bool
+		}
+	}
+	Handler interface {
+		ServeHTTP(w ResponseWriter, r *Request)
+		io.Closer
+	}
+	ID = string
+)
+`)
+
+	got := expandTestMatch(t, src, 1, ProducerConfig{})
+	want := []string{"Server", "Handler", "ID"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys without members = %#v, want %#v", keys, want)
+	}
+
+	got = expandTestMatch(t, src, 1, ProducerConfig{Members: true})
+	want = []string{"Server", "Server.Addr", "Server.Host", "Server.Options", "Handler", "Handler.ServeHTTP", "ID"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys with members = %#v, want %#v", keys, want)
+	}
+}
+
+func TestExpandGoStructFieldsOnlyInMembersMode(t *testing.T) {
+	src := testSourceFile("config.go", `type Config[T any] struct {
+	Root    string `+"`json:\"root\"`"+`
+	Workers int
+	cache   map[string]T
+}
+`)
+
+	if got := expandTestMatch(t, src, 1, ProducerConfig{}); len(got) != 1 || got[0].Key != "Config" {
+		t.Fatalf("expected only the type without members mode, got %#v", candidateKeys(got))
+	}
+
+	got := expandTestMatch(t, src, 1, ProducerConfig{Members: true})
+	want := []string{"Config", "Config.Root", "Config.Workers"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %#v, want %#v", keys, want)
+	}
+	if got[1].SemanticScore != semanticFieldScore {
+		t.Fatalf("field SemanticScore = %d, want %d", got[1].SemanticScore, semanticFieldScore)
+	}
+}
+
+func TestExpandGoUnicodeExportedMembers(t *testing.T) {
+	src := testSourceFile("größe.go", `type Größe struct {
+	Äußere int
+	ärger  int
+}
+`)
+
+	got := expandTestMatch(t, src, 1, ProducerConfig{Members: true})
+	want := []string{"Größe", "Größe.Äußere"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %#v, want %#v", keys, want)
+	}
+}
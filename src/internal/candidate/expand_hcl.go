@@ -0,0 +1,230 @@
+package candidate
+
+import "strings"
+
+// hclLine describes the block nesting at the start of a line of HCL.
+type hclLine struct {
+	Depth     int
+	Container string
+	InHeredoc bool
+}
+
+var hclBlockKinds = map[string]Kind{
+	"resource": KindResource,
+	"data":     KindResource,
+	"module":   KindModule,
+	"variable": KindVariable,
+	"output":   KindOutput,
+}
+
+// expandHCLEntry keys Terraform blocks by the address they are referenced by,
+// such as aws_s3_bucket.logs, module.vpc or var.region, and attaches the
+// enclosing block address to nested attributes.
+func expandHCLEntry(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	outline := src.hclOutline()
+	if cand.Line < 1 || cand.Line > len(outline) {
+		return nil, false
+	}
+	info := outline[cand.Line-1]
+	if info.InHeredoc {
+		return nil, true
+	}
+
+	trimmed := strings.TrimSpace(cand.Text)
+	if info.Depth == 0 {
+		typ, labels, ok := hclBlockHeader(trimmed)
+		if !ok {
+			return nil, false
+		}
+		if typ == "locals" {
+			// A locals block has no address of its own; its entries are
+			// listed as local.<name> instead.
+			return nil, true
+		}
+		cand.Key = hclAddress(typ, labels)
+		cand.Kind = hclBlockKinds[typ]
+		switch cand.Kind {
+		case KindResource, KindModule:
+			cand.SemanticScore = semanticTypeDeclScore
+		case KindVariable, KindOutput:
+			cand.SemanticScore = semanticConstScore
+		}
+		return []Candidate{cand}, true
+	}
+
+	if info.Container == "local" && info.Depth == 1 {
+		name, rest := hclIdentifier(trimmed)
+		if name != "" && strings.HasPrefix(strings.TrimLeft(rest, " \t"), "=") {
+			cand.Key = "local." + name
+			cand.Kind = KindLocal
+			cand.SemanticScore = semanticConstScore
+			return []Candidate{cand}, true
+		}
+	}
+	if info.Container == "" {
+		return nil, false
+	}
+	cand.Container = info.Container
+	return []Candidate{cand}, true
+}
+
+func hclAddress(typ string, labels []string) string {
+	switch typ {
+	case "resource":
+		return strings.Join(labels, ".")
+	case "variable":
+		typ = "var"
+	case "locals":
+		return "local"
+	}
+	if len(labels) == 0 {
+		return typ
+	}
+	return typ + "." + strings.Join(labels, ".")
+}
+
+// hclBlockHeader parses `type "label" label {`, the opening line of a block.
+func hclBlockHeader(trimmed string) (string, []string, bool) {
+	typ, rest := hclIdentifier(trimmed)
+	if typ == "" {
+		return "", nil, false
+	}
+
+	var labels []string
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		switch {
+		case strings.HasPrefix(rest, "{"):
+			return typ, labels, true
+		case strings.HasPrefix(rest, `"`):
+			end := skipQuoted(rest, 0, '"')
+			if end >= len(rest) {
+				return "", nil, false
+			}
+			labels = append(labels, rest[1:end])
+			rest = rest[end+1:]
+		default:
+			label, tail := hclIdentifier(rest)
+			if label == "" {
+				return "", nil, false
+			}
+			labels = append(labels, label)
+			rest = tail
+		}
+	}
+}
+
+// hclMapAttribute parses `name = {`, an attribute whose value is a map.
+func hclMapAttribute(trimmed string) (string, bool) {
+	name, rest := hclIdentifier(trimmed)
+	if name == "" {
+		return "", false
+	}
+	rest, ok := strings.CutPrefix(strings.TrimLeft(rest, " \t"), "=")
+	if !ok || strings.HasPrefix(rest, "=") {
+		return "", false
+	}
+	return name, strings.HasPrefix(strings.TrimLeft(rest, " \t"), "{")
+}
+
+func hclIdentifier(s string) (string, string) {
+	if strings.HasPrefix(s, "-") {
+		return "", s
+	}
+	i := identifierEnd(s, 0, "-")
+	return s[:i], s[i:]
+}
+
+func (s *sourceFile) hclOutline() []hclLine {
+	if s.hcl == nil {
+		s.hcl = buildHCLOutline(s.Lines())
+	}
+	return s.hcl
+}
+
+// buildHCLOutline tracks block nesting, skipping comments, strings and
+// heredocs. Top-level blocks are named by their address, nested blocks by
+// their type and map attributes by their name.
+func buildHCLOutline(lines []string) []hclLine {
+	type frame struct {
+		name  string
+		depth int
+	}
+
+	out := make([]hclLine, len(lines))
+	var stack []frame
+	depth := 0
+	inComment := false
+	heredoc := ""
+
+	for i, raw := range lines {
+		trimmed := strings.TrimSpace(raw)
+		if heredoc != "" {
+			out[i] = hclLine{Depth: depth, InHeredoc: true}
+			if trimmed == heredoc {
+				heredoc = ""
+			}
+			continue
+		}
+
+		names := make([]string, len(stack))
+		for j, f := range stack {
+			names[j] = f.name
+		}
+		out[i] = hclLine{Depth: depth, Container: strings.Join(names, ".")}
+
+		if !inComment {
+			if typ, labels, ok := hclBlockHeader(trimmed); ok {
+				name := typ
+				if depth == 0 {
+					name = hclAddress(typ, labels)
+				}
+				stack = append(stack, frame{name: name, depth: depth})
+			} else if name, ok := hclMapAttribute(trimmed); ok && depth > 0 {
+				stack = append(stack, frame{name: name, depth: depth})
+			}
+		}
+
+		for j := 0; j < len(trimmed); j++ {
+			if inComment {
+				if strings.HasPrefix(trimmed[j:], "*/") {
+					inComment = false
+					j++
+				}
+				continue
+			}
+			switch c := trimmed[j]; c {
+			case '#':
+				j = len(trimmed)
+			case '/':
+				if strings.HasPrefix(trimmed[j:], "//") {
+					j = len(trimmed)
+				} else if strings.HasPrefix(trimmed[j:], "/*") {
+					inComment = true
+					j++
+				}
+			case '"':
+				j = skipQuoted(trimmed, j, '"')
+			case '<':
+				if marker, ok := strings.CutPrefix(trimmed[j:], "<<"); ok {
+					marker = strings.TrimPrefix(marker, "-")
+					if name, _ := hclIdentifier(marker); name != "" && name == strings.TrimSpace(marker) {
+						heredoc = name
+						j = len(trimmed)
+					}
+				}
+			case '{', '[', '(':
+				depth++
+			case '}', ']', ')':
+				depth--
+				for len(stack) > 0 && depth <= stack[len(stack)-1].depth {
+					stack = stack[:len(stack)-1]
+				}
+			}
+		}
+		if depth < 0 {
+			depth = 0
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTerraformBlockAddresses(t *testing.T) {
+	src := testSourceFile("main.tf", `resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+  versioning {
+    enabled = true
+  }
+  policy = <<EOF
+{ "Version": "2012-10-17" }
+EOF
+}
+
+data "aws_iam_policy_document" "x" {}
+
+module "vpc" {
+  source = "./vpc"
+}
+
+variable "region" {
+  default = "eu-west-1" # {
+}
+
+output "arn" {
+  value = aws_s3_bucket.logs.arn
+}
+
+locals {
+  name_prefix = "app"
+  tags = {
+    team = "infra"
+  }
+}`)
+
+	var got []kindKey
+	for _, line := range []int{1, 2, 4, 7, 11, 13, 14, 17, 18, 21, 25, 26, 27, 28} {
+		got = append(got, kindKeys(expandTestMatch(t, src, line, ProducerConfig{}))...)
+	}
+	want := []kindKey{
+		{Kind: KindResource, Key: "aws_s3_bucket.logs"},
+		{Kind: KindCode, Key: "aws_s3_bucket.logs.bucket"},
+		{Kind: KindCode, Key: "aws_s3_bucket.logs.versioning.enabled"},
+		{Kind: KindResource, Key: "data.aws_iam_policy_document.x"},
+		{Kind: KindModule, Key: "module.vpc"},
+		{Kind: KindCode, Key: "module.vpc.source"},
+		{Kind: KindVariable, Key: "var.region"},
+		{Kind: KindCode, Key: "var.region.default"},
+		{Kind: KindOutput, Key: "output.arn"},
+		{Kind: KindLocal, Key: "local.name_prefix"},
+		{Kind: KindLocal, Key: "local.tags"},
+		{Kind: KindCode, Key: "local.tags.team"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestExpandTerraformUnicodeIdentifiers(t *testing.T) {
+	src := testSourceFile("main.tf", `locals {
+  名前 = "app"
+}`)
+
+	got := kindKeys(expandTestMatch(t, src, 2, ProducerConfig{}))
+	want := []kindKey{{Kind: KindLocal, Key: "local.名前"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandTerraformNestedLocalMaps(t *testing.T) {
+	src := testSourceFile("locals.tf", `locals {
+  settings = {
+    db = {
+      port = 5432
+    }
+    region = "eu-west-1"
+  }
+  flags = { debug = true }
+  name = "app"
+}`)
+
+	var got []kindKey
+	for _, line := range []int{2, 3, 4, 6, 8, 9} {
+		got = append(got, kindKeys(expandTestMatch(t, src, line, ProducerConfig{}))...)
+	}
+	want := []kindKey{
+		{Kind: KindLocal, Key: "local.settings"},
+		{Kind: KindCode, Key: "local.settings.db"},
+		{Kind: KindCode, Key: "local.settings.db.port"},
+		{Kind: KindCode, Key: "local.settings.region"},
+		{Kind: KindLocal, Key: "local.flags"},
+		{Kind: KindLocal, Key: "local.name"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v\nwant %#v", got, want)
+	}
+}
@@ -0,0 +1,298 @@
+package candidate
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+func expandJSDeclaration(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	text := strings.TrimSpace(cand.Text)
+	changed := false
+	if isReactComponentFile(cand.File) && isReactComponentName(cand.Key) {
+		base, visibility := semanticScoreParts(text)
+		if base == semanticFunctionScore || base == semanticConstructorScore {
+			cand.SemanticScore = semanticTypeDeclScore + visibility
+			changed = true
+		}
+	}
+
+	parse := jsMemberParser(nil)
+	container, ok := jsClassName(text)
+	if ok {
+		parse = jsClassMember
+	} else if container, ok = jsObjectBindingName(text); ok {
+		parse = jsObjectMember
+	}
+
+	var lines []string
+	if parse != nil {
+		lines = src.Lines()
+	}
+	if lines == nil {
+		if changed {
+			return []Candidate{cand}, true
+		}
+		return nil, false
+	}
+
+	out := []Candidate{cand}
+	return append(out, jsBodyMembers(cand, lines, container, parse)...), true
+}
+
+func isReactComponentFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsx", ".jsx":
+		return true
+	default:
+		return false
+	}
+}
+
+func isReactComponentName(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
+
+// jsClassName reports the class declared on a line such as
+// "export default abstract class Name<T> extends Base {".
+func jsClassName(text string) (string, bool) {
+	rest := text
+	for {
+		token, tail := jsIdentifier(rest)
+		switch token {
+		case "export", "default", "declare", "abstract":
+			rest = strings.TrimLeft(tail, " \t")
+			continue
+		case "class":
+			name, _ := jsIdentifier(strings.TrimLeft(tail, " \t"))
+			if name == "" || name == "extends" || name == "implements" {
+				return "", false
+			}
+			return name, true
+		default:
+			return "", false
+		}
+	}
+}
+
+// jsObjectBindingName reports the binding of "const name = {" when the object
+// literal continues on the following lines.
+func jsObjectBindingName(text string) (string, bool) {
+	rest := text
+	for {
+		token, tail := jsIdentifier(rest)
+		switch token {
+		case "export", "declare":
+			rest = strings.TrimLeft(tail, " \t")
+			continue
+		case "const", "let", "var":
+			name, after := jsIdentifier(strings.TrimLeft(tail, " \t"))
+			if name == "" {
+				return "", false
+			}
+			after = strings.TrimLeft(after, " \t")
+			if strings.HasPrefix(after, ":") {
+				eq := assignmentIndex(after)
+				if eq < 0 {
+					return "", false
+				}
+				after = after[eq:]
+			}
+			value, ok := strings.CutPrefix(after, "=")
+			if !ok {
+				return "", false
+			}
+			value = strings.TrimSpace(value)
+			if value != "{" && !strings.HasPrefix(value, "{ //") {
+				return "", false
+			}
+			return name, true
+		default:
+			return "", false
+		}
+	}
+}
+
+type jsMemberParser func(line string) (string, int16, bool)
+
+// jsBodyMembers emits the members found directly inside the braces opened on
+// the owner's declaration line.
+func jsBodyMembers(owner Candidate, lines []string, container string, parse jsMemberParser) []Candidate {
+	var sc bracketScanner
+	sc.scan(strings.TrimLeft(lines[owner.Line-1], " \t"))
+	if sc.depth != 1 || !sc.idle() {
+		return nil
+	}
+
+	out := make([]Candidate, 0, 8)
+	for lineNo := owner.Line + 1; lineNo <= len(lines); lineNo++ {
+		trimmed, indent := trimIndent(lines[lineNo-1])
+		if sc.depth == 1 && sc.idle() {
+			if strings.HasPrefix(trimmed, "}") {
+				break
+			}
+			if name, score, ok := parse(trimmed); ok {
+				out = append(out, Candidate{
+					File:          owner.File,
+					Line:          lineNo,
+					Col:           indent + 1,
+					Text:          trimmed,
+					Key:           name,
+					LangID:        owner.LangID,
+					SemanticScore: score,
+					Container:     container,
+				})
+			}
+		}
+
+		sc.scan(trimmed)
+		if sc.depth < 1 {
+			break
+		}
+	}
+	return out
+}
+
+func jsClassMember(line string) (string, int16, bool) {
+	rest := skipJSDecorators(line)
+	for {
+		token, tail := jsIdentifier(rest)
+		after := strings.TrimLeft(tail, " \t")
+		switch token {
+		case "public", "private", "protected", "static", "readonly", "abstract", "override", "declare", "async", "accessor", "get", "set":
+			if next, _ := jsIdentifier(strings.TrimPrefix(after, "*")); next != "" || strings.HasPrefix(after, "*") {
+				rest = after
+				continue
+			}
+		}
+		break
+	}
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "*"), " \t")
+
+	name, after := jsIdentifier(rest)
+	if name == "" || jsStatementWords[name] {
+		return "", 0, false
+	}
+	after = strings.TrimLeft(strings.TrimLeft(after, "?!"), " \t")
+	if strings.HasPrefix(after, "<") {
+		after = skipAngleBrackets(after)
+	}
+
+	switch {
+	case strings.HasPrefix(after, "("):
+		if name == "constructor" {
+			return name, semanticConstructorScore, true
+		}
+		return name, semanticMethodScore, true
+	case strings.HasPrefix(after, "=") || strings.HasPrefix(after, ":"):
+		if rhs, ok := bindingValue(strings.ToLower("x " + after)); ok && isFunctionValue(rhs) {
+			return name, semanticMethodScore, true
+		}
+	}
+	return "", 0, false
+}
+
+func jsObjectMember(line string) (string, int16, bool) {
+	rest := line
+	if token, tail := jsIdentifier(rest); token == "async" || token == "get" || token == "set" {
+		if next, _ := jsIdentifier(strings.TrimLeft(strings.TrimPrefix(strings.TrimLeft(tail, " \t"), "*"), " \t")); next != "" {
+			rest = strings.TrimLeft(tail, " \t")
+		}
+	}
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "*"), " \t")
+
+	name, after := jsIdentifier(rest)
+	if name == "" {
+		name, after = jsQuotedName(rest)
+	}
+	if name == "" || jsStatementWords[name] {
+		return "", 0, false
+	}
+	after = strings.TrimLeft(after, " \t")
+
+	switch {
+	case strings.HasPrefix(after, "("):
+		return name, semanticMethodScore, true
+	case strings.HasPrefix(after, ":"):
+		if isFunctionValue(strings.ToLower(strings.TrimLeft(after[1:], " \t"))) {
+			return name, semanticMethodScore, true
+		}
+	}
+	return "", 0, false
+}
+
+func skipJSDecorators(line string) string {
+	rest := line
+	for strings.HasPrefix(rest, "@") {
+		_, tail := jsIdentifier(rest[1:])
+		for strings.HasPrefix(tail, ".") {
+			_, tail = jsIdentifier(tail[1:])
+		}
+		if strings.HasPrefix(tail, "(") {
+			depth := 0
+			end := len(tail)
+			for i := 0; i < len(tail); i++ {
+				switch tail[i] {
+				case '"', '\'', '`':
+					i = skipQuoted(tail, i, tail[i])
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+				if depth == 0 {
+					end = min(i+1, len(tail))
+					break
+				}
+			}
+			tail = tail[end:]
+		}
+		rest = strings.TrimLeft(tail, " \t")
+	}
+	return rest
+}
+
+func skipAngleBrackets(s string) string {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				return strings.TrimLeft(s[i+1:], " \t")
+			}
+		}
+	}
+	return s
+}
+
+// jsIdentifier cuts the identifier or #private name at the start of s.
+func jsIdentifier(s string) (string, string) {
+	start := 0
+	if strings.HasPrefix(s, "#") {
+		start = 1
+	}
+	i := identifierEnd(s, start, "$")
+	if i == start {
+		return "", s
+	}
+	return s[:i], s[i:]
+}
+
+func jsQuotedName(s string) (string, string) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", s
+	}
+	end := skipQuoted(s, 0, s[0])
+	if end >= len(s) {
+		return "", s
+	}
+	return s[1:end], s[end+1:]
+}
+
+var jsStatementWords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"return": true, "super": true, "this": true, "new": true, "await": true,
+	"throw": true, "else": true, "do": true, "try": true, "case": true,
+}
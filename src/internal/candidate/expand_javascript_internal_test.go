@@ -0,0 +1,113 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandJSClassMembers(t *testing.T) {
+	src := testSourceFile("src/widget.ts", `export default class Widget<T> extends Base {
+	private count = 0;
+	static readonly defaults = { size: 1 };
+
+	constructor(private readonly el: HTMLElement) {
+		super();
+	}
+
+	render() {
+		if (this.count > 0) {
+			return;
+		}
+	}
+
+	get value(): number {
+		return this.count;
+	}
+
+	@HostListener("click", ["$event"])
+	async onClick(event: Event): Promise<void> {}
+
+	handleResize = (event: UIEvent) => {
+		const inner = () => {};
+	};
+
+	*[Symbol.iterator]() {}
+}
+`)
+
+	got := expandTestMatch(t, src, 1, ProducerConfig{})
+	want := []string{"Widget", "Widget.constructor", "Widget.render", "Widget.value", "Widget.onClick", "Widget.handleResize"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %#v, want %#v", keys, want)
+	}
+	if got[1].SemanticScore != semanticConstructorScore {
+		t.Fatalf("constructor SemanticScore = %d, want %d", got[1].SemanticScore, semanticConstructorScore)
+	}
+	if got[2].SemanticScore != semanticMethodScore || got[2].Line != 9 || got[2].Text != "render() {" {
+		t.Fatalf("render candidate = %+v", got[2])
+	}
+}
+
+func TestExpandJSObjectLiteralMethods(t *testing.T) {
+	src := testSourceFile("src/api.js", `export const api = {
+	baseURL: "/v1",
+	list: async (query) => fetch(query),
+	get(id) {
+		return fetch(id);
+	},
+	"remove": function (id) {},
+	retry: n => n + 1,
+};
+`)
+
+	got := expandTestMatch(t, src, 1, ProducerConfig{})
+	want := []string{"api", "api.list", "api.get", "api.remove", "api.retry"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %#v, want %#v", keys, want)
+	}
+}
+
+func TestExpandJSUnicodeClassAndObjectMembers(t *testing.T) {
+	src := testSourceFile("src/注文.ts", `export class 注文 {
+	合計() {
+		return 0;
+	}
+	#数量 = 1;
+}
+
+const 設定 = {
+	読み込み(id) {},
+};
+`)
+
+	got := expandTestMatch(t, src, 1, ProducerConfig{})
+	want := []string{"注文", "注文.合計"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("class keys = %#v, want %#v", keys, want)
+	}
+	if got[1].Container != "注文" || got[1].Line != 2 {
+		t.Fatalf("method = %+v", got[1])
+	}
+
+	got = expandTestMatch(t, src, 8, ProducerConfig{})
+	want = []string{"設定", "設定.読み込み"}
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Fatalf("object keys = %#v, want %#v", keys, want)
+	}
+}
+
+func TestExpandJSReactComponentsScoreAsTypes(t *testing.T) {
+	src := testSourceFile("src/Button.tsx", `export const Button = ({ label }: Props) => <button>{label}</button>;
+export function useButton() {}
+`)
+
+	got := expandTestMatch(t, src, 1, ProducerConfig{})
+	if len(got) != 1 || got[0].SemanticScore != semanticTypeDeclScore+semanticVisibilityPublic {
+		t.Fatalf("component candidate = %+v", got)
+	}
+
+	got = expandTestMatch(t, src, 2, ProducerConfig{})
+	if len(got) != 1 || got[0].SemanticScore != semanticFunctionScore+semanticVisibilityPublic {
+		t.Fatalf("hook candidate = %+v", got)
+	}
+}
@@ -0,0 +1,361 @@
+package candidate
+
+import (
+	"regexp"
+	"strings"
+)
+
+// declForm is one declaration form of a language. Its regexp selects lines in
+// the rg pass and names the declaration when the match is expanded.
+type declForm struct {
+	re    *regexp.Regexp
+	build func(cand Candidate, m []string) ([]Candidate, bool)
+}
+
+// languageDeclarations describes a language whose declarations are found by
+// the language declarations pass rather than the default declaration pattern.
+type languageDeclarations struct {
+	name  string
+	lang  LangID
+	globs []string
+	forms []declForm
+}
+
+func (l languageDeclarations) pattern() string {
+	return joinDeclForms(l.forms)
+}
+
+func joinDeclForms(forms []declForm) string {
+	parts := make([]string, len(forms))
+	for i, form := range forms {
+		parts[i] = form.re.String()
+	}
+	return strings.Join(parts, "|")
+}
+
+// Objective-C headers are searched by the declarations pass, which also
+// matches objcHeaderPattern for them.
+var declarationLanguages = []languageDeclarations{
+	{name: "lua", lang: LangLua, globs: []string{"*.lua"}, forms: luaDeclForms},
+	{name: "elixir", lang: LangElixir, globs: []string{"*.ex", "*.exs"}, forms: elixirDeclForms},
+	{name: "scala", lang: LangScala, globs: []string{"*.scala", "*.sc", "*.sbt"}, forms: scalaDeclForms},
+	{name: "haskell", lang: LangHaskell, globs: []string{"*.hs"}, forms: haskellDeclForms},
+	{name: "ocaml", lang: LangOCaml, globs: []string{"*.ml", "*.mli"}, forms: ocamlDeclForms},
+	{name: "dart", lang: LangDart, globs: []string{"*.dart"}, forms: dartDeclForms},
+	{name: "objc", lang: LangObjC, globs: []string{"*.m", "*.mm"}, forms: objcDeclForms},
+}
+
+// languageDeclarationGlobs and languageDeclarationPattern search every
+// language of declarationLanguages in one pass. A line may match the forms
+// of another language; expandLanguageDeclaration drops it.
+var (
+	languageDeclarationGlobs   = collectLanguageGlobs()
+	languageDeclarationPattern = collectLanguagePatterns()
+	objcHeaderPattern          = joinDeclForms(objcDeclForms)
+)
+
+func collectLanguageGlobs() []string {
+	var globs []string
+	for _, l := range declarationLanguages {
+		globs = append(globs, l.globs...)
+	}
+	return globs
+}
+
+func collectLanguagePatterns() string {
+	parts := make([]string, len(declarationLanguages))
+	for i, l := range declarationLanguages {
+		parts[i] = l.pattern()
+	}
+	return strings.Join(parts, "|")
+}
+
+// expandLanguageDeclaration names a declaration using the forms of its
+// language. Lines that match no form are dropped when the language
+// declarations pass found them, and left unchanged otherwise, as for the C
+// declarations of an Objective-C header.
+func expandLanguageDeclaration(cand Candidate) ([]Candidate, bool) {
+	text := strings.TrimSpace(cand.Text)
+	for _, l := range declarationLanguages {
+		if l.lang != cand.LangID {
+			continue
+		}
+		for _, form := range l.forms {
+			if m := form.re.FindStringSubmatch(text); m != nil {
+				return form.build(cand, m)
+			}
+		}
+		if pathMatchesGlobs(l.globs, cand.File) {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+func declared(cand Candidate, key string, score int16) []Candidate {
+	cand.Key = key
+	cand.SemanticScore = score
+	return []Candidate{cand}
+}
+
+var luaDeclForms = []declForm{
+	{re: regexp.MustCompile(`^\s*(local\s+)?function\s+([\p{L}_][\p{L}\p{M}\p{N}_]*(?:[.:][\p{L}_][\p{L}\p{M}\p{N}_]*)*)\s*\(`), build: luaFunction},
+	{re: regexp.MustCompile(`^\s*(local\s+)?([\p{L}_][\p{L}\p{M}\p{N}_]*(?:\.[\p{L}_][\p{L}\p{M}\p{N}_]*)*)\s*=\s*function\s*\(`), build: luaFunction},
+}
+
+// luaFunction keys M.name and M:name by name, with the table as container.
+func luaFunction(cand Candidate, m []string) ([]Candidate, bool) {
+	name := m[2]
+	score := semanticFunctionScore
+	if i := strings.LastIndexAny(name, ".:"); i >= 0 {
+		if name[i] == ':' {
+			score = semanticMethodScore
+		}
+		cand.Container = name[:i]
+		name = name[i+1:]
+	}
+	if m[1] != "" {
+		score += semanticVisibilityPrivate
+	}
+	return declared(cand, name, score), true
+}
+
+var elixirDeclForms = []declForm{
+	{re: regexp.MustCompile(`^\s*(defmodule|defprotocol|defimpl|defmacrop|defmacro|defguardp|defguard|defdelegate|defp|def)\s+([\p{L}_][\p{L}\p{M}\p{N}_.]*[?!]?)`), build: elixirDefinition},
+}
+
+func elixirDefinition(cand Candidate, m []string) ([]Candidate, bool) {
+	kw, name := m[1], m[2]
+	if name == "unquote" {
+		return nil, true
+	}
+	switch kw {
+	case "defmodule", "defimpl":
+		return declared(cand, name, semanticModuleScore), true
+	case "defprotocol":
+		return declared(cand, name, semanticTypeDeclScore), true
+	case "defp", "defmacrop", "defguardp":
+		return declared(cand, name, semanticFunctionScore+semanticVisibilityPrivate), true
+	default:
+		return declared(cand, name, semanticFunctionScore), true
+	}
+}
+
+var scalaDeclForms = []declForm{
+	{re: regexp.MustCompile(`^\s*((?:(?:(?:private|protected)(?:\[[\p{L}\p{M}\p{N}_.]*\])?|final|sealed|abstract|implicit|lazy|override|case|inline|opaque|open|transparent|infix)\s+)*)(class|object|trait|enum|def|val|var|type|given)\s+([\p{L}_][\p{L}\p{M}\p{N}_]*|\x60[^\x60]+\x60|[!#%&*+/:<=>?@\\^|~-]+)`), build: scalaDefinition},
+	{re: regexp.MustCompile(`^\s*package\s+([\p{L}_][\p{L}\p{M}\p{N}_.]*)\s*$`), build: func(cand Candidate, m []string) ([]Candidate, bool) {
+		return declared(cand, m[1], semanticModuleScore), true
+	}},
+}
+
+func scalaDefinition(cand Candidate, m []string) ([]Candidate, bool) {
+	modifiers, kw, name := m[1], m[2], strings.Trim(m[3], "`")
+	var score int16
+	switch kw {
+	case "def":
+		score = semanticFunctionScore
+		if name == "this" || isConstructorName(name) {
+			score = semanticConstructorScore
+		}
+	case "val", "var":
+		score = semanticLocalScore
+	case "given":
+		score = semanticConstScore
+	default:
+		score = semanticTypeDeclScore
+	}
+	if strings.Contains(modifiers, "private") {
+		score += semanticVisibilityPrivate
+	}
+	return declared(cand, name, score), true
+}
+
+var haskellDeclForms = []declForm{
+	{re: regexp.MustCompile(`^(module|data|newtype|type|class|instance)\s+(.+)`), build: haskellDeclaration},
+	{re: regexp.MustCompile(`^\s*([\p{Ll}_][\p{L}\p{M}\p{N}_']*(?:\s*,\s*[\p{Ll}_][\p{L}\p{M}\p{N}_']*)*)\s*::`), build: haskellSignature},
+	{re: regexp.MustCompile(`^\s*\(([!#$%&*+./<=>?@\\^|~:-]+)\)\s*::`), build: haskellSignature},
+}
+
+var haskellName = regexp.MustCompile(`^(?:[\p{Lu}][\p{L}\p{M}\p{N}_'.]*|\([!#$%&*+./<=>?@\\^|~:-]+\))`)
+
+// haskellDeclaration names module, data, newtype, type and class declarations
+// after any family keyword and class context. Instances are keyed by their
+// class with the instance type as container.
+func haskellDeclaration(cand Candidate, m []string) ([]Candidate, bool) {
+	kw, head := m[1], m[2]
+	if end := strings.Index(head, " where"); end >= 0 {
+		head = head[:end]
+	}
+	if kw == "module" {
+		name := haskellName.FindString(head)
+		if name == "" {
+			return nil, true
+		}
+		return declared(cand, name, semanticModuleScore), true
+	}
+
+	head = strings.TrimPrefix(strings.TrimPrefix(head, "family "), "instance ")
+	if ctx := strings.Index(head, "=>"); ctx >= 0 {
+		if eq := strings.Index(head, "="); eq == ctx {
+			head = strings.TrimSpace(head[ctx+2:])
+		}
+	}
+	name := haskellName.FindString(head)
+	if name == "" {
+		return nil, true
+	}
+	if kw == "instance" {
+		cand.Container = strings.Trim(strings.TrimSpace(head[len(name):]), "()")
+		return declared(cand, name, semanticModuleScore), true
+	}
+	return declared(cand, name, semanticTypeDeclScore), true
+}
+
+func haskellSignature(cand Candidate, m []string) ([]Candidate, bool) {
+	names := strings.Split(m[1], ",")
+	out := make([]Candidate, 0, len(names))
+	for _, name := range names {
+		out = append(out, declared(cand, strings.TrimSpace(name), semanticFunctionScore)...)
+	}
+	return out, true
+}
+
+var ocamlDeclForms = []declForm{
+	{re: regexp.MustCompile(`^\s*let(?:\s+rec)?\s+([\p{Ll}_][\p{L}\p{M}\p{N}_']*)(.*)`), build: ocamlLet},
+	{re: regexp.MustCompile(`^\s*(?:val|external)\s+([\p{Ll}_][\p{L}\p{M}\p{N}_']*)\s*:(.*)`), build: ocamlValue},
+	{re: regexp.MustCompile(`^\s*type(?:\s+nonrec)?\s+(?:'[\p{Ll}_][\p{L}\p{M}\p{N}_']*\s+|\([^)]*\)\s+)?([\p{Ll}_][\p{L}\p{M}\p{N}_']*)`), build: ocamlType},
+	{re: regexp.MustCompile(`^\s*(module|exception)(\s+type|\s+rec)?\s+([\p{Lu}][\p{L}\p{M}\p{N}_']*)`), build: ocamlModule},
+	{re: regexp.MustCompile(`^\s*class(?:\s+type)?(?:\s+virtual)?\s+(?:\[[^\]]*\]\s+)?([\p{Ll}_][\p{L}\p{M}\p{N}_']*)`), build: ocamlType},
+}
+
+// ocamlLet treats bindings with parameters or a fun value as functions and
+// drops let ... in bindings, which are local.
+func ocamlLet(cand Candidate, m []string) ([]Candidate, bool) {
+	name, rest := m[1], strings.TrimSpace(m[2])
+	switch name {
+	case "_", "open", "module", "exception":
+		return nil, true
+	}
+	if rest == "in" || strings.HasSuffix(rest, " in") {
+		return nil, true
+	}
+
+	score := semanticFunctionScore
+	switch {
+	case strings.HasPrefix(rest, "="):
+		value, _ := leadingToken(strings.TrimSpace(rest[1:]))
+		if value != "fun" && value != "function" {
+			score = semanticConstScore
+		}
+	case strings.HasPrefix(rest, ":"):
+		score = semanticConstScore
+	}
+	return declared(cand, name, score), true
+}
+
+func ocamlValue(cand Candidate, m []string) ([]Candidate, bool) {
+	if strings.Contains(m[2], "->") {
+		return declared(cand, m[1], semanticFunctionScore), true
+	}
+	return declared(cand, m[1], semanticConstScore), true
+}
+
+func ocamlType(cand Candidate, m []string) ([]Candidate, bool) {
+	return declared(cand, m[1], semanticTypeDeclScore), true
+}
+
+func ocamlModule(cand Candidate, m []string) ([]Candidate, bool) {
+	if m[1] == "module" && strings.TrimSpace(m[2]) != "type" {
+		return declared(cand, m[3], semanticModuleScore), true
+	}
+	return declared(cand, m[3], semanticTypeDeclScore), true
+}
+
+var dartDeclForms = []declForm{
+	{re: regexp.MustCompile(`^\s*(?:(?:abstract|sealed|base|final|interface|mixin|augment)\s+)*(?:class|mixin|enum|extension|typedef)\s+(?:type\s+)?([\p{L}_$][\p{L}\p{M}\p{N}_$]*)`), build: dartType},
+	{re: regexp.MustCompile(`^\s*(?:const\s+)?factory\s+([\p{L}_$][\p{L}\p{M}\p{N}_$]*(?:\.[\p{L}_$][\p{L}\p{M}\p{N}_$]*)?)\s*\(`), build: func(cand Candidate, m []string) ([]Candidate, bool) {
+		return declared(cand, m[1], semanticConstructorScore), true
+	}},
+	{re: regexp.MustCompile(`^(?:const|final)\s+(?:[\p{L}_$][\p{L}\p{M}\p{N}_$<>?,\[\]]*\s+)?([\p{L}_$][\p{L}\p{M}\p{N}_$]*)\s*=`), build: dartConst},
+	{re: regexp.MustCompile(`^\s+static\s+(?:const|final)\s+(?:[\p{L}_$][\p{L}\p{M}\p{N}_$<>?,\[\]]*\s+)?([\p{L}_$][\p{L}\p{M}\p{N}_$]*)\s*=`), build: dartConst},
+	{re: regexp.MustCompile(`^\s*(?:(?:static|external|@override)\s+)*([\p{L}_$][\p{L}\p{M}\p{N}_$<>?,.\[\] ]*?)\s+(?:(?:get|set)\s+)?([\p{L}_$][\p{L}\p{M}\p{N}_$]*)\s*(?:<[^>]*>)?\s*(?:\(|=>|\{)`), build: dartFunction},
+}
+
+// dartStatementWords start statements that would otherwise read as a return
+// type followed by a function name.
+var dartStatementWords = map[string]bool{
+	"return": true, "await": true, "throw": true, "new": true, "yield": true,
+	"else": true, "case": true, "is": true, "as": true, "in": true, "if": true,
+	"for": true, "while": true, "switch": true, "assert": true, "do": true,
+	"try": true, "catch": true, "on": true, "import": true, "export": true,
+	"part": true, "library": true,
+}
+
+func dartScore(name string, score int16) int16 {
+	if strings.HasPrefix(name, "_") {
+		return score + semanticVisibilityPrivate
+	}
+	return score
+}
+
+func dartType(cand Candidate, m []string) ([]Candidate, bool) {
+	if m[1] == "on" {
+		return nil, true
+	}
+	return declared(cand, m[1], dartScore(m[1], semanticTypeDeclScore)), true
+}
+
+func dartConst(cand Candidate, m []string) ([]Candidate, bool) {
+	return declared(cand, m[1], dartScore(m[1], semanticConstScore)), true
+}
+
+func dartFunction(cand Candidate, m []string) ([]Candidate, bool) {
+	typ, name := m[1], m[2]
+	first, _, _ := strings.Cut(typ, " ")
+	if dartStatementWords[first] || dartStatementWords[name] {
+		return nil, true
+	}
+	if typ == "const" {
+		return declared(cand, name, semanticConstructorScore), true
+	}
+	return declared(cand, name, dartScore(name, semanticFunctionScore)), true
+}
+
+var objcDeclForms = []declForm{
+	{re: regexp.MustCompile(`^\s*@(?:interface|implementation|protocol)\s+([\p{L}_][\p{L}\p{M}\p{N}_]*)(.*)`), build: objcContainer},
+	{re: regexp.MustCompile(`^\s*[-+]\s*\([^)]*\)\s*([\p{L}_][\p{L}\p{M}\p{N}_]*)(.*)`), build: objcMethod},
+	{re: regexp.MustCompile(`^\s*@property\b.*?([\p{L}_][\p{L}\p{M}\p{N}_]*)\s*;`), build: func(cand Candidate, m []string) ([]Candidate, bool) {
+		return declared(cand, m[1], semanticFieldScore), true
+	}},
+	{re: regexp.MustCompile(`^\s*typedef\s+NS_(?:ENUM|OPTIONS|CLOSED_ENUM|ERROR_ENUM)\s*\(\s*[\p{L}_][\p{L}\p{M}\p{N}_]*\s*,\s*([\p{L}_][\p{L}\p{M}\p{N}_]*)\s*\)`), build: func(cand Candidate, m []string) ([]Candidate, bool) {
+		return declared(cand, m[1], semanticTypeDeclScore), true
+	}},
+}
+
+// objcContainer names @interface, @implementation and @protocol blocks and
+// drops forward @protocol declarations.
+func objcContainer(cand Candidate, m []string) ([]Candidate, bool) {
+	rest := strings.TrimSpace(m[2])
+	if strings.HasPrefix(rest, ";") || strings.HasPrefix(rest, ",") {
+		return nil, true
+	}
+	return declared(cand, m[1], semanticTypeDeclScore), true
+}
+
+var objcSelectorLabel = regexp.MustCompile(`(?:^|\s)([\p{L}_][\p{L}\p{M}\p{N}_]*)\s*:\s*\(`)
+
+// objcMethod keys a method by its full selector, such as
+// setValue:forKey:.
+func objcMethod(cand Candidate, m []string) ([]Candidate, bool) {
+	selector, rest := m[1], strings.TrimSpace(m[2])
+	if strings.HasPrefix(rest, ":") {
+		selector += ":"
+		if end := strings.IndexAny(rest, "{;"); end >= 0 {
+			rest = rest[:end]
+		}
+		for _, label := range objcSelectorLabel.FindAllStringSubmatch(rest[1:], -1) {
+			selector += label[1] + ":"
+		}
+	}
+	return declared(cand, selector, semanticMethodScore), true
+}
@@ -0,0 +1,99 @@
+package candidate
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestExpandLanguageDeclarations(t *testing.T) {
+	tests := []struct {
+		file  string
+		text  string
+		keys  []string
+		score int16
+	}{
+		{file: "init.lua", text: "local function setup(opts)", keys: []string{"setup"}, score: semanticFunctionScore + semanticVisibilityPrivate},
+		{file: "init.lua", text: "function M.setup(opts)", keys: []string{"M.setup"}, score: semanticFunctionScore},
+		{file: "init.lua", text: "function Buffer:close()", keys: []string{"Buffer.close"}, score: semanticMethodScore},
+		{file: "init.lua", text: "M.on_attach = function(client, bufnr)", keys: []string{"M.on_attach"}, score: semanticFunctionScore},
+		{file: "lib/search.ex", text: "defmodule Search.Index do", keys: []string{"Search.Index"}, score: semanticModuleScore},
+		{file: "lib/search.ex", text: "  def valid?(query), do: query != \"\"", keys: []string{"valid?"}, score: semanticFunctionScore},
+		{file: "lib/search.ex", text: "  defp build(terms) do", keys: []string{"build"}, score: semanticFunctionScore + semanticVisibilityPrivate},
+		{file: "Jobs.scala", text: "sealed trait Shape", keys: []string{"Shape"}, score: semanticTypeDeclScore},
+		{file: "Jobs.scala", text: "final case class Circle(r: Double) extends Shape", keys: []string{"Circle"}, score: semanticTypeDeclScore},
+		{file: "Jobs.scala", text: "  private[jobs] def area(s: Shape): Double =", keys: []string{"area"}, score: semanticFunctionScore + semanticVisibilityPrivate},
+		{file: "Jobs.scala", text: "package com.example.jobs", keys: []string{"com.example.jobs"}, score: semanticModuleScore},
+		{file: "Search.hs", text: "module Search.Index (search) where", keys: []string{"Search.Index"}, score: semanticModuleScore},
+		{file: "Search.hs", text: "data Query a = Query { terms :: [a] }", keys: []string{"Query"}, score: semanticTypeDeclScore},
+		{file: "Search.hs", text: "class (Eq a) => Indexed a where", keys: []string{"Indexed"}, score: semanticTypeDeclScore},
+		{file: "Search.hs", text: "instance Show a => Show (Query a) where", keys: []string{"Query a.Show"}, score: semanticModuleScore},
+		{file: "Search.hs", text: "search, lookup :: Index -> Query -> [Hit]", keys: []string{"search", "lookup"}, score: semanticFunctionScore},
+		{file: "Search.hs", text: "(<+>) :: Query a -> Query a -> Query a", keys: []string{"<+>"}, score: semanticFunctionScore},
+		{file: "search.ml", text: "let rec search index q = ", keys: []string{"search"}, score: semanticFunctionScore},
+		{file: "search.ml", text: "let default_limit = 20", keys: []string{"default_limit"}, score: semanticConstScore},
+		{file: "search.ml", text: "  let hits = run q in", keys: nil},
+		{file: "search.mli", text: "val search : t -> string -> hit list", keys: []string{"search"}, score: semanticFunctionScore},
+		{file: "search.ml", text: "type 'a result = Ok of 'a | Error of string", keys: []string{"result"}, score: semanticTypeDeclScore},
+		{file: "search.ml", text: "module type INDEX = sig", keys: []string{"INDEX"}, score: semanticTypeDeclScore},
+		{file: "search.ml", text: "module Index = struct", keys: []string{"Index"}, score: semanticModuleScore},
+		{file: "app.dart", text: "abstract class Repository<T> {", keys: []string{"Repository"}, score: semanticTypeDeclScore},
+		{file: "app.dart", text: "  Future<List<User>> fetchUsers(int page) async {", keys: []string{"fetchUsers"}, score: semanticFunctionScore},
+		{file: "app.dart", text: "  String get _label => name;", keys: []string{"_label"}, score: semanticFunctionScore + semanticVisibilityPrivate},
+		{file: "app.dart", text: "  const UserCard({super.key, required this.user});", keys: []string{"UserCard"}, score: semanticConstructorScore},
+		{file: "app.dart", text: "  factory User.fromJson(Map<String, dynamic> json) {", keys: []string{"User.fromJson"}, score: semanticConstructorScore},
+		{file: "app.dart", text: "const defaultPageSize = 20;", keys: []string{"defaultPageSize"}, score: semanticConstScore},
+		{file: "app.dart", text: "    return UserCard(user: user);", keys: nil},
+		{file: "Store.m", text: "@implementation Store", keys: []string{"Store"}, score: semanticTypeDeclScore},
+		{file: "Store.m", text: "- (void)setValue:(id)value forKey:(NSString *)key {", keys: []string{"setValue:forKey:"}, score: semanticMethodScore},
+		{file: "Store.m", text: "+ (instancetype)sharedStore;", keys: []string{"sharedStore"}, score: semanticMethodScore},
+		{file: "Store.m", text: "@property (nonatomic, copy) NSString *name;", keys: []string{"name"}, score: semanticFieldScore},
+		{file: "Store.m", text: "typedef NS_ENUM(NSInteger, StoreState) {", keys: []string{"StoreState"}, score: semanticTypeDeclScore},
+		{file: "init.lua", text: "function 設定.読み込み(path)", keys: []string{"設定.読み込み"}, score: semanticFunctionScore},
+		{file: "lib/search.ex", text: "  def größe(list), do: length(list)", keys: []string{"größe"}, score: semanticFunctionScore},
+		{file: "Jobs.scala", text: "def 合計(xs: List[Int]): Int =", keys: []string{"合計"}, score: semanticFunctionScore},
+		{file: "Search.hs", text: "données :: Index -> [Hit]", keys: []string{"données"}, score: semanticFunctionScore},
+		{file: "search.ml", text: "module Índice = struct", keys: []string{"Índice"}, score: semanticModuleScore},
+		{file: "app.dart", text: "class Überblick {", keys: []string{"Überblick"}, score: semanticTypeDeclScore},
+		{file: "Store.m", text: "@property (nonatomic) NSString *名前;", keys: []string{"名前"}, score: semanticFieldScore},
+		{file: "Store.m", text: "@protocol StoreDelegate;", keys: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			src := testSourceFile(tt.file, tt.text)
+			cand := expandTestMatch(t, src, 1, ProducerConfig{})
+			got := candidateKeys(cand)
+			if len(got) == 0 {
+				got = nil
+			}
+			if !reflect.DeepEqual(got, tt.keys) {
+				t.Fatalf("keys = %#v, want %#v", got, tt.keys)
+			}
+			if len(cand) > 0 && cand[0].SemanticScore != tt.score {
+				t.Fatalf("score = %d, want %d", cand[0].SemanticScore, tt.score)
+			}
+		})
+	}
+}
+
+func TestLanguageDeclarationPatternsSelectTheirForms(t *testing.T) {
+	lines := map[LangID]string{
+		LangLua:     "local function setup(opts)",
+		LangElixir:  "  defmacro schema(name, do: block) do",
+		LangScala:   "object Main extends App {",
+		LangHaskell: "newtype Score = Score Int",
+		LangOCaml:   "exception Not_indexed of string",
+		LangDart:    "mixin Loggable on Widget {",
+		LangObjC:    "@interface Store : NSObject",
+	}
+	for _, l := range declarationLanguages {
+		line, ok := lines[l.lang]
+		if !ok {
+			t.Fatalf("no sample line for %s", l.name)
+		}
+		if !regexp.MustCompile(l.pattern()).MatchString(line) {
+			t.Fatalf("%s pattern does not match %q", l.name, line)
+		}
+	}
+}
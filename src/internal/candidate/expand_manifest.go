@@ -0,0 +1,201 @@
+package candidate
+
+import (
+	"path"
+	"strings"
+)
+
+// manifestDoc is a Kubernetes resource found in one document of a YAML file.
+type manifestDoc struct {
+	First     int
+	Last      int
+	Kind      string
+	Name      string
+	Namespace string
+	NameLine  int
+}
+
+var gitLabReservedKeys = map[string]bool{
+	"default": true, "include": true, "stages": true, "variables": true,
+	"workflow": true, "image": true, "services": true, "cache": true,
+	"before_script": true, "after_script": true, "spec": true,
+}
+
+// expandManifestEntry recognizes Kubernetes resources and CI workflow jobs and
+// steps. Other `name:` lines of a recognized document are dropped rather than
+// listed as config keys. It reports false for entries that should get regular
+// config handling.
+func expandManifestEntry(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	switch ciWorkflowKind(cand.File) {
+	case ciGitHub:
+		return githubWorkflowEntry(cand, src)
+	case ciGitLab:
+		return gitLabJobEntry(cand, src)
+	}
+
+	for _, doc := range src.manifestDocs() {
+		if cand.Line < doc.First || cand.Line > doc.Last {
+			continue
+		}
+		if cand.Line != doc.NameLine {
+			return nil, isNameEntry(cand.Text)
+		}
+		cand.Key = doc.Kind + "/" + doc.Name
+		cand.Kind = KindResource
+		cand.Container = doc.Namespace
+		cand.SemanticScore = semanticTypeDeclScore
+		return []Candidate{cand}, true
+	}
+	return nil, false
+}
+
+type ciWorkflow uint8
+
+const (
+	ciNone ciWorkflow = iota
+	ciGitHub
+	ciGitLab
+)
+
+func ciWorkflowKind(file string) ciWorkflow {
+	file = strings.ReplaceAll(file, "\\", "/")
+	base := path.Base(file)
+	switch {
+	case base == ".gitlab-ci.yml" || base == ".gitlab-ci.yaml":
+		return ciGitLab
+	case strings.HasPrefix(file, ".github/workflows/") || strings.Contains(file, "/.github/workflows/"):
+		return ciGitHub
+	default:
+		return ciNone
+	}
+}
+
+func githubWorkflowEntry(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	outline := src.configOutline(cand.LangID)
+	if cand.Line < 1 || cand.Line > len(outline) {
+		return nil, false
+	}
+	container := outline[cand.Line-1]
+	key, value, ok := configEntry(yamlItemText(cand.Text))
+	if !ok {
+		return nil, false
+	}
+
+	switch {
+	case container == "jobs":
+		cand.Key = key
+		cand.Kind = KindJob
+		cand.Container = ""
+		cand.SemanticScore = semanticFunctionScore
+	case key == "id" && value != "" && strings.HasPrefix(container, "jobs.") && strings.HasSuffix(container, ".steps"):
+		cand.Key = value
+		cand.Kind = KindStep
+		cand.Container = strings.TrimSuffix(strings.TrimPrefix(container, "jobs."), ".steps")
+		cand.SemanticScore = semanticMethodScore
+	case key == "name":
+		return nil, true
+	default:
+		return nil, false
+	}
+	return []Candidate{cand}, true
+}
+
+// yamlItemText trims a YAML line and the dash of a sequence item.
+func yamlItemText(text string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "-"))
+}
+
+// isNameEntry reports whether a YAML line sets a `name:` key.
+func isNameEntry(text string) bool {
+	key, _, ok := configEntry(yamlItemText(text))
+	return ok && key == "name"
+}
+
+func gitLabJobEntry(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	outline := src.configOutline(cand.LangID)
+	if cand.Line < 1 || cand.Line > len(outline) || outline[cand.Line-1] != "" {
+		return nil, false
+	}
+	if _, indent := trimIndent(src.Lines()[cand.Line-1]); indent != 0 {
+		return nil, false
+	}
+	key, _, ok := configEntry(strings.TrimSpace(cand.Text))
+	if !ok || gitLabReservedKeys[key] || strings.HasPrefix(key, ".") {
+		return nil, false
+	}
+	cand.Key = key
+	cand.Kind = KindJob
+	cand.SemanticScore = semanticFunctionScore
+	return []Candidate{cand}, true
+}
+
+func (s *sourceFile) manifestDocs() []manifestDoc {
+	if s.manifests == nil {
+		s.manifests = buildManifestDocs(s.Lines())
+	}
+	return s.manifests
+}
+
+// buildManifestDocs splits a YAML stream on "---" and keeps the documents that
+// look like Kubernetes resources.
+func buildManifestDocs(lines []string) []manifestDoc {
+	out := make([]manifestDoc, 0, 1)
+	doc := manifestDoc{First: 1}
+	apiVersion := false
+	inMetadata := false
+	metadataIndent := -1
+
+	finish := func(last int) {
+		doc.Last = last
+		if apiVersion && doc.Kind != "" && doc.Name != "" {
+			out = append(out, doc)
+		}
+	}
+
+	for i, raw := range lines {
+		trimmed, indent := trimIndent(raw)
+		if trimmed == "---" || strings.HasPrefix(trimmed, "--- ") {
+			finish(i)
+			doc = manifestDoc{First: i + 2}
+			apiVersion, inMetadata, metadataIndent = false, false, -1
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, ok := configEntry(trimmed)
+		if !ok {
+			continue
+		}
+
+		if indent == 0 {
+			inMetadata = key == "metadata"
+			metadataIndent = -1
+			switch key {
+			case "apiVersion":
+				apiVersion = true
+			case "kind":
+				doc.Kind = value
+			}
+			continue
+		}
+		if !inMetadata {
+			continue
+		}
+		if metadataIndent < 0 {
+			metadataIndent = indent
+		}
+		if indent != metadataIndent {
+			continue
+		}
+		switch key {
+		case "name":
+			doc.Name = value
+			doc.NameLine = i + 1
+		case "namespace":
+			doc.Namespace = value
+		}
+	}
+	finish(len(lines))
+	return out
+}
@@ -0,0 +1,90 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandKubernetesResourcesPerDocument(t *testing.T) {
+	src := testSourceFile("deploy/api.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api # primary
+  namespace: prod
+spec:
+  template:
+    metadata:
+      name: ignored
+    spec:
+      containers:
+        - name: server
+          image: api:1.0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app: api
+  name: api`)
+
+	var got []kindKey
+	for _, line := range []int{4, 9, 12, 13, 20} {
+		got = append(got, kindKeys(expandTestMatch(t, src, line, ProducerConfig{}))...)
+	}
+	want := []kindKey{
+		{Kind: KindResource, Key: "prod.Deployment/api"},
+		{Kind: KindCode, Key: "spec.template.spec.containers.image"},
+		{Kind: KindResource, Key: "Service/api"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandGitHubWorkflowJobsAndSteps(t *testing.T) {
+	src := testSourceFile(".github/workflows/ci.yml", `name: ci
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Checkout
+        id: checkout
+      - id: build
+        run: make`)
+
+	var got []kindKey
+	for _, line := range []int{1, 4, 7, 8, 9} {
+		got = append(got, kindKeys(expandTestMatch(t, src, line, ProducerConfig{}))...)
+	}
+	want := []kindKey{
+		{Kind: KindJob, Key: "test"},
+		{Kind: KindStep, Key: "test.checkout"},
+		{Kind: KindStep, Key: "test.build"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandGitLabJobs(t *testing.T) {
+	src := testSourceFile(".gitlab-ci.yml", `stages:
+  - test
+.template:
+  image: golang
+unit-tests:
+  stage: test`)
+
+	var got []kindKey
+	for _, line := range []int{1, 3, 5} {
+		got = append(got, kindKeys(expandTestMatch(t, src, line, ProducerConfig{}))...)
+	}
+	want := []kindKey{
+		{Kind: KindCode, Key: "stages"},
+		{Kind: KindCode, Key: ".template"},
+		{Kind: KindJob, Key: "unit-tests"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
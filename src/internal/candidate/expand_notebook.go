@@ -0,0 +1,72 @@
+package candidate
+
+import (
+	"regexp"
+	"strings"
+
+	"snav/internal/notebook"
+)
+
+// notebookDeclaration finds the lines of a code cell that the declaration and
+// python assignment passes would match in a .py file.
+var notebookDeclaration = regexp.MustCompile(`(?:` + DefaultRGPattern + `)|(?:` + pythonAssignmentPattern + `)`)
+
+// expandNotebook indexes the Python declarations in the code cells of a
+// Jupyter notebook when the notebook is first matched. Candidates are located
+// by cell and by line within the cell source.
+func expandNotebook(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	if src.notebook {
+		return nil, true
+	}
+	src.notebook = true
+
+	lines := src.Lines()
+	if lines == nil {
+		return nil, true
+	}
+	nb, err := notebook.Parse([]byte(strings.Join(lines, "\n")))
+	if err != nil || nb.Lang != LangPython {
+		return nil, true
+	}
+
+	var out []Candidate
+	for _, cell := range nb.Cells {
+		if cell.Kind != "code" {
+			continue
+		}
+		out = append(out, notebookCellDeclarations(cand.File, cell)...)
+	}
+	return out, true
+}
+
+func notebookCellDeclarations(file string, cell notebook.Cell) []Candidate {
+	cellSrc := &sourceFile{path: file, lines: cell.Source, loaded: true}
+	var out []Candidate
+	for i, line := range cell.Source {
+		if !notebookDeclaration.MatchString(line) {
+			continue
+		}
+		text := strings.TrimSpace(line)
+		cand := Candidate{
+			File:          file,
+			Line:          i + 1,
+			Col:           1,
+			Text:          text,
+			Key:           ExtractKey(text, file),
+			LangID:        LangPython,
+			SemanticScore: computeSemanticScore(text),
+		}
+		expanded, ok := expandPythonDeclaration(cand, cellSrc)
+		if !ok {
+			expanded = []Candidate{cand}
+		}
+		for _, next := range expanded {
+			next.Cell = cell.Index
+			next.Col = keyColumn(cell.Source[next.Line-1], next.Key, next.Col)
+			next.Doc = docComment(cell.Source, next.Line, LangPython)
+			next.Signature = signature(cell.Source, next.Line, next.Key, LangPython)
+			out = append(out, next)
+		}
+	}
+	return out
+}
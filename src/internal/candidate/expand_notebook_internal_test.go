@@ -0,0 +1,60 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandNotebookCodeCells(t *testing.T) {
+	src := testSourceFile("analysis.ipynb", `{
+ "cells": [
+  {"cell_type": "markdown", "source": ["def not_code(): pass"]},
+  {
+   "cell_type": "code",
+   "source": [
+    "THRESHOLD = 0.5\n",
+    "def load(path):\n",
+    "    return path"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "source": [
+    "class Model:\n",
+    "    def fit(self, df):\n",
+    "        return self"
+   ]
+  }
+ ],
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`)
+
+	out := expandTestMatch(t, src, 21, ProducerConfig{})
+	type cellKey struct {
+		Cell      int
+		Line      int
+		Key       string
+		Container string
+	}
+	var got []cellKey
+	for _, cand := range out {
+		if cand.LangID != LangPython {
+			t.Fatalf("%s: LangID = %q, want python", cand.Key, cand.LangID)
+		}
+		got = append(got, cellKey{Cell: cand.Cell, Line: cand.Line, Key: cand.Key, Container: cand.Container})
+	}
+	want := []cellKey{
+		{Cell: 2, Line: 1, Key: "THRESHOLD"},
+		{Cell: 2, Line: 2, Key: "load"},
+		{Cell: 3, Line: 1, Key: "Model"},
+		{Cell: 3, Line: 2, Key: "fit", Container: "Model"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v\nwant %#v", got, want)
+	}
+
+	if again := expandTestMatch(t, src, 21, ProducerConfig{}); len(again) != 0 {
+		t.Fatalf("second match expanded again: %#v", again)
+	}
+}
@@ -0,0 +1,122 @@
+package candidate
+
+import "strings"
+
+const openAPIDetectLines = 40
+
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// expandOpenAPIEntry turns operationIds and path keys of an OpenAPI or Swagger
+// document into operation and path candidates. Other entries are left to the
+// regular config key handling.
+func expandOpenAPIEntry(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	trimmed := strings.TrimSpace(cand.Text)
+	key, value, ok := configEntry(trimmed)
+	if !ok || (key != "operationId" && !strings.HasPrefix(key, "/")) {
+		return nil, false
+	}
+	if !src.isOpenAPI() {
+		// Unquoted path keys only come from the OpenAPI pass.
+		if strings.HasPrefix(trimmed, "/") {
+			return nil, true
+		}
+		return nil, false
+	}
+
+	switch {
+	case key == "operationId" && value != "":
+		cand.Key = value
+		cand.Kind = KindOperation
+		cand.SemanticScore = semanticFunctionScore
+		cand.Container = openAPIOperationContainer(src.Lines(), cand.Line)
+	case strings.HasPrefix(key, "/"):
+		cand.Key = key
+		cand.Kind = KindPath
+		cand.SemanticScore = semanticModuleScore
+	default:
+		return nil, false
+	}
+	return []Candidate{cand}, true
+}
+
+func (s *sourceFile) isOpenAPI() bool {
+	if s.openAPI == 0 {
+		s.openAPI = -1
+		lines := s.Lines()
+		for i := 0; i < len(lines) && i < openAPIDetectLines; i++ {
+			key, _, ok := configEntry(strings.TrimSpace(lines[i]))
+			if ok && (key == "openapi" || key == "swagger") {
+				s.openAPI = 1
+				break
+			}
+		}
+	}
+	return s.openAPI > 0
+}
+
+// configEntry splits a YAML or JSON line into its key and scalar value.
+func configEntry(trimmed string) (string, string, bool) {
+	var key, rest string
+	if trimmed != "" && (trimmed[0] == '"' || trimmed[0] == '\'') {
+		end := skipQuoted(trimmed, 0, trimmed[0])
+		if end >= len(trimmed) {
+			return "", "", false
+		}
+		key, rest = trimmed[1:end], trimmed[end+1:]
+	} else {
+		colon := strings.IndexByte(trimmed, ':')
+		if colon <= 0 {
+			return "", "", false
+		}
+		key, rest = strings.TrimRight(trimmed[:colon], " \t"), trimmed[colon:]
+	}
+
+	rest = strings.TrimLeft(rest, " \t")
+	if !strings.HasPrefix(rest, ":") {
+		return "", "", false
+	}
+	value := strings.TrimSpace(rest[1:])
+	if comment := strings.Index(value, " #"); comment >= 0 {
+		value = strings.TrimSpace(value[:comment])
+	}
+	value = strings.TrimSuffix(value, ",")
+	value = strings.Trim(value, `"'`)
+	return key, value, true
+}
+
+// openAPIOperationContainer describes the operation enclosing line as
+// "METHOD /path", walking up through less indented keys.
+func openAPIOperationContainer(lines []string, line int) string {
+	method, path := "", ""
+	_, indent := trimIndent(lines[line-1])
+	for lineNo := line - 1; lineNo >= 1 && indent > 0; lineNo-- {
+		trimmed, lineIndent := trimIndent(lines[lineNo-1])
+		if trimmed == "" || lineIndent >= indent {
+			continue
+		}
+		indent = lineIndent
+		key, _, ok := configEntry(trimmed)
+		if !ok {
+			continue
+		}
+		switch {
+		case method == "" && openAPIMethods[strings.ToLower(key)]:
+			method = strings.ToUpper(key)
+		case strings.HasPrefix(key, "/"):
+			path = key
+		}
+		if path != "" {
+			break
+		}
+	}
+
+	switch {
+	case method != "" && path != "":
+		return method + " " + path
+	default:
+		return path
+	}
+}
@@ -0,0 +1,246 @@
+package candidate
+
+import "strings"
+
+type pythonLine struct {
+	Start      bool
+	Container  string
+	InClass    bool
+	Decorators string
+}
+
+func expandPythonDeclaration(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	lines := src.Lines()
+	outline := src.pythonOutline()
+	if cand.Line < 1 || cand.Line > len(outline) {
+		return nil, false
+	}
+
+	info := outline[cand.Line-1]
+	trimmed, indent := trimIndent(lines[cand.Line-1])
+	name, isClass, ok := pythonDefinition(trimmed)
+	if ok && !info.Start {
+		return nil, true
+	}
+	if !ok {
+		if indent != 0 || !info.Start {
+			return nil, false
+		}
+		return pythonModuleAssignment(cand, trimmed)
+	}
+
+	cand.Key = name
+	cand.Container = info.Container
+	cand.Annotations = info.Decorators
+	if info.InClass && !isClass && cand.SemanticScore == semanticFunctionScore {
+		cand.SemanticScore = semanticMethodScore
+	}
+
+	out := []Candidate{cand}
+	if isClass && hasDataclassDecorator(info.Decorators) {
+		out = append(out, pythonDataclassFields(cand, lines, outline, indent)...)
+	}
+	return out, true
+}
+
+func pythonModuleAssignment(cand Candidate, trimmed string) ([]Candidate, bool) {
+	name, rest := leadingIdentifier(trimmed)
+	if name == "" {
+		return nil, false
+	}
+
+	cand.Key = name
+	cand.SemanticScore = semanticConstScore
+	if annotation, ok := strings.CutPrefix(strings.TrimLeft(rest, " \t"), ":"); ok {
+		annotation = strings.TrimSpace(annotation)
+		annotation = strings.TrimPrefix(strings.TrimPrefix(annotation, "typing."), "t.")
+		if strings.HasPrefix(annotation, "TypeAlias") {
+			cand.SemanticScore = semanticTypeDeclScore
+		}
+	}
+	return []Candidate{cand}, true
+}
+
+func pythonDataclassFields(class Candidate, lines []string, outline []pythonLine, classIndent int) []Candidate {
+	out := make([]Candidate, 0, 8)
+	bodyIndent := -1
+	for lineNo := class.Line + 1; lineNo <= len(lines); lineNo++ {
+		if !outline[lineNo-1].Start {
+			continue
+		}
+		trimmed, indent := trimIndent(lines[lineNo-1])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indent <= classIndent {
+			break
+		}
+		if bodyIndent < 0 {
+			bodyIndent = indent
+		}
+		if indent != bodyIndent {
+			continue
+		}
+
+		name, rest := leadingIdentifier(trimmed)
+		annotation, ok := strings.CutPrefix(strings.TrimLeft(rest, " \t"), ":")
+		if name == "" || !ok || strings.HasPrefix(strings.TrimSpace(annotation), "ClassVar") {
+			continue
+		}
+		out = append(out, Candidate{
+			File:          class.File,
+			Line:          lineNo,
+			Col:           indent + 1,
+			Text:          trimmed,
+			Key:           name,
+			LangID:        class.LangID,
+			SemanticScore: semanticFieldScore,
+			Container:     qualifiedContainer(class.Container, class.Key),
+		})
+	}
+	return out
+}
+
+func hasDataclassDecorator(decorators string) bool {
+	for _, decorator := range strings.Fields(decorators) {
+		switch decorator {
+		case "@dataclass", "@dataclasses.dataclass", "@attr.s", "@attr.attrs", "@attrs.define", "@attrs.frozen", "@define", "@frozen":
+			return true
+		}
+	}
+	return false
+}
+
+func pythonDefinition(trimmed string) (string, bool, bool) {
+	keyword, rest := leadingIdentifier(trimmed)
+	if keyword == "async" {
+		keyword, rest = leadingIdentifier(strings.TrimLeft(rest, " \t"))
+	}
+	if keyword != "def" && keyword != "class" {
+		return "", false, false
+	}
+	if rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+		return "", false, false
+	}
+
+	name, _ := leadingIdentifier(strings.TrimLeft(rest, " \t"))
+	if name == "" {
+		return "", false, false
+	}
+	return name, keyword == "class", true
+}
+
+func (s *sourceFile) pythonOutline() []pythonLine {
+	if s.python == nil {
+		s.python = buildPythonOutline(s.Lines())
+	}
+	return s.python
+}
+
+// buildPythonOutline tracks indentation to find the class and def chain that
+// encloses each line. Lines continuing a bracketed expression or a
+// triple-quoted string do not start a logical line and never close a scope.
+func buildPythonOutline(lines []string) []pythonLine {
+	type frame struct {
+		indent int
+		name   string
+		class  bool
+	}
+
+	out := make([]pythonLine, len(lines))
+	var stack []frame
+	current := pythonLine{}
+	depth := 0
+	inString := ""
+	var decorators []string
+
+	rebuild := func() {
+		names := make([]string, len(stack))
+		for i, f := range stack {
+			names[i] = f.name
+		}
+		current.Container = strings.Join(names, ".")
+		current.InClass = len(stack) > 0 && stack[len(stack)-1].class
+	}
+
+	for i, raw := range lines {
+		trimmed, indent := trimIndent(raw)
+		start := depth == 0 && inString == ""
+		code := start && trimmed != "" && !strings.HasPrefix(trimmed, "#")
+
+		if code {
+			popped := false
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+				popped = true
+			}
+			if popped {
+				rebuild()
+			}
+		}
+
+		out[i] = pythonLine{Start: start, Container: current.Container, InClass: current.InClass}
+		if code {
+			switch name, class, ok := pythonDefinition(trimmed); {
+			case ok:
+				out[i].Decorators = strings.Join(decorators, " ")
+				decorators = decorators[:0]
+				stack = append(stack, frame{indent: indent, name: name, class: class})
+				rebuild()
+			case strings.HasPrefix(trimmed, "@"):
+				decorators = append(decorators, pythonDecoratorName(trimmed))
+			default:
+				decorators = decorators[:0]
+			}
+		}
+
+		depth, inString = scanPythonLine(trimmed, depth, inString)
+		if depth < 0 {
+			depth = 0
+		}
+	}
+
+	return out
+}
+
+func pythonDecoratorName(line string) string {
+	return line[:identifierEnd(line, 1, ".")]
+}
+
+func scanPythonLine(line string, depth int, inString string) (int, string) {
+	for i := 0; i < len(line); i++ {
+		if inString != "" {
+			if strings.HasPrefix(line[i:], inString) {
+				i += len(inString) - 1
+				inString = ""
+			} else if line[i] == '\\' {
+				i++
+			}
+			continue
+		}
+
+		switch b := line[i]; b {
+		case '#':
+			return depth, inString
+		case '"', '\'':
+			if quote := line[i : i+1]; strings.HasPrefix(line[i:], quote+quote+quote) {
+				inString = quote + quote + quote
+				i += 2
+				continue
+			}
+			i = skipQuoted(line, i, b)
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+	}
+	return depth, inString
+}
+
+func qualifiedContainer(container string, name string) string {
+	if container == "" {
+		return name
+	}
+	return container + "." + name
+}
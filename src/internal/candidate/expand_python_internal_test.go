@@ -0,0 +1,130 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+)
+
+const pythonExpandSource = `import typing
+
+MAX_RETRIES = 3
+DEFAULT_TIMEOUT: float = 2.5
+UserId: typing.TypeAlias = int
+
+@dataclass(frozen=True)
+class Config:
+    name: str
+    retries: int = MAX_RETRIES
+    cache: ClassVar[dict] = {}
+
+    @property
+    def label(self) -> str:
+        """Return the label.
+
+        def not_a_function():
+        """
+        return self.name
+
+    def load(
+        self,
+        path: str,
+    ) -> "Config":
+        return self
+
+    class Nested:
+        async def run(self):
+            pass
+
+def helper():
+    pass
+`
+
+func TestExpandPythonModuleAssignments(t *testing.T) {
+	src := testSourceFile("settings.py", pythonExpandSource)
+
+	tests := []struct {
+		line  int
+		key   string
+		score int16
+	}{
+		{line: 3, key: "MAX_RETRIES", score: semanticConstScore},
+		{line: 4, key: "DEFAULT_TIMEOUT", score: semanticConstScore},
+		{line: 5, key: "UserId", score: semanticTypeDeclScore},
+	}
+	for _, tt := range tests {
+		got := expandTestMatch(t, src, tt.line, ProducerConfig{})
+		if len(got) != 1 || got[0].Key != tt.key || got[0].SemanticScore != tt.score {
+			t.Fatalf("line %d = %+v, want key %q score %d", tt.line, got, tt.key, tt.score)
+		}
+	}
+}
+
+func TestExpandPythonDataclassFieldsAndDecorators(t *testing.T) {
+	src := testSourceFile("settings.py", pythonExpandSource)
+
+	got := expandTestMatch(t, src, 8, ProducerConfig{})
+	if keys := candidateKeys(got); !reflect.DeepEqual(keys, []string{"Config", "Config.name", "Config.retries"}) {
+		t.Fatalf("keys = %#v", keys)
+	}
+	if got[0].Annotations != "@dataclass" {
+		t.Fatalf("annotations = %q, want @dataclass", got[0].Annotations)
+	}
+	if got[1].SemanticScore != semanticFieldScore || got[1].Line != 9 || got[1].Col != 5 {
+		t.Fatalf("field = %+v", got[1])
+	}
+}
+
+func TestExpandPythonUnicodeDecorators(t *testing.T) {
+	src := testSourceFile("注文.py", "@キャッシュ.保存\ndef 合計():\n    return 0\n")
+
+	got := expandTestMatch(t, src, 2, ProducerConfig{})
+	if len(got) != 1 || got[0].Annotations != "@キャッシュ.保存" {
+		t.Fatalf("got %+v, want @キャッシュ.保存", got)
+	}
+}
+
+func TestExpandPythonAttributesMethodsToClasses(t *testing.T) {
+	src := testSourceFile("settings.py", pythonExpandSource)
+
+	tests := []struct {
+		line        int
+		key         string
+		annotations string
+		score       int16
+	}{
+		{line: 14, key: "Config.label", annotations: "@property", score: semanticMethodScore},
+		{line: 21, key: "Config.load", score: semanticMethodScore},
+		{line: 27, key: "Config.Nested", score: semanticTypeDeclScore},
+		{line: 28, key: "Config.Nested.run", score: semanticMethodScore},
+		{line: 31, key: "helper", score: semanticFunctionScore},
+	}
+	for _, tt := range tests {
+		got := expandTestMatch(t, src, tt.line, ProducerConfig{})
+		if len(got) != 1 {
+			t.Fatalf("line %d = %+v, want one candidate", tt.line, got)
+		}
+		if key := candidateKeys(got)[0]; key != tt.key {
+			t.Fatalf("line %d key = %q, want %q", tt.line, key, tt.key)
+		}
+		if got[0].Annotations != tt.annotations || got[0].SemanticScore != tt.score {
+			t.Fatalf("line %d = %+v, want annotations %q score %d", tt.line, got[0], tt.annotations, tt.score)
+		}
+	}
+}
+
+func TestExpandPythonDropsDefinitionsInsideStrings(t *testing.T) {
+	src := testSourceFile("settings.py", pythonExpandSource)
+
+	if got := expandTestMatch(t, src, 17, ProducerConfig{}); len(got) != 0 {
+		t.Fatalf("docstring match = %+v, want none", got)
+	}
+}
+
+func TestExpandPythonUnicodeIdentifiers(t *testing.T) {
+	src := testSourceFile("注文.py", "class 注文:\n    def 合計(self):\n        return 0\n")
+
+	got := expandTestMatch(t, src, 2, ProducerConfig{})
+	if len(got) != 1 || got[0].Key != "合計" || got[0].Container != "注文" || got[0].SemanticScore != semanticMethodScore {
+		t.Fatalf("got %+v, want method 合計 in 注文", got)
+	}
+}
@@ -0,0 +1,291 @@
+package candidate
+
+import "strings"
+
+var protoKinds = map[string]Kind{
+	"message": KindMessage,
+	"enum":    KindEnum,
+	"service": KindService,
+	"rpc":     KindRPC,
+}
+
+func expandProtoDeclaration(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	trimmed := strings.TrimSpace(cand.Text)
+	keyword, rest := leadingIdentifier(trimmed)
+	kind, ok := protoKinds[keyword]
+	if !ok {
+		return nil, false
+	}
+	name, _ := leadingIdentifier(strings.TrimLeft(rest, " \t"))
+	if name == "" {
+		return nil, false
+	}
+
+	cand.Key = name
+	cand.Kind = kind
+	cand.SemanticScore = semanticTypeDeclScore
+	if kind == KindRPC {
+		cand.SemanticScore = semanticMethodScore
+	}
+	if outline := src.protoOutline(); cand.Line >= 1 && cand.Line <= len(outline) {
+		cand.Container = outline[cand.Line-1]
+	}
+	return []Candidate{cand}, true
+}
+
+func (s *sourceFile) protoOutline() []string {
+	if s.proto == nil {
+		s.proto = buildProtoOutline(s.Lines())
+	}
+	return s.proto
+}
+
+// buildProtoOutline records, for each line, the dotted path of the messages,
+// enums and services whose bodies enclose it.
+func buildProtoOutline(lines []string) []string {
+	type frame struct {
+		name  string
+		depth int
+	}
+
+	out := make([]string, len(lines))
+	var stack []frame
+	var sc bracketScanner
+	names := func() string {
+		parts := make([]string, len(stack))
+		for i, f := range stack {
+			parts[i] = f.name
+		}
+		return strings.Join(parts, ".")
+	}
+
+	for i, raw := range lines {
+		trimmed := strings.TrimSpace(raw)
+		out[i] = names()
+
+		if sc.idle() {
+			keyword, rest := leadingIdentifier(trimmed)
+			if _, ok := protoKinds[keyword]; ok && keyword != "rpc" && strings.Contains(rest, "{") {
+				if name, _ := leadingIdentifier(strings.TrimLeft(rest, " \t")); name != "" {
+					stack = append(stack, frame{name: name, depth: sc.depth})
+				}
+			}
+		}
+
+		sc.scan(trimmed)
+		for len(stack) > 0 && sc.depth <= stack[len(stack)-1].depth {
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return out
+}
+
+var graphQLRootKinds = map[string]Kind{
+	"Query":        KindQuery,
+	"Mutation":     KindMutation,
+	"Subscription": KindSubscription,
+}
+
+func expandGraphQLDeclaration(cand Candidate, src *sourceFile) ([]Candidate, bool) {
+	trimmed := strings.TrimSpace(cand.Text)
+	keyword, rest := leadingIdentifier(trimmed)
+	if keyword == "extend" {
+		keyword, rest = leadingIdentifier(strings.TrimLeft(rest, " \t"))
+	}
+	name, tail := leadingIdentifier(strings.TrimLeft(rest, " \t"))
+	if name == "" {
+		return nil, false
+	}
+
+	cand.Key = name
+	switch keyword {
+	case "type", "interface", "input", "union", "scalar":
+		cand.Kind = KindType
+		cand.SemanticScore = semanticTypeDeclScore
+	case "enum":
+		cand.Kind = KindEnum
+		cand.SemanticScore = semanticTypeDeclScore
+	case "query":
+		cand.Kind = KindQuery
+		cand.SemanticScore = semanticFunctionScore
+	case "mutation":
+		cand.Kind = KindMutation
+		cand.SemanticScore = semanticFunctionScore
+	case "subscription":
+		cand.Kind = KindSubscription
+		cand.SemanticScore = semanticFunctionScore
+	default:
+		return nil, false
+	}
+
+	out := []Candidate{cand}
+	if kind, ok := graphQLRootKinds[name]; ok && keyword == "type" && strings.Contains(tail, "{") {
+		out = append(out, graphQLRootFields(cand, src.Lines(), kind)...)
+	}
+	return out, true
+}
+
+// graphQLRootFields emits the fields of a Query, Mutation or Subscription type
+// as operations of that kind.
+func graphQLRootFields(root Candidate, lines []string, kind Kind) []Candidate {
+	out := make([]Candidate, 0, 8)
+	var sc bracketScanner
+	inDescription := false
+
+	for lineNo := root.Line + 1; lineNo <= len(lines); lineNo++ {
+		raw, _ := sourceLineAt(lines, lineNo)
+		trimmed, indent := trimIndent(raw)
+		if strings.Count(trimmed, `"""`)%2 == 1 {
+			inDescription = !inDescription
+			continue
+		}
+		if inDescription {
+			continue
+		}
+		if hash := strings.IndexByte(trimmed, '#'); hash >= 0 {
+			trimmed = strings.TrimRight(trimmed[:hash], " \t")
+		}
+
+		if sc.depth == 0 {
+			if strings.HasPrefix(trimmed, "}") {
+				break
+			}
+			name, rest := leadingIdentifier(trimmed)
+			rest = strings.TrimLeft(rest, " \t")
+			if name != "" && (strings.HasPrefix(rest, "(") || strings.HasPrefix(rest, ":")) {
+				out = append(out, Candidate{
+					File:          root.File,
+					Line:          lineNo,
+					Col:           indent + 1,
+					Text:          trimmed,
+					Key:           name,
+					LangID:        root.LangID,
+					SemanticScore: semanticFunctionScore,
+					Container:     root.Key,
+					Kind:          kind,
+				})
+			}
+		}
+
+		sc.scan(trimmed)
+		if sc.depth < 0 {
+			break
+		}
+	}
+	return out
+}
+
+func expandSQLDeclaration(cand Candidate) ([]Candidate, bool) {
+	words := sqlWords(cand.Text, 12)
+	if len(words) < 2 || !strings.EqualFold(words[0], "create") {
+		return nil, false
+	}
+
+	i := 1
+	if i+1 < len(words) && strings.EqualFold(words[i], "or") && strings.EqualFold(words[i+1], "replace") {
+		i += 2
+	}
+	for i < len(words) && isSQLWord(words[i], "temp", "temporary", "unique", "materialized", "unlogged") {
+		i++
+	}
+	if i >= len(words) {
+		return nil, false
+	}
+
+	var kind Kind
+	switch strings.ToLower(words[i]) {
+	case "table":
+		kind = KindTable
+		cand.SemanticScore = semanticTypeDeclScore
+	case "view":
+		kind = KindView
+		cand.SemanticScore = semanticTypeDeclScore
+	case "index":
+		kind = KindIndex
+		cand.SemanticScore = semanticConstScore
+	case "function", "procedure":
+		kind = KindFunction
+		cand.SemanticScore = semanticFunctionScore
+	default:
+		return nil, false
+	}
+	i++
+	if i < len(words) && isSQLWord(words[i], "concurrently") {
+		i++
+	}
+	if i+2 < len(words) && isSQLWord(words[i], "if") && isSQLWord(words[i+1], "not") && isSQLWord(words[i+2], "exists") {
+		i += 3
+	}
+	if i >= len(words) || isSQLWord(words[i], "on") {
+		return nil, false
+	}
+
+	name := words[i]
+	container := ""
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		container, name = name[:dot], name[dot+1:]
+	}
+	if kind == KindIndex && i+1 < len(words) && isSQLWord(words[i+1], "on") {
+		j := i + 2
+		if j < len(words) && isSQLWord(words[j], "only") {
+			j++
+		}
+		if j < len(words) {
+			container = words[j]
+		}
+	}
+	if name == "" {
+		return nil, false
+	}
+
+	cand.Key = name
+	cand.Kind = kind
+	cand.Container = container
+	return []Candidate{cand}, true
+}
+
+func isSQLWord(word string, options ...string) bool {
+	for _, option := range options {
+		if strings.EqualFold(word, option) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlWords splits the head of a SQL statement into at most limit words.
+// Quoted identifiers are unquoted and qualified names stay together.
+func sqlWords(text string, limit int) []string {
+	words := make([]string, 0, limit)
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			words = append(words, b.String())
+			b.Reset()
+		}
+	}
+
+	for i := 0; i < len(text) && len(words) < limit; i++ {
+		switch c := text[i]; {
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(text[i+1:], closing)
+			if end < 0 {
+				b.WriteString(text[i+1:])
+				i = len(text)
+				continue
+			}
+			b.WriteString(text[i+1 : i+1+end])
+			i += end + 1
+		case isIdentByte(c) || c == '.' || c == '$':
+			b.WriteByte(c)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
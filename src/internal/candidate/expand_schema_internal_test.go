@@ -0,0 +1,151 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+)
+
+type kindKey struct {
+	Kind Kind
+	Key  string
+}
+
+func kindKeys(cands []Candidate) []kindKey {
+	keys := candidateKeys(cands)
+	out := make([]kindKey, len(cands))
+	for i, cand := range cands {
+		out[i] = kindKey{Kind: cand.Kind, Key: keys[i]}
+	}
+	return out
+}
+
+func TestExpandProtoDeclarationsUseEnclosingMessages(t *testing.T) {
+	src := testSourceFile("api/search.proto", `syntax = "proto3";
+
+message SearchRequest {
+  string query = 1;
+  message Filter {
+    enum Scope { SCOPE_UNSPECIFIED = 0; }
+  }
+}
+
+service SearchService {
+  rpc Search(SearchRequest) returns (SearchResponse);
+}`)
+
+	var got []kindKey
+	for _, line := range []int{3, 5, 6, 10, 11} {
+		got = append(got, kindKeys(expandTestMatch(t, src, line, ProducerConfig{}))...)
+	}
+	want := []kindKey{
+		{Kind: KindMessage, Key: "SearchRequest"},
+		{Kind: KindMessage, Key: "SearchRequest.Filter"},
+		{Kind: KindEnum, Key: "SearchRequest.Filter.Scope"},
+		{Kind: KindService, Key: "SearchService"},
+		{Kind: KindRPC, Key: "SearchService.Search"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandGraphQLRootTypeFields(t *testing.T) {
+	src := testSourceFile("schema.graphql", `type User {
+  id: ID!
+}
+
+type Query {
+  """
+  Look up a user.
+  """
+  user(id: ID!): User # by id
+  users(
+    first: Int
+  ): [User!]!
+}
+
+extend type Mutation {
+  deleteUser(id: ID!): Boolean
+}
+
+query GetUser($id: ID!) {
+  user(id: $id) { id }
+}`)
+
+	var got []kindKey
+	for _, line := range []int{1, 5, 15, 19} {
+		got = append(got, kindKeys(expandTestMatch(t, src, line, ProducerConfig{}))...)
+	}
+	want := []kindKey{
+		{Kind: KindType, Key: "User"},
+		{Kind: KindType, Key: "Query"},
+		{Kind: KindQuery, Key: "Query.user"},
+		{Kind: KindQuery, Key: "Query.users"},
+		{Kind: KindType, Key: "Mutation"},
+		{Kind: KindMutation, Key: "Mutation.deleteUser"},
+		{Kind: KindQuery, Key: "GetUser"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandSQLDeclarations(t *testing.T) {
+	tests := []struct {
+		text string
+		want kindKey
+	}{
+		{text: "CREATE TABLE users (", want: kindKey{Kind: KindTable, Key: "users"}},
+		{text: `create table if not exists "public"."audit_log" (`, want: kindKey{Kind: KindTable, Key: "public.audit_log"}},
+		{text: "CREATE OR REPLACE MATERIALIZED VIEW active_users AS", want: kindKey{Kind: KindView, Key: "active_users"}},
+		{text: "CREATE UNIQUE INDEX CONCURRENTLY idx_users_email ON users (email);", want: kindKey{Kind: KindIndex, Key: "users.idx_users_email"}},
+		{text: "CREATE FUNCTION refresh_stats() RETURNS trigger AS $$", want: kindKey{Kind: KindFunction, Key: "refresh_stats"}},
+	}
+	for _, tt := range tests {
+		got, ok := expandSQLDeclaration(Candidate{Text: tt.text, LangID: LangSQL})
+		if !ok || len(got) != 1 {
+			t.Fatalf("expandSQLDeclaration(%q) = %+v, %v", tt.text, got, ok)
+		}
+		if k := kindKeys(got)[0]; k != tt.want {
+			t.Fatalf("expandSQLDeclaration(%q) = %#v, want %#v", tt.text, k, tt.want)
+		}
+	}
+
+	if _, ok := expandSQLDeclaration(Candidate{Text: "CREATE INDEX ON users (email);"}); ok {
+		t.Fatalf("unnamed index should be left unchanged")
+	}
+}
+
+func TestExpandOpenAPIOperationsAndPaths(t *testing.T) {
+	src := testSourceFile("api/openapi.yaml", `openapi: 3.0.0
+info:
+  title: Users
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      responses: {}
+    delete:
+      operationId: "deleteUser"`)
+
+	var got []kindKey
+	for _, line := range []int{5, 7, 10} {
+		got = append(got, kindKeys(expandTestMatch(t, src, line, ProducerConfig{}))...)
+	}
+	want := []kindKey{
+		{Kind: KindPath, Key: "/users/{id}"},
+		{Kind: KindOperation, Key: "GET /users/{id}.getUser"},
+		{Kind: KindOperation, Key: "DELETE /users/{id}.deleteUser"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+
+	plain := testSourceFile("deploy.yaml", "routes:\n  /health: ok\n  operationId: x")
+	if got := expandTestMatch(t, plain, 2, ProducerConfig{}); len(got) != 0 {
+		t.Fatalf("path key outside OpenAPI = %+v, want dropped", got)
+	}
+	if got := expandTestMatch(t, plain, 3, ProducerConfig{}); len(got) != 1 || got[0].Kind != KindCode {
+		t.Fatalf("operationId outside OpenAPI = %+v, want unchanged", got)
+	}
+}
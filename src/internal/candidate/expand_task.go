@@ -0,0 +1,256 @@
+package candidate
+
+import (
+	"path"
+	"strings"
+)
+
+type taskFile uint8
+
+const (
+	taskFileNone taskFile = iota
+	taskFileMake
+	taskFileJust
+	taskFileDocker
+	taskFilePackageJSON
+	taskFileMise
+	taskFileTaskfile
+)
+
+func detectTaskFile(file string) taskFile {
+	file = strings.ReplaceAll(file, "\\", "/")
+	base := path.Base(file)
+	lower := strings.ToLower(base)
+	switch {
+	case lower == "makefile" || lower == "gnumakefile" || strings.HasSuffix(lower, ".mk"):
+		return taskFileMake
+	case lower == "justfile" || lower == ".justfile" || strings.HasSuffix(lower, ".just"):
+		return taskFileJust
+	case lower == "dockerfile" || lower == "containerfile" || strings.HasPrefix(lower, "dockerfile.") || strings.HasSuffix(lower, ".dockerfile"):
+		return taskFileDocker
+	case base == "package.json":
+		return taskFilePackageJSON
+	case isMiseConfig(file, base):
+		return taskFileMise
+	case lower == "taskfile.yml" || lower == "taskfile.yaml" || lower == "taskfile.dist.yml" || lower == "taskfile.dist.yaml":
+		return taskFileTaskfile
+	default:
+		return taskFileNone
+	}
+}
+
+func isMiseConfig(file string, base string) bool {
+	name := strings.TrimPrefix(base, ".")
+	if name == "mise.toml" || (strings.HasPrefix(name, "mise.") && strings.HasSuffix(name, ".toml")) {
+		return true
+	}
+	return strings.HasSuffix(file, "/mise/config.toml") || strings.HasSuffix(file, ".mise/config.toml") || file == "mise/config.toml"
+}
+
+// expandTaskEntry turns build targets and task definitions into task
+// candidates. Lines that only look like targets, such as Make variables or
+// special targets, are dropped; everything else is left unchanged.
+func expandTaskEntry(cand Candidate, src *sourceFile, kind taskFile) ([]Candidate, bool) {
+	trimmed := strings.TrimSpace(cand.Text)
+	var names []string
+	switch kind {
+	case taskFileMake:
+		if inLineRanges(src.makeDefines(), cand.Line) {
+			return nil, true
+		}
+		var ok bool
+		if names, ok = makeTargets(trimmed); !ok {
+			return nil, false
+		}
+	case taskFileJust:
+		name, ok := justRecipe(trimmed)
+		if !ok {
+			return nil, false
+		}
+		names = []string{name}
+	case taskFileDocker:
+		name, ok := dockerStage(trimmed)
+		if !ok {
+			return nil, false
+		}
+		names = []string{name}
+	case taskFilePackageJSON, taskFileTaskfile:
+		key, _, ok := configEntry(trimmed)
+		if !ok {
+			return nil, false
+		}
+		parent := "scripts"
+		if kind == taskFileTaskfile {
+			parent = "tasks"
+		}
+		if p, indent, ok := parentConfigKey(src.Lines(), cand.Line); !ok || p != parent || (kind == taskFileTaskfile && indent != 0) {
+			return nil, false
+		}
+		names = []string{key}
+	case taskFileMise:
+		name, ok := miseTask(trimmed, src.Lines(), cand.Line)
+		if !ok {
+			return nil, false
+		}
+		names = []string{name}
+	}
+
+	out := make([]Candidate, 0, len(names))
+	for _, name := range names {
+		next := cand
+		next.Key = name
+		next.Kind = KindTask
+		next.SemanticScore = semanticFunctionScore
+		out = append(out, next)
+	}
+	return out, true
+}
+
+// makeTargets parses the targets of a Make rule. Pattern rules, special
+// targets and variable assignments yield no names; ok is false when the line
+// is not a rule at all.
+func makeTargets(trimmed string) ([]string, bool) {
+	colon := strings.IndexByte(trimmed, ':')
+	if colon <= 0 {
+		return nil, false
+	}
+	head := trimmed[:colon]
+	if strings.HasPrefix(trimmed[colon:], ":=") || strings.HasPrefix(trimmed[colon:], "::=") || strings.ContainsAny(head, "=#") {
+		return nil, true
+	}
+
+	var names []string
+	for _, target := range strings.Fields(head) {
+		if strings.HasPrefix(target, ".") || strings.ContainsAny(target, "%$") {
+			continue
+		}
+		names = append(names, target)
+	}
+	return names, true
+}
+
+func (s *sourceFile) makeDefines() []lineRange {
+	if s.defines == nil {
+		s.defines = findMakeDefines(s.Lines())
+		if s.defines == nil {
+			s.defines = []lineRange{}
+		}
+	}
+	return s.defines
+}
+
+// findMakeDefines finds multi-line `define NAME ... endef` variables, whose
+// bodies are text such as canned recipes rather than rules. Nested defines
+// are part of the outermost one.
+func findMakeDefines(lines []string) []lineRange {
+	var out []lineRange
+	depth, first := 0, 0
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		for len(fields) > 0 && (fields[0] == "override" || fields[0] == "export" || fields[0] == "private") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "define":
+			if depth == 0 {
+				first = i + 1
+			}
+			depth++
+		case "endef":
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				out = append(out, lineRange{First: first, Last: i + 1})
+			}
+		}
+	}
+	if depth > 0 {
+		out = append(out, lineRange{First: first, Last: len(lines)})
+	}
+	return out
+}
+
+func justRecipe(trimmed string) (string, bool) {
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "#") {
+		return "", false
+	}
+	trimmed = strings.TrimPrefix(trimmed, "@")
+	end := 0
+	for end < len(trimmed) && (isIdentByte(trimmed[end]) || trimmed[end] == '-') {
+		end++
+	}
+	if end == 0 {
+		return "", false
+	}
+
+	name, rest := trimmed[:end], trimmed[end:]
+	switch name {
+	case "set", "alias", "export", "import", "mod":
+		return "", false
+	}
+	colon := strings.IndexByte(rest, ':')
+	if colon < 0 || strings.HasPrefix(rest[colon:], ":=") {
+		return "", false
+	}
+	return name, true
+}
+
+func dockerStage(trimmed string) (string, bool) {
+	fields := strings.Fields(trimmed)
+	if len(fields) < 4 || !strings.EqualFold(fields[0], "from") || !strings.EqualFold(fields[len(fields)-2], "as") {
+		return "", false
+	}
+	return fields[len(fields)-1], true
+}
+
+// miseTask recognizes [tasks.name] tables and name = ... entries of a [tasks]
+// table.
+func miseTask(trimmed string, lines []string, line int) (string, bool) {
+	if strings.HasPrefix(trimmed, "[") {
+		header := strings.Trim(strings.TrimSpace(trimmed), "[]")
+		name, ok := strings.CutPrefix(header, "tasks.")
+		if !ok || name == "" {
+			return "", false
+		}
+		return strings.Trim(name, `"'`), true
+	}
+
+	eq := strings.IndexByte(trimmed, '=')
+	if eq <= 0 {
+		return "", false
+	}
+	name := strings.Trim(strings.TrimSpace(trimmed[:eq]), `"'`)
+	if name == "" {
+		return "", false
+	}
+	for lineNo := line - 1; lineNo >= 1 && lineNo <= len(lines); lineNo-- {
+		header := strings.TrimSpace(lines[lineNo-1])
+		if strings.HasPrefix(header, "[") {
+			return name, header == "[tasks]"
+		}
+	}
+	return "", false
+}
+
+// parentConfigKey finds the key of the YAML or JSON mapping that encloses
+// line, using indentation.
+func parentConfigKey(lines []string, line int) (string, int, bool) {
+	if line < 1 || line > len(lines) {
+		return "", 0, false
+	}
+	_, indent := trimIndent(lines[line-1])
+	for lineNo := line - 1; lineNo >= 1; lineNo-- {
+		trimmed, lineIndent := trimIndent(lines[lineNo-1])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || lineIndent >= indent {
+			continue
+		}
+		key, _, ok := configEntry(trimmed)
+		return key, lineIndent, ok
+	}
+	return "", 0, false
+}
@@ -0,0 +1,117 @@
+package candidate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func taskKeys(t *testing.T, src *sourceFile, lines ...int) []string {
+	t.Helper()
+
+	var out []string
+	for _, line := range lines {
+		for _, cand := range expandTestMatch(t, src, line, ProducerConfig{}) {
+			if cand.Kind != KindTask {
+				t.Fatalf("line %d = %+v, want task kind", line, cand)
+			}
+			out = append(out, cand.Key)
+		}
+	}
+	return out
+}
+
+func TestExpandMakefileTargets(t *testing.T) {
+	src := testSourceFile("Makefile", `.PHONY: build release
+VERSION = v1:latest
+GOFLAGS := -trimpath
+build test: deps
+	go build ./...
+%.o: %.c
+release:`)
+
+	got := taskKeys(t, src, 1, 2, 3, 4, 6, 7)
+	want := []string{"build", "test", "release"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("targets = %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandMakefileSkipsDefineBodies(t *testing.T) {
+	src := testSourceFile("Makefile", `define run-tests =
+check: lint
+	go test ./...
+endef
+override define nested
+define inner
+inner: ;
+endef
+endef
+build:`)
+
+	got := taskKeys(t, src, 1, 2, 6, 7, 10)
+	want := []string{"build"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("targets = %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandJustfileRecipesAndDockerStages(t *testing.T) {
+	just := testSourceFile("justfile", `set shell := ["bash", "-c"]
+@release version="v1": build
+lint:`)
+	if got := taskKeys(t, just, 2, 3); !reflect.DeepEqual(got, []string{"release", "lint"}) {
+		t.Fatalf("recipes = %#v", got)
+	}
+
+	docker := testSourceFile("build/Dockerfile", `FROM --platform=$BUILDPLATFORM golang:1.23 AS builder
+FROM gcr.io/distroless/static AS runtime`)
+	if got := taskKeys(t, docker, 1, 2); !reflect.DeepEqual(got, []string{"builder", "runtime"}) {
+		t.Fatalf("stages = %#v", got)
+	}
+}
+
+func TestExpandScriptTablesOnlyMatchTaskSections(t *testing.T) {
+	pkg := testSourceFile("package.json", `{
+  "scripts": {
+    "test:unit": "vitest"
+  },
+  "dependencies": {
+    "vitest": "^1.0.0"
+  }
+}`)
+	if got := taskKeys(t, pkg, 3); !reflect.DeepEqual(got, []string{"test:unit"}) {
+		t.Fatalf("scripts = %#v", got)
+	}
+	if got := expandTestMatch(t, pkg, 6, ProducerConfig{}); len(got) != 1 || got[0].Kind != KindCode {
+		t.Fatalf("dependency entry = %+v, want unchanged", got)
+	}
+
+	mise := testSourceFile("mise.toml", `[tools]
+go = "1.23"
+
+[tasks]
+lint = "golangci-lint run"
+
+[tasks.release]
+run = "goreleaser"`)
+	if got := taskKeys(t, mise, 5, 7); !reflect.DeepEqual(got, []string{"lint", "release"}) {
+		t.Fatalf("mise tasks = %#v", got)
+	}
+	for _, line := range []int{2, 8} {
+		if got := expandTestMatch(t, mise, line, ProducerConfig{}); len(got) != 1 || got[0].Kind != KindCode {
+			t.Fatalf("mise line %d = %+v, want unchanged", line, got)
+		}
+	}
+
+	taskfile := testSourceFile("Taskfile.yml", `version: '3'
+tasks:
+  build:
+    cmds:
+      - go build`)
+	if got := taskKeys(t, taskfile, 3); !reflect.DeepEqual(got, []string{"build"}) {
+		t.Fatalf("taskfile tasks = %#v", got)
+	}
+	if got := expandTestMatch(t, taskfile, 4, ProducerConfig{}); len(got) != 1 || got[0].Kind != KindCode {
+		t.Fatalf("taskfile cmds = %+v, want unchanged", got)
+	}
+}
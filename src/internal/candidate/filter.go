@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"unicode"
 )
 
 func FilterCandidates(candidates []Candidate, query string) []FilteredCandidate {
@@ -31,17 +32,17 @@ func FilterCandidatesRangeWithQueryRunes(candidates []Candidate, start int, end
 		return nil
 	}
 
-	caseSensitive := len(qRaw) == len(qLower)
+	q := parseScopedQuery(qRaw, qLower)
 	n := end - start
 	workers := filterWorkerCount(n)
 	var out []FilteredCandidate
 	if workers <= 1 {
 		out = make([]FilteredCandidate, 0, max(1, n/4))
-		out = appendScoredRange(out, candidates, nil, start, end, qRaw, qLower, caseSensitive)
+		out = appendScoredRange(out, candidates, nil, start, end, q)
 	} else {
 		out = filterCandidatesParallelChunks(workers, n, func(chunkStart int, chunkEnd int) []FilteredCandidate {
 			local := make([]FilteredCandidate, 0, max(1, (chunkEnd-chunkStart)/4))
-			return appendScoredRange(local, candidates, nil, start+chunkStart, start+chunkEnd, qRaw, qLower, caseSensitive)
+			return appendScoredRange(local, candidates, nil, start+chunkStart, start+chunkEnd, q)
 		})
 	}
 
@@ -65,7 +66,7 @@ func filterCandidatesCore(candidates []Candidate, subset []FilteredCandidate, qR
 		return nil
 	}
 
-	caseSensitive := len(qRaw) == len(qLower)
+	q := parseScopedQuery(qRaw, qLower)
 	rangeLen := len(candidates)
 	serialCapacity := len(candidates) / 4
 	parallelDivisor := 4
@@ -79,11 +80,11 @@ func filterCandidatesCore(candidates []Candidate, subset []FilteredCandidate, qR
 	var out []FilteredCandidate
 	if workers <= 1 {
 		out = make([]FilteredCandidate, 0, serialCapacity)
-		out = appendScoredRange(out, candidates, subset, 0, rangeLen, qRaw, qLower, caseSensitive)
+		out = appendScoredRange(out, candidates, subset, 0, rangeLen, q)
 	} else {
 		out = filterCandidatesParallelChunks(workers, rangeLen, func(start int, end int) []FilteredCandidate {
 			local := make([]FilteredCandidate, 0, max(1, (end-start)/parallelDivisor))
-			return appendScoredRange(local, candidates, subset, start, end, qRaw, qLower, caseSensitive)
+			return appendScoredRange(local, candidates, subset, start, end, q)
 		})
 	}
 
@@ -112,10 +113,10 @@ func filterWorkerCount(n int) int {
 	return workers
 }
 
-func appendScoredRange(out []FilteredCandidate, candidates []Candidate, subset []FilteredCandidate, start int, end int, qRaw []rune, qLower []rune, caseSensitive bool) []FilteredCandidate {
+func appendScoredRange(out []FilteredCandidate, candidates []Candidate, subset []FilteredCandidate, start int, end int, q scopedQuery) []FilteredCandidate {
 	if subset == nil {
 		for i := start; i < end; i++ {
-			item, ok := scoreCandidate(&candidates[i], int32(i), qRaw, qLower, caseSensitive)
+			item, ok := scoreCandidate(&candidates[i], int32(i), q)
 			if !ok {
 				continue
 			}
@@ -130,7 +131,7 @@ func appendScoredRange(out []FilteredCandidate, candidates []Candidate, subset [
 			continue
 		}
 
-		item, ok := scoreCandidate(&candidates[idx], subset[i].Index, qRaw, qLower, caseSensitive)
+		item, ok := scoreCandidate(&candidates[idx], subset[i].Index, q)
 		if !ok {
 			continue
 		}
@@ -217,10 +218,74 @@ func MergeFilteredCandidates(candidates []Candidate, left []FilteredCandidate, r
 	return out
 }
 
-func scoreCandidate(cand *Candidate, index int32, qRaw []rune, qLower []rune, caseSensitive bool) (FilteredCandidate, bool) {
+// generatedPenalty ranks matches from generated files below comparable
+// matches from hand-written ones, and dependencyPenalty ranks dependency
+// sources below first-party code.
+const (
+	generatedPenalty  = 600
+	dependencyPenalty = 1000
+)
+
+// scopedQuery is a query with its root:, change: and doc: scopes cut off,
+// parsed once per filter rather than for every candidate.
+type scopedQuery struct {
+	raw           []rune
+	lower         []rune
+	caseSensitive bool
+	// root is the root: scope, when rooted.
+	root   []rune
+	rooted bool
+	// change is the change: scope, when changeScoped.
+	change       []rune
+	changeScoped bool
+	// doc matches the rest of the query against doc comments only.
+	doc bool
+}
+
+func parseScopedQuery(qRaw []rune, qLower []rune) scopedQuery {
+	q := scopedQuery{raw: qRaw, lower: qLower, caseSensitive: len(qRaw) == len(qLower)}
+	if root, restLower, ok := CutRootScope(qLower); ok {
+		_, restRaw, _ := CutRootScope(qRaw)
+		q.root, q.rooted = root, true
+		q.raw, q.lower = restRaw, restLower
+	}
+	if change, restLower, ok := CutChangeScope(q.lower); ok {
+		_, restRaw, _ := CutChangeScope(q.raw)
+		q.change, q.changeScoped = change, true
+		q.raw, q.lower = restRaw, restLower
+	}
+	if docLower, ok := CutDocScope(q.lower); ok {
+		docRaw, _ := CutDocScope(q.raw)
+		q.raw, q.lower, q.doc = docRaw, docLower, true
+	}
+	return q
+}
+
+func scoreCandidate(cand *Candidate, index int32, q scopedQuery) (FilteredCandidate, bool) {
+	if q.rooted && !matchesLabel(cand.Root, q.root) {
+		return FilteredCandidate{}, false
+	}
+	if q.changeScoped && (cand.Change == "" || !matchesLabel(string(cand.Change), q.change)) {
+		return FilteredCandidate{}, false
+	}
+	if (q.rooted || q.changeScoped) && len(q.lower) == 0 && !q.doc {
+		return FilteredCandidate{Index: index}, true
+	}
+	qRaw, qLower, caseSensitive := q.raw, q.lower, q.caseSensitive
+	if q.doc {
+		return scoreDocCandidate(cand, index, qRaw, qLower, caseSensitive)
+	}
+
 	keyScore, _, keyOK := fuzzyScore(cand.Key, qRaw, qLower, caseSensitive)
+	if !keyOK && cand.Container != "" {
+		keyScore, _, keyOK = fuzzyScoreQualified(cand.Container, cand.Key, qRaw, qLower, caseSensitive)
+	}
 	textScore, textSpan, textOK := fuzzyScore(cand.Text, qRaw, qLower, caseSensitive)
 	pathScore, pathSpan, pathOK := fuzzyScore(cand.File, qRaw, qLower, caseSensitive)
+	annotationScore, annotationOK := 0, false
+	if cand.Annotations != "" {
+		annotationScore, _, annotationOK = fuzzyScore(cand.Annotations, qRaw, qLower, caseSensitive)
+	}
 
 	queryLen := nonSpaceRuneCount(qLower)
 	if textOK && rejectLooseFuzzyMatch(textScore, textSpan, queryLen) {
@@ -230,7 +295,7 @@ func scoreCandidate(cand *Candidate, index int32, qRaw []rune, qLower []rune, ca
 		pathOK = false
 	}
 
-	if !keyOK && !textOK && !pathOK {
+	if !keyOK && !textOK && !pathOK && !annotationOK {
 		return FilteredCandidate{}, false
 	}
 
@@ -244,6 +309,9 @@ func scoreCandidate(cand *Candidate, index int32, qRaw []rune, qLower []rune, ca
 	if pathOK {
 		score = maxInt32(score, int32(1200+pathScore-120))
 	}
+	if annotationOK {
+		score = maxInt32(score, int32(1600+annotationScore*2-60))
+	}
 	if keyOK {
 		score += int32(candidateSemanticScore(cand))
 	}
@@ -251,6 +319,12 @@ func scoreCandidate(cand *Candidate, index int32, qRaw []rune, qLower []rune, ca
 	if keyOK && textOK {
 		score += 80
 	}
+	if cand.Generated {
+		score -= generatedPenalty
+	}
+	if cand.Dependency {
+		score -= dependencyPenalty
+	}
 
 	item := FilteredCandidate{Index: index, Score: score}
 	if pathOK && !textOK && (!keyOK || keyLooksLikeFilename(cand)) {
@@ -261,6 +335,93 @@ func scoreCandidate(cand *Candidate, index int32, qRaw []rune, qLower []rune, ca
 	return item, true
 }
 
+// CutDocScope strips a leading `doc:` scope from a query. Scoped queries
+// match the doc comment summary only.
+func CutDocScope(q []rune) ([]rune, bool) {
+	const scope = "doc:"
+	if len(q) < len(scope) {
+		return q, false
+	}
+	for i, r := range scope {
+		if unicode.ToLower(q[i]) != r {
+			return q, false
+		}
+	}
+	rest := q[len(scope):]
+	for len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	return rest, true
+}
+
+// CutRootScope strips a leading `root:name` scope from a query, returning the
+// root name and the rest of the query. Scoped queries only match candidates
+// from roots whose label starts with the name.
+func CutRootScope(q []rune) ([]rune, []rune, bool) {
+	return cutNamedScope(q, "root:")
+}
+
+// CutChangeScope strips a leading `change:kind` scope from a query, returning
+// the kind and the rest of the query. Scoped queries only match symbol diff
+// rows whose change starts with the kind, as in `change:rem`.
+func CutChangeScope(q []rune) ([]rune, []rune, bool) {
+	return cutNamedScope(q, "change:")
+}
+
+func cutNamedScope(q []rune, scope string) ([]rune, []rune, bool) {
+	if len(q) < len(scope) {
+		return nil, q, false
+	}
+	for i, r := range scope {
+		if unicode.ToLower(q[i]) != r {
+			return nil, q, false
+		}
+	}
+	rest := q[len(scope):]
+	end := 0
+	for end < len(rest) && rest[end] != ' ' {
+		end++
+	}
+	name := rest[:end]
+	rest = rest[end:]
+	for len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	return name, rest, true
+}
+
+// matchesLabel reports whether label starts with prefix, ignoring case.
+func matchesLabel(label string, prefix []rune) bool {
+	labelRunes := []rune(label)
+	if len(prefix) > len(labelRunes) {
+		return false
+	}
+	for i, r := range prefix {
+		if unicode.ToLower(labelRunes[i]) != unicode.ToLower(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func scoreDocCandidate(cand *Candidate, index int32, qRaw []rune, qLower []rune, caseSensitive bool) (FilteredCandidate, bool) {
+	if cand.Doc == "" {
+		return FilteredCandidate{}, false
+	}
+	docScore, docSpan, docOK := fuzzyScore(cand.Doc, qRaw, qLower, caseSensitive)
+	if !docOK || rejectLooseFuzzyMatch(docScore, docSpan, nonSpaceRuneCount(qLower)) {
+		return FilteredCandidate{}, false
+	}
+	score := int32(1600+docScore*2) + int32(candidateSemanticScore(cand))
+	if cand.Generated {
+		score -= generatedPenalty
+	}
+	if cand.Dependency {
+		score -= dependencyPenalty
+	}
+	return FilteredCandidate{Index: index, Score: score}, true
+}
+
 func rejectLooseFuzzyMatch(score int, span int, queryLen int) bool {
 	if queryLen <= 1 || span <= 0 {
 		return false
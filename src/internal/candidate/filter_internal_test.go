@@ -198,3 +198,134 @@ func TestFilterCandidatesFilenameKeyMatchOpensFirstLine(t *testing.T) {
 		t.Fatalf("expected filename-key match to open at 1:1, got %d:%d", res[0].OpenLine, res[0].OpenCol)
 	}
 }
+
+func TestFilterCandidatesMatchesContainerQualifiedKey(t *testing.T) {
+	candidates := []Candidate{
+		{ID: 1, File: "server.go", Text: "Addr string", Key: "Addr", Container: "Server"},
+		{ID: 2, File: "client.go", Text: "Addr string", Key: "Addr", Container: "Client"},
+	}
+
+	res := FilterCandidates(candidates, "server.addr")
+	if len(res) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(res))
+	}
+	if got := candidates[int(res[0].Index)].Container; got != "Server" {
+		t.Fatalf("expected Server.Addr, got container %q", got)
+	}
+}
+
+func TestFilterCandidatesMatchesConfigKeyPathWithSpaces(t *testing.T) {
+	candidates := []Candidate{
+		{ID: 1, File: "values.yaml", Text: "enabled: true", Key: "enabled", Container: "server.tls"},
+		{ID: 2, File: "values.yaml", Text: "enabled: false", Key: "enabled", Container: "metrics"},
+	}
+
+	res := FilterCandidates(candidates, "tls enabled")
+	if len(res) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(res))
+	}
+	if got := candidates[int(res[0].Index)].Container; got != "server.tls" {
+		t.Fatalf("expected server.tls.enabled, got container %q", got)
+	}
+}
+
+func TestFilterCandidatesDocScope(t *testing.T) {
+	candidates := []Candidate{
+		{ID: 1, File: "json.go", Text: "func Parse(b []byte) error", Key: "Parse", Doc: "Parse decodes JSON from b."},
+		{ID: 2, File: "yaml.go", Text: "func Parse(b []byte) error", Key: "Parse", Doc: "Parse decodes YAML documents."},
+		{ID: 3, File: "json.go", Text: "func decodeJSON() error", Key: "decodeJSON"},
+	}
+
+	res := FilterCandidates(candidates, "doc: yaml")
+	if len(res) != 1 || candidates[int(res[0].Index)].ID != 2 {
+		t.Fatalf("doc: yaml matched %#v", res)
+	}
+
+	res = FilterCandidates(candidates, "Doc:")
+	if len(res) != 2 {
+		t.Fatalf("empty doc scope should list documented candidates, got %d", len(res))
+	}
+
+	res = FilterCandidates(candidates, "json")
+	for _, item := range res {
+		if candidates[int(item.Index)].ID == 2 {
+			t.Fatalf("unscoped query should not match doc text")
+		}
+	}
+}
+
+func TestFilterCandidatesDemotesGenerated(t *testing.T) {
+	candidates := []Candidate{
+		{ID: 1, File: "api/user.pb.go", Text: "type User struct {", Key: "User", Generated: true},
+		{ID: 2, File: "model/user.go", Text: "type User struct {", Key: "User"},
+	}
+
+	res := FilterCandidates(candidates, "User")
+	if len(res) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(res))
+	}
+	if got := candidates[int(res[0].Index)].ID; got != 2 {
+		t.Fatalf("expected hand-written User first, got ID %d", got)
+	}
+}
+
+func TestFilterCandidatesRootScope(t *testing.T) {
+	candidates := []Candidate{
+		{ID: 1, File: "user.go", Text: "type User struct", Key: "User", Root: "api"},
+		{ID: 2, File: "user.ts", Text: "interface User", Key: "User", Root: "web"},
+		{ID: 3, File: "server.go", Text: "func Serve()", Key: "Serve", Root: "api"},
+	}
+
+	res := FilterCandidates(candidates, "root:web user")
+	if len(res) != 1 || candidates[int(res[0].Index)].ID != 2 {
+		t.Fatalf("root:web user matched %#v", res)
+	}
+
+	res = FilterCandidates(candidates, "Root:AP")
+	if len(res) != 2 {
+		t.Fatalf("root prefix should list every candidate of the root, got %d", len(res))
+	}
+
+	res = FilterCandidates(candidates, "root:api doc:")
+	if len(res) != 0 {
+		t.Fatalf("root scope should combine with the doc scope, got %#v", res)
+	}
+}
+
+func TestFilterCandidatesChangeScope(t *testing.T) {
+	candidates := []Candidate{
+		{ID: 1, File: "user.go", Text: "type User struct", Key: "User", Change: ChangeAdded},
+		{ID: 2, File: "user.go", Text: "func NewUser()", Key: "NewUser", Change: ChangeRemoved},
+		{ID: 3, File: "server.go", Text: "func Serve()", Key: "Serve"},
+	}
+
+	res := FilterCandidates(candidates, "change:rem user")
+	if len(res) != 1 || candidates[int(res[0].Index)].ID != 2 {
+		t.Fatalf("change:rem user matched %#v", res)
+	}
+
+	res = FilterCandidates(candidates, "change:")
+	if len(res) != 2 {
+		t.Fatalf("an empty change scope should list every diff row, got %d", len(res))
+	}
+
+	res = FilterCandidates(candidates, "root:added")
+	if len(res) != 0 {
+		t.Fatalf("root scope should not match changes, got %#v", res)
+	}
+}
+
+func TestFilterCandidatesDemotesDependencies(t *testing.T) {
+	candidates := []Candidate{
+		{ID: 1, File: "command.go", Text: "type Command struct {", Key: "Command", Root: "github.com/spf13/cobra", Dependency: true},
+		{ID: 2, File: "cli/cmd.go", Text: "type Cmd struct {", Key: "Cmd"},
+	}
+
+	res := FilterCandidates(candidates, "Cmd")
+	if len(res) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(res))
+	}
+	if got := candidates[int(res[0].Index)].ID; got != 2 {
+		t.Fatalf("expected first-party Cmd first, got ID %d", got)
+	}
+}
@@ -10,78 +10,130 @@ func fuzzyScore(text string, queryRaw []rune, queryLower []rune, caseSensitive b
 		return 0, 0, true
 	}
 
-	queryLen := nonSpaceRuneCount(queryLower)
-	if queryLen == 0 {
+	m, ok := newFuzzyMatcher(queryRaw, queryLower, caseSensitive)
+	if !ok {
 		return 0, 0, true
 	}
+	m.feed(text)
+	return m.result()
+}
 
-	qi := skipLeadingSpaces(queryLower, 0)
-	if qi == len(queryLower) {
+// fuzzyScoreQualified scores the query against "container.key" without
+// building the joined string.
+func fuzzyScoreQualified(container string, key string, queryRaw []rune, queryLower []rune, caseSensitive bool) (int, int, bool) {
+	if len(queryLower) == 0 {
 		return 0, 0, true
 	}
 
-	last := -2
-	first := -1
-	score := 0
-	runeIdx := 0
-	var prev rune
-	hasPrev := false
-	caseMatches := 0
+	m, ok := newFuzzyMatcher(queryRaw, queryLower, caseSensitive)
+	if !ok {
+		return 0, 0, true
+	}
+	m.feed(container)
+	m.feed(".")
+	m.feed(key)
+	return m.result()
+}
 
+type fuzzyMatcher struct {
+	queryRaw      []rune
+	queryLower    []rune
+	caseSensitive bool
+	queryLen      int
+
+	qi          int
+	last        int
+	first       int
+	score       int
+	runeIdx     int
+	prev        rune
+	hasPrev     bool
+	caseMatches int
+}
+
+func newFuzzyMatcher(queryRaw []rune, queryLower []rune, caseSensitive bool) (fuzzyMatcher, bool) {
+	queryLen := nonSpaceRuneCount(queryLower)
+	if queryLen == 0 {
+		return fuzzyMatcher{}, false
+	}
+
+	qi := skipLeadingSpaces(queryLower, 0)
+	if qi == len(queryLower) {
+		return fuzzyMatcher{}, false
+	}
+
+	return fuzzyMatcher{
+		queryRaw:      queryRaw,
+		queryLower:    queryLower,
+		caseSensitive: caseSensitive,
+		queryLen:      queryLen,
+		qi:            qi,
+		last:          -2,
+		first:         -1,
+	}, true
+}
+
+func (m *fuzzyMatcher) feed(text string) {
+	queryLower := m.queryLower
 	for _, raw := range text {
 		r := lowerRuneFast(raw)
 
-		if qi < len(queryLower) && r == queryLower[qi] {
+		if m.qi < len(queryLower) && r == queryLower[m.qi] {
 			bonus := 10
-			if runeIdx == 0 || (hasPrev && isBoundaryRune(prev)) {
+			if m.runeIdx == 0 || (m.hasPrev && isBoundaryRune(m.prev)) {
 				bonus += 8
 			}
-			if last+1 == runeIdx {
+			if m.last+1 == m.runeIdx {
 				bonus += 6
 			}
-			if caseSensitive && qi < len(queryRaw) && raw == queryRaw[qi] {
+			if m.caseSensitive && m.qi < len(m.queryRaw) && raw == m.queryRaw[m.qi] {
 				bonus += 4
-				caseMatches++
+				m.caseMatches++
 			}
 
-			score += bonus
-			if first < 0 {
-				first = runeIdx
+			m.score += bonus
+			if m.first < 0 {
+				m.first = m.runeIdx
 			}
-			last = runeIdx
-			qi++
-			qi = skipLeadingSpaces(queryLower, qi)
+			m.last = m.runeIdx
+			m.qi++
+			m.qi = skipLeadingSpaces(queryLower, m.qi)
 		}
 
-		prev = r
-		hasPrev = true
-		runeIdx++
+		m.prev = r
+		m.hasPrev = true
+		m.runeIdx++
 	}
+}
 
-	qi = skipLeadingSpaces(queryLower, qi)
+func (m *fuzzyMatcher) result() (int, int, bool) {
+	queryLower := m.queryLower
+	qi := skipLeadingSpaces(queryLower, m.qi)
 	if qi != len(queryLower) {
 		return 0, 0, false
 	}
 
+	score := m.score
+	runeIdx := m.runeIdx
 	if runeIdx > len(queryLower) {
 		score -= runeIdx - len(queryLower)
 	}
 	if runeIdx < 40 {
 		score += 40 - runeIdx
 	}
-	if caseMatches > 0 {
-		score += caseMatches * 3
+	if m.caseMatches > 0 {
+		score += m.caseMatches * 3
 	}
 
 	span := 0
-	if first >= 0 {
-		span = last - first + 1
+	if m.first >= 0 {
+		span = m.last - m.first + 1
 	}
 	if span > 0 {
-		if span == queryLen {
+		if span == m.queryLen {
 			score += 12
-		} else if span > queryLen {
-			score -= (span - queryLen) * 2
+		} else if span > m.queryLen {
+			score -= (span - m.queryLen) * 2
 		}
 	}
 
@@ -0,0 +1,62 @@
+package candidate
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"snav/internal/lang"
+)
+
+// generatedHeaderLines is how far into a file generated-code markers are
+// looked for.
+const generatedHeaderLines = 40
+
+var generatedMarker = regexp.MustCompile(`Code generated .*DO NOT EDIT|@generated\b|<auto-generated`)
+
+// isGeneratedFile reports whether a file was written by a code generator.
+// A linguist-generated attribute in .gitattributes decides first, then the
+// file name, then a marker comment near the top of the file such as Go's
+// "// Code generated ... DO NOT EDIT." or "@generated". Only the header is
+// read for the marker.
+func isGeneratedFile(rel string, overrides *lang.Overrides, src *sourceFile) bool {
+	if generated, ok := overrides.Generated(rel); ok {
+		return generated
+	}
+	if isGeneratedName(rel) {
+		return true
+	}
+	return hasGeneratedMarker(src.Head(generatedHeaderLines))
+}
+
+func isGeneratedName(rel string) bool {
+	base := path.Base(filepath.ToSlash(rel))
+	for _, glob := range generatedFileGlobs {
+		if ok, _ := path.Match(glob, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func hasGeneratedMarker(lines []string) bool {
+	for i, line := range lines {
+		if i >= generatedHeaderLines {
+			break
+		}
+		if isCommentLine(strings.TrimSpace(line)) && generatedMarker.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCommentLine(trimmed string) bool {
+	for _, prefix := range []string{"//", "/*", "*", "#", "--", "<!--", ";", "%", "'"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
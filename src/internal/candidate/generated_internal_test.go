@@ -0,0 +1,51 @@
+package candidate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"snav/internal/lang"
+)
+
+func TestIsGeneratedFile(t *testing.T) {
+	overrides := lang.ParseOverrides("gen/** linguist-generated\ngen/keep.go -linguist-generated\n")
+	tests := []struct {
+		path string
+		src  string
+		want bool
+	}{
+		{path: "api/user.pb.go", src: "package api", want: true},
+		{path: "pkg/apis/v1/zz_generated.deepcopy.go", src: "package v1", want: true},
+		{path: "proto/user_pb2.py", src: "", want: true},
+		{path: "internal/store.go", src: "// Code generated by mockgen. DO NOT EDIT.\n\npackage store", want: true},
+		{path: "lib/schema.js", src: "/**\n * @generated SignedSource<<abc>>\n */\nexport const a = 1", want: true},
+		{path: "Models/User.cs", src: "// <auto-generated>\n// </auto-generated>\nclass User {}", want: true},
+		{path: "gen/api.go", src: "package gen", want: true},
+		{path: "gen/keep.go", src: "// Code generated by hand. DO NOT EDIT.\npackage gen", want: false},
+		{path: "internal/codegen.go", src: "package internal\n\nconst header = \"// Code generated by snav. DO NOT EDIT.\"", want: false},
+		{path: "internal/plain.go", src: "package internal", want: false},
+	}
+	for _, tt := range tests {
+		if got := isGeneratedFile(tt.path, overrides, testSourceFile(tt.path, tt.src)); got != tt.want {
+			t.Fatalf("isGeneratedFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIsGeneratedFileReadsOnlyTheHeader(t *testing.T) {
+	root := t.TempDir()
+	body := "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n" + strings.Repeat("func F() {}\n", 200)
+	if err := os.WriteFile(filepath.Join(root, "api.go"), []byte(body), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	src := newSourceFile(root)
+	src.reset("api.go")
+	if !isGeneratedFile("api.go", lang.ParseOverrides(""), src) {
+		t.Fatal("isGeneratedFile = false, want true")
+	}
+	if src.loaded || len(src.head) > generatedHeaderLines {
+		t.Fatalf("read %d lines, loaded = %v; want only the header", len(src.head), src.loaded)
+	}
+}
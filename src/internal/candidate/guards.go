@@ -0,0 +1,154 @@
+package candidate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"snav/internal/lang"
+)
+
+const (
+	// DefaultMaxFileSize is passed to rg --max-filesize.
+	DefaultMaxFileSize = "1M"
+	// DefaultMaxFileCandidates caps the candidates indexed from one file.
+	DefaultMaxFileCandidates = 2000
+	// maxCandidateTextLen bounds Text and Signature in bytes.
+	maxCandidateTextLen = 400
+
+	// A web file is treated as minified when a line is longer than
+	// minifiedLineLen and its lines average more than minifiedAverageLen.
+	minifiedLineLen    = 1000
+	minifiedAverageLen = 200
+	// minifiedSampleLines is how many leading lines the check samples.
+	minifiedSampleLines = 20
+)
+
+// ScanResult reports how a scan ended. Root is the RootName of the scanned
+// root.
+type ScanResult struct {
+	Root    string
+	Err     error
+	Skipped SkipStats
+}
+
+// SkipStats counts the files the producer left out or cut short. Oversized
+// files are those over the --max-filesize limit that a pass would search.
+type SkipStats struct {
+	Oversized int
+	Minified  int
+	Capped    int
+}
+
+// Summary describes the skipped files for the status bar, or "" when none
+// were skipped.
+func (s SkipStats) Summary() string {
+	var parts []string
+	if s.Oversized > 0 {
+		parts = append(parts, fmt.Sprintf("skipped %d oversized %s", s.Oversized, plural(s.Oversized, "file", "files")))
+	}
+	if s.Minified > 0 {
+		parts = append(parts, fmt.Sprintf("skipped %d minified %s", s.Minified, plural(s.Minified, "file", "files")))
+	}
+	if s.Capped > 0 {
+		parts = append(parts, fmt.Sprintf("capped %d %s", s.Capped, plural(s.Capped, "file", "files")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Add returns the combined counts of s and other.
+func (s SkipStats) Add(other SkipStats) SkipStats {
+	return SkipStats{
+		Oversized: s.Oversized + other.Oversized,
+		Minified:  s.Minified + other.Minified,
+		Capped:    s.Capped + other.Capped,
+	}
+}
+
+// maxFileSize is the --max-filesize limit of cfg.
+func maxFileSize(cfg ProducerConfig) string {
+	if cfg.MaxFileSize == "" {
+		return DefaultMaxFileSize
+	}
+	return cfg.MaxFileSize
+}
+
+// countOversizedFiles counts the files in cfg.Root that passes would search
+// but rg skips for being over the size limit. It is best effort: files that
+// cannot be listed or stat'ed are not counted.
+func countOversizedFiles(ctx context.Context, cfg ProducerConfig, passes []rgPass) int {
+	limit, err := parseFileSize(maxFileSize(cfg))
+	if err != nil {
+		return 0
+	}
+	files, err := listRGFiles(ctx, cfg.Root, rgFileArgs(cfg, false))
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for _, file := range files {
+		if !passesSearch(passes, file) {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(cfg.Root, file)); err == nil && info.Size() > limit {
+			n++
+		}
+	}
+	return n
+}
+
+// passesSearch reports whether any of passes searches rel.
+func passesSearch(passes []rgPass, rel string) bool {
+	for _, pass := range passes {
+		if len(pass.globs) == 0 || pathMatchesGlobs(pass.globs, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func plural(n int, one string, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// isMinifiedFile reports whether a file is a minified bundle: a .min. name,
+// or for web languages, very long lines among its first few. Other
+// languages are not read.
+func isMinifiedFile(rel string, id LangID, src *sourceFile) bool {
+	if strings.Contains(path.Base(filepath.ToSlash(rel)), ".min.") {
+		return true
+	}
+	switch id {
+	case LangJavaScript, LangTypeScript, LangTSX, lang.CSS, lang.HTML, LangJSON:
+	default:
+		return false
+	}
+
+	lines := src.Head(minifiedSampleLines)
+	total, longest := 0, 0
+	for _, line := range lines {
+		total += len(line)
+		longest = max(longest, len(line))
+	}
+	return len(lines) > 0 && longest > minifiedLineLen && total/len(lines) > minifiedAverageLen
+}
+
+// truncateCandidateText cuts s to maxCandidateTextLen bytes on a character
+// boundary.
+func truncateCandidateText(s string) string {
+	if len(s) <= maxCandidateTextLen {
+		return s
+	}
+	cut := maxCandidateTextLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,62 @@
+package candidate
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestIsMinifiedFile(t *testing.T) {
+	bundle := []string{"!function(){" + strings.Repeat("var a=1;", 300) + "}();"}
+	prose := []string{strings.Repeat("A long paragraph kept on one line. ", 60)}
+	tests := []struct {
+		path  string
+		lang  LangID
+		lines []string
+		want  bool
+	}{
+		{path: "static/app.min.js", lang: LangJavaScript, lines: []string{"var a = 1;"}, want: true},
+		{path: "static/app.js", lang: LangJavaScript, lines: bundle, want: true},
+		{path: "static/app.js", lang: LangJavaScript, lines: append([]string{"function a() {", "}"}, bundle[0][:900]), want: false},
+		{path: "docs/guide.go", lang: LangGo, lines: prose, want: false},
+		{path: "static/app.js", lang: LangJavaScript, lines: append(make([]string, minifiedSampleLines), bundle...), want: false},
+	}
+	for _, tt := range tests {
+		if got := isMinifiedFile(tt.path, tt.lang, testSourceFile(tt.path, strings.Join(tt.lines, "\n"))); got != tt.want {
+			t.Fatalf("isMinifiedFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIsMinifiedFileSkipsReadingOtherLanguages(t *testing.T) {
+	src := newSourceFile(t.TempDir())
+	src.reset("main.go")
+	if isMinifiedFile("main.go", LangGo, src) {
+		t.Fatal("isMinifiedFile = true, want false")
+	}
+	if src.loaded || src.headN != 0 {
+		t.Fatal("isMinifiedFile read a Go file")
+	}
+}
+
+func TestTruncateCandidateText(t *testing.T) {
+	if got := truncateCandidateText("func A() {}"); got != "func A() {}" {
+		t.Fatalf("short text changed: %q", got)
+	}
+	got := truncateCandidateText("x" + strings.Repeat("é", maxCandidateTextLen))
+	if !strings.HasSuffix(got, "…") || len(got) > maxCandidateTextLen+len("…") || !utf8.ValidString(got) {
+		t.Fatalf("truncateCandidateText = %q", got)
+	}
+}
+
+func TestSkipStatsSummary(t *testing.T) {
+	if got := (SkipStats{}).Summary(); got != "" {
+		t.Fatalf("empty summary = %q", got)
+	}
+	if got := (SkipStats{Minified: 1, Capped: 3}).Summary(); got != "skipped 1 minified file, capped 3 files" {
+		t.Fatalf("summary = %q", got)
+	}
+	if got := (SkipStats{Oversized: 2}).Summary(); got != "skipped 2 oversized files" {
+		t.Fatalf("summary = %q", got)
+	}
+}
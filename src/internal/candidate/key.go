@@ -4,42 +4,44 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 var keyRegexes = []*regexp.Regexp{
-	regexp.MustCompile(`^\s*(?:export\s+)?(?:inline\s+)?namespace\s+([A-Za-z_][A-Za-z0-9_]*(?:(?:::|\.)[A-Za-z_][A-Za-z0-9_]*)*)\b`),
-	regexp.MustCompile(`^\s*(?:module|package)\s+([A-Za-z_][A-Za-z0-9_]*(?:(?:::|\.)[A-Za-z_][A-Za-z0-9_]*)*)\b`),
-	regexp.MustCompile(`^\s*(?:(?:export|default|async|public|private|protected|internal|abstract|final|sealed|partial|static)\s+)*(?:function|class|interface|type|enum|record)\s+([A-Za-z_$][A-Za-z0-9_$]*)\b`),
-	regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][A-Za-z0-9_$]*)`),
-	regexp.MustCompile(`^\s*func\s*(?:\([^)]*\)\s*)?([A-Za-z_][A-Za-z0-9_]*)\s*\(`),
-	regexp.MustCompile(`^\s*(?:type|var|const)\s+([A-Za-z_][A-Za-z0-9_]*)`),
-	regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:fn|struct|enum|trait|mod|type|const|static)\s+([A-Za-z_][A-Za-z0-9_]*)`),
+	regexp.MustCompile(`^\s*(?:export\s+)?(?:inline\s+)?namespace\s+([\p{L}_][\p{L}\p{M}\p{N}_]*(?:(?:::|\.)[\p{L}_][\p{L}\p{M}\p{N}_]*)*)`),
+	regexp.MustCompile(`^\s*(?:module|package)\s+([\p{L}_][\p{L}\p{M}\p{N}_]*(?:(?:::|\.)[\p{L}_][\p{L}\p{M}\p{N}_]*)*)`),
+	regexp.MustCompile(`^\s*(?:(?:export|default|async|public|private|protected|internal|abstract|final|sealed|partial|static)\s+)*(?:function|class|interface|type|enum|record)\s+([\p{L}_$][\p{L}\p{M}\p{N}_$]*)`),
+	regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+([\p{L}_$][\p{L}\p{M}\p{N}_$]*)`),
+	regexp.MustCompile(`^\s*func\s*(?:\([^)]*\)\s*)?([\p{L}_][\p{L}\p{M}\p{N}_]*)\s*\(`),
+	regexp.MustCompile(`^\s*(?:type|var|const)\s+([\p{L}_][\p{L}\p{M}\p{N}_]*)`),
+	regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:fn|struct|enum|trait|mod|type|const|static)\s+([\p{L}_][\p{L}\p{M}\p{N}_]*)`),
 	regexp.MustCompile(`^\s*test\s+"((?:\\.|[^"\\])+)"`),
-	regexp.MustCompile(`^\s*(?:async\s+def|def|class)\s+([A-Za-z_][A-Za-z0-9_]*)`),
-	regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|abstract|virtual|override|async|extern|unsafe|sealed|partial|readonly|synchronized|native|strictfp)\s+)+(?:[A-Za-z_][A-Za-z0-9_<>,.?\[\]]*\s+)+([A-Za-z_][A-Za-z0-9_]*)\s*\(`),
-	regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|abstract|final|sealed|partial|static|open|override|data|inline|suspend|tailrec|operator|infix|external|lateinit|const|inner|annotation|enum|value)\s+)*(?:fun|object|class|interface|typealias)\s+([A-Za-z_][A-Za-z0-9_]*)\b`),
-	regexp.MustCompile(`^\s*(?:interface|class|enum|record)\s+([A-Za-z_][A-Za-z0-9_]*)`),
-	regexp.MustCompile(`^\s*(?:fun|val|var|object|class|interface)\s+([A-Za-z_][A-Za-z0-9_]*)`),
-	regexp.MustCompile(`^\s*\[\[([A-Za-z0-9_.:-]+)\]\]\s*$`),
-	regexp.MustCompile(`^\s*\[([A-Za-z0-9_.:-]+)\]\s*$`),
+	regexp.MustCompile(`^\s*(?:async\s+def|def|class)\s+([\p{L}_][\p{L}\p{M}\p{N}_]*)`),
+	regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|final|abstract|virtual|override|async|extern|unsafe|sealed|partial|readonly|synchronized|native|strictfp)\s+)+(?:[\p{L}_][\p{L}\p{M}\p{N}_<>,.?\[\]]*\s+)+([\p{L}_][\p{L}\p{M}\p{N}_]*)\s*\(`),
+	regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|abstract|final|sealed|partial|static|open|override|data|inline|suspend|tailrec|operator|infix|external|lateinit|const|inner|annotation|enum|value)\s+)*(?:fun|object|class|interface|typealias)\s+([\p{L}_][\p{L}\p{M}\p{N}_]*)`),
+	regexp.MustCompile(`^\s*(?:interface|class|enum|record)\s+([\p{L}_][\p{L}\p{M}\p{N}_]*)`),
+	regexp.MustCompile(`^\s*(?:fun|val|var|object|class|interface)\s+([\p{L}_][\p{L}\p{M}\p{N}_]*)`),
+	regexp.MustCompile(`^\s*\[\[([\p{L}\p{M}\p{N}_.:-]+)\]\]\s*$`),
+	regexp.MustCompile(`^\s*\[([\p{L}\p{M}\p{N}_.:-]+)\]\s*$`),
 	regexp.MustCompile(`^\s*"((?:\\.|[^"\\])+)"\s*:`),
 	regexp.MustCompile(`^\s*'([^']+)'\s*:`),
 	regexp.MustCompile(`^\s*-\s*"((?:\\.|[^"\\])+)"\s*:`),
 	regexp.MustCompile(`^\s*-\s*'([^']+)'\s*:`),
-	regexp.MustCompile(`^\s*-\s*([A-Za-z0-9_.-]+)\s*:`),
-	regexp.MustCompile(`^\s*export\s+([A-Za-z_][A-Za-z0-9_.-]*)\s*=`),
-	regexp.MustCompile(`^\s*[A-Za-z0-9_.-]+\s+"(?:\\.|[^"\\])+"\s+"((?:\\.|[^"\\])+)"\s*\{`),
-	regexp.MustCompile(`^\s*[A-Za-z0-9_.-]+\s+"((?:\\.|[^"\\])+)"\s*\{`),
+	regexp.MustCompile(`^\s*-\s*([\p{L}\p{M}\p{N}_.-]+)\s*:`),
+	regexp.MustCompile(`^\s*export\s+([\p{L}_][\p{L}\p{M}\p{N}_.-]*)\s*=`),
+	regexp.MustCompile(`^\s*[\p{L}\p{M}\p{N}_.-]+\s+"(?:\\.|[^"\\])+"\s+"((?:\\.|[^"\\])+)"\s*\{`),
+	regexp.MustCompile(`^\s*[\p{L}\p{M}\p{N}_.-]+\s+"((?:\\.|[^"\\])+)"\s*\{`),
 	regexp.MustCompile(`^\s*<[^>]*\b(?:[Kk][Ee][Yy]|[Nn][Aa][Mm][Ee]|[Ii][Dd])\s*=\s*"((?:\\.|[^"\\])+)"`),
 	regexp.MustCompile(`^\s*<[^>]*\b(?:[Kk][Ee][Yy]|[Nn][Aa][Mm][Ee]|[Ii][Dd])\s*=\s*'([^']+)'`),
-	regexp.MustCompile(`^\s*<\s*([A-Za-z_][A-Za-z0-9_.:-]*)\b`),
-	regexp.MustCompile(`^\s*([A-Za-z0-9_.-]+)\s*\{`),
-	regexp.MustCompile(`^\s*([A-Za-z0-9_.-]+)\s*(?::|=)`),
-	regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*:=`),
-	regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*:`),
+	regexp.MustCompile(`^\s*<\s*([\p{L}_][\p{L}\p{M}\p{N}_.:-]*)`),
+	regexp.MustCompile(`^\s*([\p{L}\p{M}\p{N}_.-]+)\s*\{`),
+	regexp.MustCompile(`^\s*([\p{L}\p{M}\p{N}_.-]+)\s*(?::|=)`),
+	regexp.MustCompile(`^\s*([\p{L}_][\p{L}\p{M}\p{N}_]*)\s*:=`),
+	regexp.MustCompile(`^\s*([\p{L}_][\p{L}\p{M}\p{N}_]*)\s*:`),
 }
 
-var firstIdentifier = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+var firstIdentifier = regexp.MustCompile(`[\p{L}_][\p{L}\p{M}\p{N}_]*`)
 
 func ExtractKey(text string, file string) string {
 	return extractKeyWithConfigHint(text, file, looksLikeConfigFile(file))
@@ -93,12 +95,26 @@ func extractFunctionKeyFast(text string) (string, bool) {
 	}
 
 	name := head[start:end]
-	if matcherStopWords[strings.ToLower(name)] {
+	if matcherStopWords[strings.ToLower(name)] || isAssignedFunction(head[:start], name) {
 		return "", false
 	}
 	return name, true
 }
 
+// isAssignedFunction reports whether name is the async or function keyword
+// of a function value, as in "const foo = async () =>". The key is then the
+// binding, which the regular expressions extract.
+func isAssignedFunction(before string, name string) bool {
+	if name != "async" && name != "function" {
+		return false
+	}
+	before = strings.TrimRight(before, " \t")
+	if name == "function" {
+		before = strings.TrimRight(strings.TrimSuffix(before, "async"), " \t")
+	}
+	return strings.HasSuffix(before, "=") || strings.HasSuffix(before, ":")
+}
+
 func lastIdentifierSpan(s string) (int, int) {
 	end := len(s)
 	for end > 0 {
@@ -120,6 +136,13 @@ func lastIdentifierSpan(s string) (int, int) {
 			start--
 			continue
 		}
+		if b >= utf8.RuneSelf {
+			r, size := utf8.DecodeLastRuneInString(s[:start])
+			if isIdentRune(r) {
+				start -= size
+				continue
+			}
+		}
 		break
 	}
 	if start == end {
@@ -129,6 +152,114 @@ func lastIdentifierSpan(s string) (int, int) {
 	return start, end
 }
 
+// isIdentRune reports whether a non-ASCII rune can be part of an identifier.
+// Callers check ASCII bytes themselves, which keeps the common case cheap.
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nl)
+}
+
+// keyColumn returns the 1-based byte column of key in line, so that editors
+// open on the declared name rather than on its modifiers. Columns count bytes
+// like rg's. The key is looked for after the declaration keyword and a Go
+// receiver, then anywhere in the line. Qualified keys such as module.vpc or
+// Deployment/api are located by their last segment found in the line, then
+// by their first. When the key is not in the line the column of the first
+// non-blank character is used, unless fallback is later.
+func keyColumn(line string, key string, fallback int) int {
+	start := declarationKeywordEnd(line)
+	idx := keyIndex(line[start:], key)
+	if idx >= 0 {
+		idx += start
+	} else {
+		idx = keyIndex(line, key)
+	}
+	if idx < 0 {
+		_, indent := trimIndent(line)
+		return max(fallback, indent+1)
+	}
+	return idx + 1
+}
+
+func keyIndex(line string, key string) int {
+	idx := wordIndex(line, key)
+	if idx < 0 {
+		segments := strings.FieldsFunc(key, isKeySeparator)
+		for i := len(segments) - 1; i >= 0 && idx < 0; i-- {
+			idx = wordIndex(line, segments[i])
+		}
+	}
+	return idx
+}
+
+var declarationKeywords = map[string]bool{
+	"func": true, "function": true, "fn": true, "def": true, "fun": true,
+	"class": true, "struct": true, "interface": true, "trait": true,
+	"enum": true, "record": true, "type": true, "typealias": true,
+	"const": true, "let": true, "var": true, "val": true,
+	"module": true, "mod": true, "namespace": true, "package": true,
+	"object": true, "protocol": true, "extension": true,
+}
+
+// declarationKeywordEnd returns the byte offset just past the declaration
+// keyword that leads line, after any modifiers, and past a Go method
+// receiver. It returns 0 when the line does not start with one.
+func declarationKeywordEnd(line string) int {
+	rest := strings.TrimLeft(line, " \t")
+	for {
+		word, tail := leadingIdentifier(rest)
+		if word == "" || (tail != "" && tail[0] != ' ' && tail[0] != '\t' && tail[0] != '(') {
+			return 0
+		}
+		if !declarationKeywords[word] {
+			rest = strings.TrimLeft(tail, " \t")
+			continue
+		}
+		end := len(line) - len(tail)
+		if word != "func" {
+			return end
+		}
+		receiver := strings.TrimLeft(tail, " \t")
+		if !strings.HasPrefix(receiver, "(") {
+			return end
+		}
+		if close := strings.IndexByte(receiver, ')'); close >= 0 {
+			return len(line) - len(receiver) + close + 1
+		}
+		return end
+	}
+}
+
+func isKeySeparator(r rune) bool {
+	return r == '.' || r == '/' || r == ':' || r == '#'
+}
+
+// wordIndex finds the first occurrence of word in s that is not part of a
+// longer identifier.
+func wordIndex(s string, word string) int {
+	if word == "" {
+		return -1
+	}
+	for offset := 0; offset < len(s); {
+		i := strings.Index(s[offset:], word)
+		if i < 0 {
+			return -1
+		}
+		start := offset + i
+		end := start + len(word)
+		if (start == 0 || !isWordByte(s[start-1]) || !isWordByte(word[0])) &&
+			(end == len(s) || !isWordByte(s[end]) || !isWordByte(word[len(word)-1])) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+// isWordByte treats every byte of a multi-byte character as part of a word.
+func isWordByte(b byte) bool {
+	return isIdentByte(b) || b == '$' || b >= utf8.RuneSelf
+}
+
 func looksLikeConfigFile(path string) bool {
 	base := filepath.Base(path)
 	ext := filepath.Ext(base)
@@ -260,6 +391,13 @@ func extractSimpleConfigKey(line string) (string, bool) {
 			end++
 			continue
 		}
+		if b >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(line[end:])
+			if isIdentRune(r) {
+				end += size
+				continue
+			}
+		}
 		break
 	}
 	if end == 0 {
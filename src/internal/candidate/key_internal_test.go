@@ -115,6 +115,14 @@ func TestExtractKeyNamespaceAndClasses(t *testing.T) {
 		{name: "hcl simple block", text: "terraform {", want: "terraform"},
 		{name: "xml key attr", text: `<add key="ConnectionStrings__Main" value="dsn" />`, want: "ConnectionStrings__Main"},
 		{name: "xml section tag", text: `<appSettings>`, want: "appSettings"},
+		{name: "kotlin unicode fun", text: "fun 計算する(x: Int): Int {", want: "計算する"},
+		{name: "swift unicode struct", text: "struct ユーザー {", want: "ユーザー"},
+		{name: "go unicode type", text: "type 注文 struct {", want: "注文"},
+		{name: "python unicode def", text: "def 挨拶(name):", want: "挨拶"},
+		{name: "python combining mark", text: "def cafe\u0301_menu():", want: "cafe\u0301_menu"},
+		{name: "js unicode const", text: "export const 設定値 = {};", want: "設定値"},
+		{name: "js unicode class", text: "class Größe extends Base {}", want: "Größe"},
+		{name: "unicode yaml key", text: "名前: テスト", want: "名前"},
 	}
 
 	for _, tt := range tests {
@@ -126,3 +134,56 @@ func TestExtractKeyNamespaceAndClasses(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractKeyFunctionValues(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "export const foo = async () => {", want: "foo"},
+		{text: "const bar = async function() {", want: "bar"},
+		{text: "const baz = function* (items) {", want: "baz"},
+		{text: "let qux = async (req, res) => {", want: "qux"},
+		{text: "handler = async function handle(event) {", want: "handle"},
+		{text: "  load: async () => {", want: "load"},
+		{text: "export async function fetchUser(id) {", want: "fetchUser"},
+	}
+	for _, tt := range tests {
+		if got := ExtractKey(tt.text, "src/api.ts"); got != tt.want {
+			t.Fatalf("ExtractKey(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExtractKeyUnicodeConfigKey(t *testing.T) {
+	if got := ExtractKey("ユーザー名: taro", "config/users.yaml"); got != "ユーザー名" {
+		t.Fatalf("ExtractKey = %q, want ユーザー名", got)
+	}
+}
+
+func TestKeyColumn(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		key  string
+		want int
+	}{
+		{name: "modifiers", line: "    public static Foo foo(int a) {", key: "foo", want: 23},
+		{name: "receiver type prefix", line: "func (s *Server) Serve() error {", key: "Serve", want: 18},
+		{name: "tab indent", line: "\tdef load(path):", key: "load", want: 6},
+		{name: "qualified key", line: `resource "aws_s3_bucket" "logs" {`, key: "aws_s3_bucket.logs", want: 27},
+		{name: "dotted config key", line: "    level: debug", key: "log.level", want: 5},
+		{name: "multibyte prefix counts bytes", line: "const 名前 = 1; let value = 2", key: "value", want: 23},
+		{name: "multibyte key", line: "\tfunc (c *カート) 追加(x int) {", key: "追加", want: 22},
+		{name: "receiver named like method", line: "func (s *Server) Server() *Server {", key: "Server", want: 18},
+		{name: "keyword after modifiers", line: "export default class Default extends Base {", key: "Default", want: 22},
+		{name: "missing key", line: "    <template>", key: "UserCard", want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyColumn(tt.line, tt.key, 1); got != tt.want {
+				t.Fatalf("keyColumn(%q, %q) = %d, want %d", tt.line, tt.key, got, tt.want)
+			}
+		})
+	}
+}
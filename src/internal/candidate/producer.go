@@ -7,15 +7,17 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
+	"runtime"
 	"snav/internal/lang"
 	"strings"
+	"sync"
 )
 
 const producerBatchSize = 2048
 
-func StartProducer(ctx context.Context, cfg ProducerConfig) (<-chan []Candidate, <-chan error) {
+func StartProducer(ctx context.Context, cfg ProducerConfig) (<-chan []Candidate, <-chan ScanResult) {
 	out := make(chan []Candidate, 64)
-	done := make(chan error, 1)
+	done := make(chan ScanResult, 1)
 
 	go func() {
 		defer close(out)
@@ -30,6 +32,16 @@ func StartProducer(ctx context.Context, cfg ProducerConfig) (<-chan []Candidate,
 		lastMetaFile := ""
 		lastMetaConfig := false
 		lastMetaLang := LangPlain
+		lastMetaGenerated := false
+		lastMetaSkip := false
+		var skipped SkipStats
+		maxFileCandidates := cfg.MaxFileCandidates
+		if maxFileCandidates <= 0 {
+			maxFileCandidates = DefaultMaxFileCandidates
+		}
+		fileCandidates := make(map[string]int)
+		cappedFiles := make(map[string]struct{})
+		minifiedFiles := make(map[string]struct{})
 		flush := func() error {
 			if len(batch) == 0 {
 				return nil
@@ -42,16 +54,73 @@ func StartProducer(ctx context.Context, cfg ProducerConfig) (<-chan []Candidate,
 				return ctx.Err()
 			}
 		}
-		emitMatch := func(file string, line int, col int, text string) error {
+		src := newSourceFile(cfg.Root)
+		var tree *revTree
+		var overrides *lang.Overrides
+		if cfg.Rev != "" {
+			var err error
+			tree, err = openRevTree(ctx, cfg)
+			if err != nil {
+				done <- ScanResult{Root: cfg.RootName, Err: err}
+				return
+			}
+			defer tree.Close()
+			overrides = tree.overrides()
+			src.read = tree.readLines
+		} else {
+			var err error
+			overrides, err = lang.LoadOverrides(cfg.Root)
+			if err != nil {
+				done <- ScanResult{Root: cfg.RootName, Err: err}
+				return
+			}
+		}
+		emit := func(cand Candidate) error {
+			n := fileCandidates[cand.File]
+			if n >= maxFileCandidates {
+				if _, seen := cappedFiles[cand.File]; !seen {
+					cappedFiles[cand.File] = struct{}{}
+					skipped.Capped++
+				}
+				return nil
+			}
+			fileCandidates[cand.File] = n + 1
+			cand.Text = truncateCandidateText(cand.Text)
+			cand.Signature = truncateCandidateText(cand.Signature)
+			cand.Root = cfg.RootName
+			id++
+			cand.ID = id
+			batch = append(batch, cand)
+			if len(batch) < cap(batch) {
+				return nil
+			}
+			return flush()
+		}
+		emitMatch := func(file string, line int, col int, raw string) error {
 			if file != lastMetaFile {
 				lastMetaFile = file
 				lastMetaConfig = looksLikeConfigFile(file)
-				lastMetaLang = lang.Detect(file)
+				src.reset(file)
+				// The preview detects languages with the same DetectContent
+				// rules, so modelines apply to every file.
+				if id, ok := overrides.Lookup(file); ok {
+					lastMetaLang = id
+				} else {
+					lastMetaLang = lang.DetectContent(file, src.Lines())
+				}
+				lastMetaGenerated = isGeneratedFile(file, overrides, src)
+				lastMetaSkip = isMinifiedFile(file, lastMetaLang, src)
+				if _, seen := minifiedFiles[file]; lastMetaSkip && !seen {
+					minifiedFiles[file] = struct{}{}
+					skipped.Minified++
+				}
+			}
+			if lastMetaSkip || lastMetaGenerated && cfg.ExcludeGenerated {
+				return nil
 			}
 
-			id++
+			text := strings.TrimLeft(raw, " \t")
 			cand := Candidate{
-				ID:            id,
 				File:          file,
 				Line:          line,
 				Col:           col,
@@ -59,35 +128,119 @@ func StartProducer(ctx context.Context, cfg ProducerConfig) (<-chan []Candidate,
 				Key:           extractKeyWithConfigHint(text, file, lastMetaConfig),
 				LangID:        lastMetaLang,
 				SemanticScore: computeSemanticScore(text),
+				Generated:     lastMetaGenerated,
+				Dependency:    cfg.Dependency != "",
 			}
-			batch = append(batch, cand)
-			if len(batch) < cap(batch) {
+			if src.wasEmitted(cand) {
 				return nil
 			}
-			return flush()
-		}
 
-		if err := runRGPass(ctx, cfg.Root, rgArgs(cfg, pattern), emitMatch); err != nil {
-			done <- fmt.Errorf("search declarations: %w", err)
-			return
+			expanded, ok := expandCandidate(cand, src, cfg)
+			if !ok {
+				cand.Col = keyColumn(raw, cand.Key, cand.Col)
+				cand.Doc = src.docComment(cand)
+				cand.Signature = src.signature(cand)
+				cand.Test = src.isTestCandidate(cand)
+				return emit(cand)
+			}
+			for _, next := range expanded {
+				if src.wasEmitted(next) {
+					continue
+				}
+				src.markEmitted(next)
+				next.Generated = lastMetaGenerated
+				next.Dependency = cfg.Dependency != ""
+				next.Test = src.isTestCandidate(next)
+				if next.Cell == 0 {
+					lineText := raw
+					if next.Line != line {
+						lineText, _ = sourceLineAt(src.Lines(), next.Line)
+					}
+					next.Col = keyColumn(lineText, next.Key, next.Col)
+					next.Doc = src.docComment(next)
+					next.Signature = src.signature(next)
+				}
+				if err := emit(next); err != nil {
+					return err
+				}
+			}
+			return nil
 		}
-		if shouldIncludeConfigPass(pattern) {
-			if err := runRGPass(ctx, cfg.Root, rgConfigArgs(cfg), emitMatch); err != nil {
-				done <- fmt.Errorf("search config entries: %w", err)
+
+		passes := rgPasses(cfg, pattern)
+		oversized := make(chan int, 1)
+		go func() {
+			if tree != nil {
+				oversized <- tree.oversizedFiles(passes)
+				return
+			}
+			oversized <- countOversizedFiles(ctx, cfg, passes)
+		}()
+		for _, pass := range passes {
+			var err error
+			if tree != nil {
+				err = tree.search(ctx, pass, emitMatch)
+			} else {
+				err = runRGPass(ctx, cfg.Root, rgGlobArgs(cfg, pass.globs, pass.pattern), emitMatch)
+			}
+			if err != nil {
+				done <- ScanResult{Root: cfg.RootName, Err: fmt.Errorf("search %s: %w", pass.name, err)}
 				return
 			}
 		}
 		if err := flush(); err != nil {
-			done <- err
+			done <- ScanResult{Root: cfg.RootName, Err: err}
 			return
 		}
 
-		done <- nil
+		skipped.Oversized = <-oversized
+		done <- ScanResult{Root: cfg.RootName, Skipped: skipped}
 	}()
 
 	return out, done
 }
 
+// StartProducers scans several roots in parallel, a few at a time. Batches
+// from all roots share the returned channel; each root reports its
+// ScanResult once all of its batches have been sent, and the result channel
+// closes after the last.
+func StartProducers(ctx context.Context, cfgs []ProducerConfig) (<-chan []Candidate, <-chan ScanResult) {
+	if len(cfgs) == 1 {
+		return StartProducer(ctx, cfgs[0])
+	}
+
+	out := make(chan []Candidate, 64)
+	done := make(chan ScanResult, len(cfgs))
+	slots := make(chan struct{}, max(2, runtime.GOMAXPROCS(0)))
+	var wg sync.WaitGroup
+	for _, cfg := range cfgs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			rootOut, rootDone := StartProducer(ctx, cfg)
+			for batch := range rootOut {
+				select {
+				case out <- batch:
+				case <-ctx.Done():
+				}
+			}
+			result, ok := <-rootDone
+			if !ok {
+				result = ScanResult{Root: cfg.RootName, Err: ctx.Err()}
+			}
+			done <- result
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+		close(done)
+	}()
+	return out, done
+}
+
 func runRGPass(ctx context.Context, root string, args []string, onMatch func(file string, line int, col int, text string) error) error {
 	cmd := exec.CommandContext(ctx, "rg", args...)
 	cmd.Dir = root
@@ -166,6 +319,32 @@ func runRGPass(ctx context.Context, root string, args []string, onMatch func(fil
 	return nil
 }
 
+// listRGFiles lists the files rg walks in root with args, relative to root.
+func listRGFiles(ctx context.Context, root string, args []string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "rg", append([]string{"--files", "--null"}, args...)...)
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("rg failed: %s", msg)
+		}
+		return nil, fmt.Errorf("rg failed: %w", err)
+	}
+	var files []string
+	for _, file := range bytes.Split(data, []byte{0}) {
+		if file := strings.TrimSpace(string(file)); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
 func isNoFilesSearchedMessage(msg string) bool {
 	return strings.Contains(msg, "No files were searched")
 }
@@ -174,23 +353,69 @@ func shouldIncludeConfigPass(pattern string) bool {
 	return strings.TrimSpace(pattern) == DefaultRGPattern
 }
 
+// rgPass is one search over the files matching globs, or all files when
+// globs is empty.
+type rgPass struct {
+	name    string
+	globs   []string
+	pattern string
+}
+
+func rgPasses(cfg ProducerConfig, pattern string) []rgPass {
+	passes := []rgPass{{name: "declarations", globs: declarationGlobs(pattern), pattern: declarationPattern(pattern)}}
+	if !shouldIncludeConfigPass(pattern) {
+		return passes
+	}
+	languages := rgPass{name: "language declarations", globs: languageDeclarationGlobs, pattern: languageDeclarationPattern}
+	if cfg.Dependency != "" {
+		return append(passes, languages)
+	}
+	passes = append(passes,
+		rgPass{name: "config entries", globs: configIncludeGlobs, pattern: DefaultRGConfigPattern},
+		rgPass{name: "python assignments", globs: pythonIncludeGlobs, pattern: pythonAssignmentPattern},
+		rgPass{name: "doc headings", globs: docIncludeGlobs, pattern: docHeadingPattern},
+		rgPass{name: "schemas", globs: schemaIncludeGlobs, pattern: schemaPattern},
+		rgPass{name: "openapi paths", globs: openAPIIncludeGlobs, pattern: openAPIPathPattern},
+		rgPass{name: "tasks", globs: taskIncludeGlobs, pattern: taskPattern},
+		rgPass{name: "components", globs: componentIncludeGlobs, pattern: componentPattern},
+		rgPass{name: "notebooks", globs: notebookIncludeGlobs, pattern: notebookPattern},
+		languages,
+	)
+	return passes
+}
+
 func rgArgs(cfg ProducerConfig, pattern string) []string {
-	args := rgBaseArgs(cfg)
+	return rgGlobArgs(cfg, declarationGlobs(pattern), declarationPattern(pattern))
+}
+
+// declarationPattern extends the default pattern with the Objective-C
+// declaration forms, as headers are only searched by the declarations pass.
+func declarationPattern(pattern string) string {
 	if pattern == DefaultRGPattern {
-		for _, glob := range declarationIncludeGlobs {
-			args = append(args, "--glob", glob)
-		}
+		return pattern + "|" + objcHeaderPattern
 	}
-	args = append(args, pattern)
-	return args
+	return pattern
+}
+
+// declarationGlobs limits the default pattern to source files; a custom
+// pattern searches every file.
+func declarationGlobs(pattern string) []string {
+	if pattern == DefaultRGPattern {
+		return declarationIncludeGlobs
+	}
+	return nil
 }
 
 func rgConfigArgs(cfg ProducerConfig) []string {
+	return rgGlobArgs(cfg, configIncludeGlobs, DefaultRGConfigPattern)
+}
+
+func rgGlobArgs(cfg ProducerConfig, globs []string, pattern string) []string {
 	args := rgBaseArgs(cfg)
-	for _, glob := range configIncludeGlobs {
+	for _, glob := range globs {
 		args = append(args, "--glob", glob)
 	}
-	args = append(args, DefaultRGConfigPattern)
+	args = append(args, pattern)
 	return args
 }
 
@@ -198,19 +423,31 @@ func rgBaseArgs(cfg ProducerConfig) []string {
 	args := []string{
 		"--vimgrep",
 		"--null",
-		"--trim",
 		"--color", "never",
 		"--no-heading",
 		"--smart-case",
 	}
+	return append(args, rgFileArgs(cfg, true)...)
+}
+
+// rgFileArgs selects the files rg walks. Without sizeLimit, files over
+// --max-filesize are listed too.
+func rgFileArgs(cfg ProducerConfig, sizeLimit bool) []string {
+	var args []string
 	if cfg.NoIgnore {
 		args = append(args, "--no-ignore")
 	}
+	if cfg.Hidden {
+		args = append(args, "--hidden", "--glob", "!.git")
+	}
+	if sizeLimit {
+		args = append(args, "--max-filesize", maxFileSize(cfg))
+	}
 	for _, glob := range cfg.Excludes {
 		args = append(args, "--glob", "!"+glob)
 	}
-	if cfg.ExcludeTests {
-		for _, glob := range testExcludeGlobs {
+	if cfg.ExcludeGenerated {
+		for _, glob := range generatedFileGlobs {
 			args = append(args, "--glob", "!"+glob)
 		}
 	}
@@ -2,6 +2,7 @@ package candidate
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -44,8 +45,8 @@ func TestParseRGVimgrepLineRejectsMalformedInput(t *testing.T) {
 	}
 }
 
-func TestTestExcludeGlobsAreSpecific(t *testing.T) {
-	for _, glob := range testExcludeGlobs {
+func TestTestFileGlobsAreSpecific(t *testing.T) {
+	for _, glob := range testFileGlobs {
 		if strings.Contains(glob, "*test*") || strings.Contains(glob, "*spec*") {
 			t.Fatalf("glob %q is too broad and can hide non-test files", glob)
 		}
@@ -60,15 +61,15 @@ func TestRGArgs(t *testing.T) {
 		want := []string{
 			"--vimgrep",
 			"--null",
-			"--trim",
 			"--color", "never",
 			"--no-heading",
 			"--smart-case",
+			"--max-filesize", "1M",
 		}
 		for _, glob := range declarationIncludeGlobs {
 			want = append(want, "--glob", glob)
 		}
-		want = append(want, DefaultRGPattern)
+		want = append(want, DefaultRGPattern+"|"+objcHeaderPattern)
 
 		if !reflect.DeepEqual(got, want) {
 			t.Fatalf("rgArgs = %#v, want %#v", got, want)
@@ -83,10 +84,10 @@ func TestRGArgs(t *testing.T) {
 		want := []string{
 			"--vimgrep",
 			"--null",
-			"--trim",
 			"--color", "never",
 			"--no-heading",
 			"--smart-case",
+			"--max-filesize", "1M",
 			"todo",
 		}
 		if !reflect.DeepEqual(got, want) {
@@ -96,42 +97,58 @@ func TestRGArgs(t *testing.T) {
 
 	t.Run("flags", func(t *testing.T) {
 		cfg := ProducerConfig{
-			NoIgnore:     true,
-			Excludes:     []string{"a/**", "b/**"},
-			ExcludeTests: true,
-			Pattern:      "func",
+			NoIgnore:         true,
+			Excludes:         []string{"a/**", "b/**"},
+			ExcludeGenerated: true,
+			Pattern:          "func",
 		}
 		got := rgArgs(cfg, "func")
 		want := []string{
 			"--vimgrep",
 			"--null",
-			"--trim",
 			"--color", "never",
 			"--no-heading",
 			"--smart-case",
 			"--no-ignore",
+			"--max-filesize", "1M",
 			"--glob", "!a/**",
 			"--glob", "!b/**",
-			"--glob", "!test/**",
-			"--glob", "!tests/**",
-			"--glob", "!__tests__/**",
-			"--glob", "!spec/**",
-			"--glob", "!specs/**",
-			"--glob", "!**/test/**",
-			"--glob", "!**/tests/**",
-			"--glob", "!**/__tests__/**",
-			"--glob", "!**/spec/**",
-			"--glob", "!**/specs/**",
-			"--glob", "!*_test.*",
-			"--glob", "!*_spec.*",
-			"--glob", "!*.test.*",
-			"--glob", "!*.spec.*",
-			"--glob", "!test_*.py",
-			"--glob", "!**/*_test.*",
-			"--glob", "!**/*_spec.*",
-			"--glob", "!**/*.test.*",
-			"--glob", "!**/*.spec.*",
-			"--glob", "!**/test_*.py",
+			"--glob", "!zz_generated*",
+			"--glob", "!*.pb.go",
+			"--glob", "!*.pb.gw.go",
+			"--glob", "!*.pb.h",
+			"--glob", "!*.pb.cc",
+			"--glob", "!*_pb2.py",
+			"--glob", "!*_pb2.pyi",
+			"--glob", "!*_pb2_grpc.py",
+			"--glob", "!*_pb.js",
+			"--glob", "!*_pb.d.ts",
+			"--glob", "!*.g.dart",
+			"--glob", "!*.freezed.dart",
+			"--glob", "!*.g.cs",
+			"--glob", "!*.designer.cs",
+			"--glob", "!*.generated.*",
+			"--glob", "!*_generated.*",
+			"--glob", "!mock_*.go",
+			"--glob", "!*_mock.go",
+			"func",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("rgArgs = %#v, want %#v", got, want)
+		}
+	})
+
+	t.Run("hidden", func(t *testing.T) {
+		got := rgArgs(ProducerConfig{Hidden: true}, "func")
+		want := []string{
+			"--vimgrep",
+			"--null",
+			"--color", "never",
+			"--no-heading",
+			"--smart-case",
+			"--hidden",
+			"--glob", "!.git",
+			"--max-filesize", "1M",
 			"func",
 		}
 		if !reflect.DeepEqual(got, want) {
@@ -147,10 +164,10 @@ func TestRGConfigArgs(t *testing.T) {
 		want := []string{
 			"--vimgrep",
 			"--null",
-			"--trim",
 			"--color", "never",
 			"--no-heading",
 			"--smart-case",
+			"--max-filesize", "1M",
 			"--glob", "*.json",
 			"--glob", "*.jsonc",
 			"--glob", "*.json5",
@@ -183,41 +200,39 @@ func TestRGConfigArgs(t *testing.T) {
 
 	t.Run("flags", func(t *testing.T) {
 		cfg := ProducerConfig{
-			NoIgnore:     true,
-			Excludes:     []string{"a/**", "b/**"},
-			ExcludeTests: true,
+			NoIgnore:         true,
+			Excludes:         []string{"a/**", "b/**"},
+			ExcludeGenerated: true,
 		}
 		got := rgConfigArgs(cfg)
 		want := []string{
 			"--vimgrep",
 			"--null",
-			"--trim",
 			"--color", "never",
 			"--no-heading",
 			"--smart-case",
 			"--no-ignore",
+			"--max-filesize", "1M",
 			"--glob", "!a/**",
 			"--glob", "!b/**",
-			"--glob", "!test/**",
-			"--glob", "!tests/**",
-			"--glob", "!__tests__/**",
-			"--glob", "!spec/**",
-			"--glob", "!specs/**",
-			"--glob", "!**/test/**",
-			"--glob", "!**/tests/**",
-			"--glob", "!**/__tests__/**",
-			"--glob", "!**/spec/**",
-			"--glob", "!**/specs/**",
-			"--glob", "!*_test.*",
-			"--glob", "!*_spec.*",
-			"--glob", "!*.test.*",
-			"--glob", "!*.spec.*",
-			"--glob", "!test_*.py",
-			"--glob", "!**/*_test.*",
-			"--glob", "!**/*_spec.*",
-			"--glob", "!**/*.test.*",
-			"--glob", "!**/*.spec.*",
-			"--glob", "!**/test_*.py",
+			"--glob", "!zz_generated*",
+			"--glob", "!*.pb.go",
+			"--glob", "!*.pb.gw.go",
+			"--glob", "!*.pb.h",
+			"--glob", "!*.pb.cc",
+			"--glob", "!*_pb2.py",
+			"--glob", "!*_pb2.pyi",
+			"--glob", "!*_pb2_grpc.py",
+			"--glob", "!*_pb.js",
+			"--glob", "!*_pb.d.ts",
+			"--glob", "!*.g.dart",
+			"--glob", "!*.freezed.dart",
+			"--glob", "!*.g.cs",
+			"--glob", "!*.designer.cs",
+			"--glob", "!*.generated.*",
+			"--glob", "!*_generated.*",
+			"--glob", "!mock_*.go",
+			"--glob", "!*_mock.go",
 			"--glob", "*.json",
 			"--glob", "*.jsonc",
 			"--glob", "*.json5",
@@ -273,7 +288,7 @@ final class ServiceManager {
 		got = append(got, batch...)
 	}
 
-	if err := <-done; err != nil {
+	if err := (<-done).Err; err != nil {
 		t.Fatalf("StartProducer error: %v", err)
 	}
 
@@ -309,3 +324,306 @@ func TestShouldIncludeConfigPass(t *testing.T) {
 		t.Fatalf("custom pattern should not include config pass")
 	}
 }
+
+func TestRGPassesAddsPythonAssignmentsForDefaultPattern(t *testing.T) {
+	names := func(passes []rgPass) []string {
+		out := make([]string, 0, len(passes))
+		for _, pass := range passes {
+			out = append(out, pass.name)
+		}
+		return out
+	}
+
+	got := names(rgPasses(ProducerConfig{}, DefaultRGPattern))
+	want := []string{"declarations", "config entries", "python assignments", "doc headings", "schemas", "openapi paths", "tasks", "components", "notebooks", "language declarations"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("passes = %v, want %v", got, want)
+	}
+	if got := names(rgPasses(ProducerConfig{}, "^foo$")); len(got) != 1 {
+		t.Fatalf("custom pattern passes = %v, want declarations only", got)
+	}
+}
+
+func TestStartProducerSearchesLanguageDeclarationsInOnePass(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"init.lua":        "local function setup(opts)\nM.on_attach = function(client)\nclass Widget {\n",
+		"lib/main.dart":   "class App {\ndefmodule Search do\n",
+		"include/Store.h": "#import <Foundation/Foundation.h>\n@interface Store : NSObject\n- (void)reload;\nstruct store_state { int n; };\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	out, done := StartProducer(context.Background(), ProducerConfig{Root: root})
+	got := map[string][]string{}
+	for batch := range out {
+		for _, cand := range batch {
+			got[cand.File] = append(got[cand.File], cand.Key)
+		}
+	}
+	if err := (<-done).Err; err != nil {
+		t.Fatalf("StartProducer error: %v", err)
+	}
+
+	want := map[string][]string{
+		"init.lua":        {"setup", "on_attach"},
+		"lib/main.dart":   {"App"},
+		"include/Store.h": {"Store", "reload", "store_state"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys = %v, want %v", got, want)
+	}
+}
+
+func TestStartProducerDetectsHeaderLanguageFromContent(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitattributes":     "legacy/*.h linguist-language=C++\n",
+		"include/widget.h":   "namespace ui {\nclass Widget {};\n}\n",
+		"include/point.h":    "struct point { int x; };\n",
+		"include/Store.h":    "#import <Foundation/Foundation.h>\nstruct store_state { int n; };\n",
+		"legacy/compat.h":    "struct compat { int x; };\n",
+		"include/defaults.h": "// vim: set ft=cpp:\nstruct defaults { int x; };\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	out, done := StartProducer(context.Background(), ProducerConfig{Root: root})
+	got := map[string]LangID{}
+	for batch := range out {
+		for _, cand := range batch {
+			got[cand.File] = cand.LangID
+		}
+	}
+	if err := (<-done).Err; err != nil {
+		t.Fatalf("StartProducer error: %v", err)
+	}
+
+	want := map[string]LangID{
+		"include/widget.h":   LangCPP,
+		"include/point.h":    LangC,
+		"include/Store.h":    LangObjC,
+		"legacy/compat.h":    LangCPP,
+		"include/defaults.h": LangCPP,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("languages = %v, want %v", got, want)
+	}
+}
+
+func TestStartProducerMarksAndExcludesGeneratedFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"api/user.pb.go":  "package api\n\ntype User struct{}\n",
+		"store/mock.go":   "// Code generated by MockGen. DO NOT EDIT.\n\npackage store\n\ntype MockStore struct{}\n",
+		"store/store.go":  "package store\n\ntype Store struct{}\n",
+		"vendored/lib.go": "package vendored\n\ntype Lib struct{}\n",
+		".gitattributes":  "vendored/** linguist-generated\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	collect := func(cfg ProducerConfig) map[string]bool {
+		out, done := StartProducer(context.Background(), cfg)
+		got := map[string]bool{}
+		for batch := range out {
+			for _, cand := range batch {
+				got[cand.Key] = cand.Generated
+			}
+		}
+		if err := (<-done).Err; err != nil {
+			t.Fatalf("StartProducer error: %v", err)
+		}
+		return got
+	}
+
+	want := map[string]bool{"User": true, "MockStore": true, "Store": false, "Lib": true}
+	if got := collect(ProducerConfig{Root: root}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("generated = %v, want %v", got, want)
+	}
+	want = map[string]bool{"Store": false}
+	if got := collect(ProducerConfig{Root: root, ExcludeGenerated: true}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("with ExcludeGenerated = %v, want %v", got, want)
+	}
+}
+
+func TestStartProducerSkipsMinifiedFilesAndCapsCandidates(t *testing.T) {
+	root := t.TempDir()
+	var many strings.Builder
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(&many, "func F%d() {}\n", i)
+	}
+	files := map[string]string{
+		"static/app.min.js": "function bundled() {}\n",
+		"pkg/many.go":       "package pkg\n\n" + many.String(),
+		"pkg/one.go":        "package pkg\n\nfunc One() {}\n",
+		"pkg/exact.go":      "package pkg\n\nfunc A() {}\nfunc B() {}\nfunc C() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	out, done := StartProducer(context.Background(), ProducerConfig{Root: root, MaxFileCandidates: 3})
+	perFile := map[string]int{}
+	for batch := range out {
+		for _, cand := range batch {
+			perFile[cand.File]++
+		}
+	}
+	result := <-done
+	if result.Err != nil {
+		t.Fatalf("StartProducer error: %v", result.Err)
+	}
+
+	want := map[string]int{"pkg/many.go": 3, "pkg/one.go": 1, "pkg/exact.go": 3}
+	if !reflect.DeepEqual(perFile, want) {
+		t.Fatalf("candidates per file = %v, want %v", perFile, want)
+	}
+	if want := (SkipStats{Minified: 1, Capped: 1}); result.Skipped != want {
+		t.Fatalf("skipped = %+v, want %+v", result.Skipped, want)
+	}
+}
+
+func TestStartProducerCountsOversizedFiles(t *testing.T) {
+	root := t.TempDir()
+	big := "package pkg\n\nfunc Big() {}\n" + strings.Repeat("// padding\n", 200)
+	files := map[string]string{
+		"pkg/big.go":   big,
+		"pkg/small.go": "package pkg\n\nfunc Small() {}\n",
+		"assets/a.bin": big,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	out, done := StartProducer(context.Background(), ProducerConfig{Root: root, MaxFileSize: "1K"})
+	for range out {
+	}
+	result := <-done
+	if result.Err != nil {
+		t.Fatalf("StartProducer error: %v", result.Err)
+	}
+	if result.Skipped.Oversized != 1 {
+		t.Fatalf("oversized = %d, want 1", result.Skipped.Oversized)
+	}
+}
+
+func TestStartProducersLabelsCandidatesPerRoot(t *testing.T) {
+	api := t.TempDir()
+	web := t.TempDir()
+	if err := os.WriteFile(filepath.Join(api, "server.go"), []byte("package api\n\nfunc Serve() {}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(web, "app.ts"), []byte("export function render() {}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	out, done := StartProducers(context.Background(), []ProducerConfig{
+		{Root: api, RootName: "api"},
+		{Root: web, RootName: "web"},
+	})
+	got := map[string]string{}
+	for batch := range out {
+		for _, cand := range batch {
+			got[cand.Key] = cand.Root + ":" + cand.File
+		}
+	}
+	results := map[string]bool{}
+	for result := range done {
+		if result.Err != nil {
+			t.Fatalf("StartProducers error for %s: %v", result.Root, result.Err)
+		}
+		results[result.Root] = true
+	}
+
+	want := map[string]string{"Serve": "api:server.go", "render": "web:app.ts"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("candidates = %v, want %v", got, want)
+	}
+	if !results["api"] || !results["web"] || len(results) != 2 {
+		t.Fatalf("results = %v, want one per root", results)
+	}
+}
+
+func TestStartProducersReportsAttributeErrorsForTheirRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".gitattributes"), 0o755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+
+	out, done := StartProducers(context.Background(), []ProducerConfig{{Root: root, RootName: "api"}})
+	for range out {
+	}
+	var results []ScanResult
+	for result := range done {
+		results = append(results, result)
+	}
+	if len(results) != 1 || results[0].Err == nil || results[0].Root != "api" {
+		t.Fatalf("results = %+v, want one error for api", results)
+	}
+}
+
+func TestStartProducerIndexesOnlyDeclarationsFromDependencies(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"command.go":   "package cobra\n\ntype Command struct{}\n",
+		"README.md":    "# Cobra\n",
+		"config.yaml":  "name: cobra\n",
+		"doc/guide.md": "## Usage\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	cfg := ProducerConfig{Root: root, RootName: "github.com/spf13/cobra", Dependency: "github.com/spf13/cobra@v1.8.0", NoIgnore: true}
+	out, done := StartProducer(context.Background(), cfg)
+	var got []Candidate
+	for batch := range out {
+		got = append(got, batch...)
+	}
+	if err := (<-done).Err; err != nil {
+		t.Fatalf("StartProducer error: %v", err)
+	}
+	if len(got) != 1 || got[0].Key != "Command" || !got[0].Dependency || got[0].Root != "github.com/spf13/cobra" {
+		t.Fatalf("candidates = %#v, want only the Command dependency declaration", got)
+	}
+}
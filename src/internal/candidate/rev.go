@@ -0,0 +1,241 @@
+package candidate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"snav/internal/gitrev"
+	"snav/internal/lang"
+	"snav/internal/readfile"
+)
+
+// revTree is the tree of a git commit, searched in place of rg over the
+// working tree. It holds the tracked files only, so ignore files do not
+// apply; hidden files, excludes and the file size limit do.
+type revTree struct {
+	files  []gitrev.File
+	byPath map[string]gitrev.File
+	// oversized are the paths left out for being over the size limit.
+	oversized []string
+	reader    *gitrev.Reader
+
+	// path and data are the last blob read, which the emitted candidates of
+	// a match usually read again.
+	path string
+	data []byte
+}
+
+func openRevTree(ctx context.Context, cfg ProducerConfig) (*revTree, error) {
+	limit, err := parseFileSize(maxFileSize(cfg))
+	if err != nil {
+		return nil, err
+	}
+	files, err := gitrev.ListFiles(ctx, cfg.Root, cfg.Rev)
+	if err != nil {
+		return nil, err
+	}
+
+	excludes := cfg.Excludes
+	if cfg.ExcludeGenerated {
+		excludes = append(append([]string(nil), excludes...), generatedFileGlobs...)
+	}
+	t := &revTree{byPath: make(map[string]gitrev.File, len(files))}
+	for _, f := range files {
+		t.byPath[f.Path] = f
+		if !cfg.Hidden && isHiddenPath(f.Path) || excludedPath(excludes, f.Path) {
+			continue
+		}
+		if f.Size > limit {
+			t.oversized = append(t.oversized, f.Path)
+			continue
+		}
+		t.files = append(t.files, f)
+	}
+
+	t.reader, err = gitrev.NewReader(ctx, cfg.Root)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// oversizedFiles counts the files left out for their size that passes
+// would search.
+func (t *revTree) oversizedFiles(passes []rgPass) int {
+	n := 0
+	for _, path := range t.oversized {
+		if passesSearch(passes, path) {
+			n++
+		}
+	}
+	return n
+}
+
+func (t *revTree) Close() {
+	_ = t.reader.Close()
+}
+
+// overrides parses the commit's root .gitattributes.
+func (t *revTree) overrides() *lang.Overrides {
+	data, err := t.readPath(".gitattributes")
+	if err != nil {
+		return lang.ParseOverrides("")
+	}
+	return lang.ParseOverrides(string(data))
+}
+
+func (t *revTree) readLines(path string) ([]string, error) {
+	data, err := t.readPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return readfile.SplitLinesNormalized(data), nil
+}
+
+func (t *revTree) readPath(path string) ([]byte, error) {
+	if path == t.path {
+		return t.data, nil
+	}
+	f, ok := t.byPath[path]
+	if !ok {
+		return nil, fmt.Errorf("%s is not in the revision", path)
+	}
+	data, err := t.reader.Read(f.Object)
+	if err != nil {
+		return nil, err
+	}
+	t.path, t.data = path, data
+	return data, nil
+}
+
+// search runs a pass over the commit's files, reporting matches the way
+// rg --vimgrep does: once per match with its 1-based byte column.
+func (t *revTree) search(ctx context.Context, pass rgPass, onMatch func(file string, line int, col int, text string) error) error {
+	re, err := compileSearchPattern(pass.pattern)
+	if err != nil {
+		return err
+	}
+	for _, f := range t.files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(pass.globs) > 0 && !pathMatchesGlobs(pass.globs, f.Path) {
+			continue
+		}
+		data, err := t.readPath(f.Path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			continue
+		}
+
+		text := strings.TrimSuffix(string(data), "\n")
+		for i, line := range strings.Split(text, "\n") {
+			line = strings.TrimSuffix(line, "\r")
+			for _, loc := range re.FindAllStringIndex(line, -1) {
+				if err := onMatch(f.Path, i+1, loc[0]+1, line); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// compileSearchPattern compiles a pattern with rg's --smart-case: it is
+// case-insensitive unless it has an uppercase literal.
+func compileSearchPattern(pattern string) (*regexp.Regexp, error) {
+	if !hasUppercaseLiteral(pattern) {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("compile pattern: %w", err)
+	}
+	return re, nil
+}
+
+// hasUppercaseLiteral reports whether a pattern has an uppercase letter
+// outside escapes such as \S and \p{Lu}.
+func hasUppercaseLiteral(pattern string) bool {
+	rs := []rune(pattern)
+	for i := 0; i < len(rs); i++ {
+		if rs[i] != '\\' {
+			if unicode.IsUpper(rs[i]) {
+				return true
+			}
+			continue
+		}
+		i++
+		if i+1 < len(rs) && (rs[i] == 'p' || rs[i] == 'P') {
+			i++
+			if rs[i] == '{' {
+				for i < len(rs) && rs[i] != '}' {
+					i++
+				}
+			}
+		}
+	}
+	return false
+}
+
+func pathMatchesGlobs(globs []string, rel string) bool {
+	for _, glob := range globs {
+		if lang.MatchPattern(glob, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludedPath reports whether an exclude glob matches rel or one of its
+// directories, as rg's !glob does.
+func excludedPath(globs []string, rel string) bool {
+	for p := rel; p != "." && p != "/"; p = path.Dir(p) {
+		if pathMatchesGlobs(globs, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHiddenPath(rel string) bool {
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFileSize parses an rg --max-filesize value: a number of bytes with
+// an optional K, M or G suffix.
+func parseFileSize(s string) (int64, error) {
+	digits := strings.TrimSpace(s)
+	mult := int64(1)
+	if n := len(digits); n > 0 {
+		switch digits[n-1] {
+		case 'K', 'k':
+			mult = 1 << 10
+		case 'M', 'm':
+			mult = 1 << 20
+		case 'G', 'g':
+			mult = 1 << 30
+		}
+		if mult > 1 {
+			digits = digits[:n-1]
+		}
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid max file size %q", s)
+	}
+	return n * mult, nil
+}
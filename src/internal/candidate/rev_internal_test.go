@@ -0,0 +1,99 @@
+package candidate
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStartProducerIndexesRevision(t *testing.T) {
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=snav", "-c", "user.email=snav@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name string, content string) {
+		t.Helper()
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	git("init", "-q")
+	write("server.go", "package app\n\n// Serve starts the server.\nfunc Serve() {}\n")
+	write(".github/config.yaml", "name: ci\n")
+	write("README.md", "# Overview\n")
+	write("big.go", "package app\n\nfunc Big() {}\n"+strings.Repeat("// padding\n", 200))
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	git("tag", "v1")
+	write("server.go", "package app\n\nfunc Listen() {}\n")
+	write("client.go", "package app\n\nfunc Dial() {}\n")
+
+	out, done := StartProducer(context.Background(), ProducerConfig{Root: repo, Pattern: DefaultRGPattern, Rev: "v1", MaxFileSize: "1K"})
+	keys := map[string]Candidate{}
+	for batch := range out {
+		for _, cand := range batch {
+			keys[cand.Key] = cand
+		}
+	}
+	result := <-done
+	if result.Err != nil {
+		t.Fatalf("StartProducer error: %v", result.Err)
+	}
+	if result.Skipped.Oversized != 1 {
+		t.Fatalf("oversized = %d, want 1", result.Skipped.Oversized)
+	}
+
+	serve, ok := keys["Serve"]
+	if !ok || serve.File != "server.go" || serve.Line != 4 || serve.Doc != "Serve starts the server." {
+		t.Fatalf("Serve = %#v, want it read from the commit with its doc comment", serve)
+	}
+	if _, ok := keys["Overview"]; !ok {
+		t.Fatalf("expected the doc heading pass to run over the commit, got %v", keys)
+	}
+	for _, key := range []string{"Listen", "Dial", "name", "Big"} {
+		if _, ok := keys[key]; ok {
+			t.Fatalf("unexpected %s from the working tree or a hidden file", key)
+		}
+	}
+}
+
+func TestHasUppercaseLiteral(t *testing.T) {
+	tests := map[string]bool{
+		`^func\s+\w+`:        false,
+		`^\p{Lu}\S*`:         false,
+		`\PL+`:               false,
+		`^type [A-Z]`:        true,
+		`^(?:def|Class)\b`:   true,
+		`^[a-z_]+\s*=\s*\d+`: false,
+	}
+	for pattern, want := range tests {
+		if got := hasUppercaseLiteral(pattern); got != want {
+			t.Errorf("hasUppercaseLiteral(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
+
+func TestParseFileSize(t *testing.T) {
+	tests := map[string]int64{"512": 512, "1K": 1 << 10, "2M": 2 << 20, "1g": 1 << 30}
+	for in, want := range tests {
+		if got, err := parseFileSize(in); err != nil || got != want {
+			t.Errorf("parseFileSize(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+	if _, err := parseFileSize("big"); err == nil {
+		t.Errorf("parseFileSize(%q) should fail", "big")
+	}
+}
@@ -9,6 +9,7 @@ const (
 	semanticMethodScore      int16 = 300
 	semanticConstScore       int16 = 260
 	semanticModuleScore      int16 = 220
+	semanticDocScore         int16 = 200
 	semanticFieldScore       int16 = 170
 	semanticLocalScore       int16 = 110
 	semanticParamScore       int16 = 80
@@ -29,21 +30,23 @@ func candidateSemanticScore(cand *Candidate) int16 {
 }
 
 func computeSemanticScore(text string) int16 {
+	base, visibility := semanticScoreParts(text)
+	return base + visibility
+}
+
+// semanticScoreParts splits the semantic score of a declaration line into its
+// declaration kind score and its visibility adjustment.
+func semanticScoreParts(text string) (int16, int16) {
 	lower := strings.ToLower(strings.TrimSpace(text))
 	if lower == "" {
-		return 0
+		return 0, 0
 	}
 
 	keyword, rest, visibility := classifyDeclaration(lower)
 	if keyword == "" {
-		return visibility
-	}
-
-	base := semanticScoreForDeclaration(keyword, rest)
-	if base == 0 {
-		return visibility
+		return 0, visibility
 	}
-	return base + visibility
+	return semanticScoreForDeclaration(keyword, rest), visibility
 }
 
 func classifyDeclaration(lower string) (string, string, int16) {
@@ -110,7 +113,12 @@ func semanticScoreForDeclaration(keyword string, rest string) int16 {
 		return semanticFunctionScore
 	case "field", "property":
 		return semanticFieldScore
-	case "let", "var", "val":
+	case "let", "var":
+		if rhs, ok := bindingValue(rest); ok && isFunctionValue(rhs) {
+			return semanticFunctionScore
+		}
+		return semanticLocalScore
+	case "val":
 		return semanticLocalScore
 	case "param", "parameter":
 		return semanticParamScore
@@ -120,17 +128,14 @@ func semanticScoreForDeclaration(keyword string, rest string) int16 {
 }
 
 func semanticConstLikeScore(rest string) int16 {
-	name, after := leadingToken(rest)
-	if name == "" {
+	rhs, ok := bindingValue(rest)
+	if !ok {
 		return semanticConstScore
 	}
-
-	after = strings.TrimLeft(after, " \t")
-	if !strings.HasPrefix(after, "=") {
-		return semanticConstScore
+	if isFunctionValue(rhs) {
+		return semanticFunctionScore
 	}
 
-	rhs := strings.TrimLeft(after[1:], " \t")
 	keyword, _ := leadingToken(rhs)
 	switch keyword {
 	case "struct", "enum", "union", "opaque":
@@ -142,6 +147,66 @@ func semanticConstLikeScore(rest string) int16 {
 	}
 }
 
+// bindingValue returns the right-hand side of "name = value" or
+// "name: Type = value".
+func bindingValue(rest string) (string, bool) {
+	name, after := leadingToken(rest)
+	if name == "" {
+		return "", false
+	}
+
+	after = strings.TrimLeft(after, " \t")
+	if strings.HasPrefix(after, ":") {
+		eq := assignmentIndex(after)
+		if eq < 0 {
+			return "", false
+		}
+		after = after[eq:]
+	}
+	if !strings.HasPrefix(after, "=") || strings.HasPrefix(after, "==") || strings.HasPrefix(after, "=>") {
+		return "", false
+	}
+	return strings.TrimLeft(after[1:], " \t"), true
+}
+
+func assignmentIndex(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '=' {
+			continue
+		}
+		if i+1 < len(s) && (s[i+1] == '>' || s[i+1] == '=') {
+			i++
+			continue
+		}
+		if i > 0 && strings.IndexByte("=!<>", s[i-1]) >= 0 {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// isFunctionValue reports whether a binding value is a function expression or
+// an arrow function.
+func isFunctionValue(rhs string) bool {
+	keyword, tail := leadingToken(rhs)
+	if keyword == "async" {
+		rhs = strings.TrimLeft(tail, " \t")
+		keyword, tail = leadingToken(rhs)
+	}
+
+	switch {
+	case keyword == "function":
+		return true
+	case keyword != "":
+		return strings.HasPrefix(strings.TrimLeft(tail, " \t"), "=>")
+	case strings.HasPrefix(rhs, "(") || strings.HasPrefix(rhs, "<"):
+		return strings.Contains(rhs, "=>")
+	default:
+		return false
+	}
+}
+
 func semanticFunctionLikeScore(keyword string, rest string) int16 {
 	name, isMethod := functionNameAndMethod(keyword, rest)
 	if isConstructorName(name) {
@@ -29,3 +29,23 @@ func TestComputeSemanticScoreKotlinTypeAlias(t *testing.T) {
 		t.Fatalf("computeSemanticScore(typealias) = %d, want %d", got, semanticTypeDeclScore)
 	}
 }
+
+func TestComputeSemanticScoreArrowFunctionBindings(t *testing.T) {
+	tests := []struct {
+		text string
+		want int16
+	}{
+		{text: "export const fetchUser = async (id: string) => {", want: semanticFunctionScore + semanticVisibilityPublic},
+		{text: "export const Button: React.FC<Props> = ({ label }) => {", want: semanticFunctionScore + semanticVisibilityPublic},
+		{text: "const toKey = id => `k:${id}`", want: semanticFunctionScore},
+		{text: "var legacy = function () {", want: semanticFunctionScore},
+		{text: "export const DEFAULT_TIMEOUT = 30", want: semanticConstScore + semanticVisibilityPublic},
+		{text: "let counter = (1 + 2) * 3", want: semanticLocalScore},
+	}
+
+	for _, tt := range tests {
+		if got := computeSemanticScore(tt.text); got != tt.want {
+			t.Fatalf("computeSemanticScore(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
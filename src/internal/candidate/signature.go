@@ -0,0 +1,162 @@
+package candidate
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxSignatureLines bounds how far a declaration is followed before giving
+// up on finding the end of its signature.
+const maxSignatureLines = 12
+
+// signatureContinuation matches lines that carry on the signature above them:
+// Rust and Swift where clauses, trailing return types, throws clauses, Kotlin
+// return types and C++ constructor initializers.
+var signatureContinuation = regexp.MustCompile(`^(?:where\b|->|throws\b|extends\b|implements\b|requires\b|noexcept\b|:[^:])`)
+
+// signature collapses a declaration that spans several lines into one,
+// starting at the 1-based line. It returns "" for declarations that fit on
+// their line.
+func signature(lines []string, line int, key string, id LangID) string {
+	if line < 1 || line > len(lines) || key == "" || !hasSignatures(id) {
+		return ""
+	}
+
+	first := codeBeforeComment(lines[line-1], id)
+	depth := parenDepth(first, 0)
+	if depth > 0 && !opensParameterList(first, key) {
+		return ""
+	}
+
+	parts := []string{strings.TrimSpace(first)}
+	inWhere := false
+	for n := line + 1; n <= len(lines) && n < line+maxSignatureLines; n++ {
+		next := strings.TrimSpace(codeBeforeComment(lines[n-1], id))
+		switch {
+		case depth > 0:
+		case signatureContinuation.MatchString(next):
+			inWhere = inWhere || strings.HasPrefix(next, "where")
+		case inWhere && !strings.HasPrefix(next, "{"):
+		default:
+			if len(parts) == 1 {
+				return ""
+			}
+			return collapseSignature(parts)
+		}
+		if next == "" {
+			return ""
+		}
+		parts = append(parts, next)
+		depth = parenDepth(next, depth)
+		if inWhere && depth == 0 && (strings.HasSuffix(next, "{") || strings.HasSuffix(next, ";")) {
+			return collapseSignature(parts)
+		}
+	}
+	return ""
+}
+
+func hasSignatures(id LangID) bool {
+	switch id {
+	case LangGo, LangRust, LangZig, LangCSharp, LangJava, LangKotlin, LangPHP,
+		LangRuby, LangPython, LangJavaScript, LangTypeScript, LangTSX, LangSwift,
+		LangC, LangCPP, LangObjC, LangScala, LangDart, LangLua, LangElixir:
+		return true
+	}
+	return false
+}
+
+// opensParameterList reports whether the parenthesis left open on the line
+// follows the declared name, as opposed to a call or a grouped block such as
+// Go's var (...).
+func opensParameterList(line string, key string) bool {
+	open := firstUnclosedParen(line)
+	if open < 0 {
+		return false
+	}
+	head := strings.TrimRight(line[:open], " \t")
+	head = strings.TrimRight(strings.TrimSuffix(head, "async"), " \t")
+	head = strings.TrimRight(strings.TrimSuffix(head, "="), " \t")
+	if n := len(head); n > 0 && (head[n-1] == '>' || head[n-1] == ']') {
+		if i := strings.LastIndexAny(head, "<["); i >= 0 {
+			head = strings.TrimRight(head[:i], " \t")
+		}
+	}
+
+	segments := strings.FieldsFunc(key, isKeySeparator)
+	if len(segments) == 0 {
+		return false
+	}
+	name := segments[len(segments)-1]
+	rest, ok := strings.CutSuffix(head, name)
+	return ok && (rest == "" || !isWordByte(rest[len(rest)-1]))
+}
+
+func firstUnclosedParen(line string) int {
+	var open []int
+	for i := 0; i < len(line); i++ {
+		switch b := line[i]; b {
+		case '"', '`':
+			i = skipQuoted(line, i, b)
+		case '(':
+			open = append(open, i)
+		case ')':
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	if len(open) == 0 {
+		return -1
+	}
+	return open[0]
+}
+
+// parenDepth adds the parentheses and square brackets a line leaves open to
+// depth, skipping string and character literals.
+func parenDepth(line string, depth int) int {
+	for i := 0; i < len(line); i++ {
+		switch b := line[i]; b {
+		case '"', '`':
+			i = skipQuoted(line, i, b)
+		case '\'':
+			// Rust lifetimes and labels are not quotes; character literals
+			// close within a few bytes.
+			if end := strings.IndexByte(line[i+1:min(len(line), i+5)], '\''); end >= 0 {
+				i += end + 1
+			}
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		}
+	}
+	return max(depth, 0)
+}
+
+// codeBeforeComment drops a trailing line comment.
+func codeBeforeComment(line string, id LangID) string {
+	marker := "//"
+	switch id {
+	case LangPython, LangRuby, LangElixir:
+		marker = "#"
+	case LangLua:
+		marker = "--"
+	}
+	for i := 0; i < len(line); i++ {
+		switch b := line[i]; {
+		case b == '"' || b == '`':
+			i = skipQuoted(line, i, b)
+		case strings.HasPrefix(line[i:], marker):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func collapseSignature(parts []string) string {
+	text := strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+	for _, fix := range [][2]string{{"( ", "("}, {"[ ", "["}, {" )", ")"}, {" ]", "]"}, {",)", ")"}, {",]", "]"}} {
+		text = strings.ReplaceAll(text, fix[0], fix[1])
+	}
+	return text
+}
@@ -0,0 +1,83 @@
+package candidate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		lang LangID
+		key  string
+		src  string
+		want string
+	}{
+		{
+			name: "go wrapped parameters",
+			lang: LangGo,
+			key:  "Open",
+			src:  "func (s *Store) Open(\n\tctx context.Context, // request scope\n\tpath string,\n) (*File, error) {\n\treturn nil, nil\n}",
+			want: "func (s *Store) Open(ctx context.Context, path string) (*File, error) {",
+		},
+		{
+			name: "single line",
+			lang: LangGo,
+			key:  "Close",
+			src:  "func (s *Store) Close() error {\n\treturn nil\n}",
+		},
+		{
+			name: "go grouped declaration",
+			lang: LangGo,
+			key:  "var",
+			src:  "var (\n\ta = 1\n)",
+		},
+		{
+			name: "rust where clause",
+			lang: LangRust,
+			key:  "merge",
+			src:  "pub fn merge<'a, T>(left: &'a [T], right: &'a [T]) -> Vec<T>\nwhere\n    T: Clone + Ord,\n{\n    todo!()\n}",
+			want: "pub fn merge<'a, T>(left: &'a [T], right: &'a [T]) -> Vec<T> where T: Clone + Ord,",
+		},
+		{
+			name: "java annotated parameters",
+			lang: LangJava,
+			key:  "get",
+			src:  "public ResponseEntity<User> get(\n        @PathVariable(\"id\") String id,\n        @RequestParam(\"q\") String q) throws IOException {\n    return null;\n}",
+			want: `public ResponseEntity<User> get(@PathVariable("id") String id, @RequestParam("q") String q) throws IOException {`,
+		},
+		{
+			name: "cpp trailing return",
+			lang: LangCPP,
+			key:  "area",
+			src:  "auto area(const Shape& s)\n    -> double {\n  return 0;\n}",
+			want: "auto area(const Shape& s) -> double {",
+		},
+		{
+			name: "csharp allman braces",
+			lang: LangCSharp,
+			key:  "Run",
+			src:  "public void Run(int a,\n                int b)\n{\n}",
+			want: "public void Run(int a, int b)",
+		},
+		{
+			name: "python",
+			lang: LangPython,
+			key:  "load",
+			src:  "def load(\n    path: str,  # file to read\n    strict: bool = False,\n) -> Config:\n    pass",
+			want: "def load(path: str, strict: bool = False) -> Config:",
+		},
+		{
+			name: "call spanning lines",
+			lang: LangTypeScript,
+			key:  "router",
+			src:  "export const router = createRouter(\n  routes,\n)",
+		},
+	}
+	for _, tt := range tests {
+		got := signature(strings.Split(tt.src, "\n"), 1, tt.key, tt.lang)
+		if got != tt.want {
+			t.Fatalf("%s: signature() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package candidate
+
+import (
+	"path/filepath"
+
+	"snav/internal/readfile"
+)
+
+type emittedKey struct {
+	Cell int
+	Line int
+	Key  string
+}
+
+type sourceFile struct {
+	root string
+	// read loads a file by its path within root. It is nil for the working
+	// tree, which is read from disk.
+	read      func(path string) ([]string, error)
+	path      string
+	lines     []string
+	loaded    bool
+	head      []string
+	headN     int
+	emitted   map[emittedKey]struct{}
+	python    []pythonLine
+	docs      []docHeading
+	proto     []string
+	config    []string
+	manifests []manifestDoc
+	hcl       []hclLine
+	component *componentOutline
+	notebook  bool
+	openAPI   int8
+	testPath  bool
+	tests     []lineRange
+	defines   []lineRange
+}
+
+func newSourceFile(root string) *sourceFile {
+	return &sourceFile{root: root}
+}
+
+func (s *sourceFile) reset(path string) {
+	s.path = path
+	s.lines = nil
+	s.loaded = false
+	s.head = nil
+	s.headN = 0
+	s.emitted = nil
+	s.python = nil
+	s.docs = nil
+	s.proto = nil
+	s.config = nil
+	s.manifests = nil
+	s.hcl = nil
+	s.component = nil
+	s.notebook = false
+	s.openAPI = 0
+	s.testPath = isTestPath(path)
+	s.tests = nil
+	s.defines = nil
+}
+
+func (s *sourceFile) Lines() []string {
+	if s.loaded {
+		return s.lines
+	}
+	s.loaded = true
+
+	var lines []string
+	var err error
+	if s.read != nil {
+		lines, err = s.read(s.path)
+	} else {
+		lines, err = readfile.ReadLinesNormalized(filepath.Join(s.root, s.path))
+	}
+	if err != nil {
+		return nil
+	}
+	s.lines = lines
+	return s.lines
+}
+
+// Head returns up to the first n lines of the file. Unless the whole file
+// is already loaded, only those lines are read from disk. Files of a
+// revision are read whole, as their blob is already in memory.
+func (s *sourceFile) Head(n int) []string {
+	if s.loaded || s.read != nil {
+		lines := s.Lines()
+		return lines[:min(n, len(lines))]
+	}
+	if s.headN < n {
+		s.headN = n
+		head, err := readfile.ReadHeadNormalized(filepath.Join(s.root, s.path), n)
+		if err != nil {
+			return nil
+		}
+		s.head = head
+	}
+	return s.head[:min(n, len(s.head))]
+}
+
+func (s *sourceFile) markEmitted(cand Candidate) {
+	if s.emitted == nil {
+		s.emitted = make(map[emittedKey]struct{})
+	}
+	s.emitted[emittedKey{Cell: cand.Cell, Line: cand.Line, Key: cand.Key}] = struct{}{}
+}
+
+func (s *sourceFile) wasEmitted(cand Candidate) bool {
+	_, ok := s.emitted[emittedKey{Cell: cand.Cell, Line: cand.Line, Key: cand.Key}]
+	return ok
+}
+
+// docComment reads the doc comment of a declaration candidate. Only
+// languages with doc comment conventions load the file for it.
+func (s *sourceFile) docComment(cand Candidate) string {
+	if cand.Kind != KindCode || !hasDocComments(cand.LangID) {
+		return ""
+	}
+	return docComment(s.Lines(), cand.Line, cand.LangID)
+}
+
+// signature collapses a multi-line declaration candidate to one line.
+func (s *sourceFile) signature(cand Candidate) string {
+	if cand.Kind != KindCode || !hasSignatures(cand.LangID) {
+		return ""
+	}
+	return signature(s.Lines(), cand.Line, cand.Key, cand.LangID)
+}
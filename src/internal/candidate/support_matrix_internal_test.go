@@ -49,6 +49,9 @@ func TestDeclarationIncludeGlobsCoverSupportedSourceFiles(t *testing.T) {
 		{path: "main.hpp", want: lang.CPP},
 		{path: "main.hh", want: lang.CPP},
 		{path: "main.hxx", want: lang.CPP},
+		{path: "UserCard.vue", want: lang.Vue},
+		{path: "Counter.svelte", want: lang.Svelte},
+		{path: "index.astro", want: lang.Astro},
 	}
 
 	for _, tt := range tests {
@@ -100,3 +103,61 @@ func matchesAnyGlob(globs []string, name string) bool {
 	}
 	return false
 }
+
+func TestSchemaIncludeGlobsCoverSchemaFiles(t *testing.T) {
+	tests := []struct {
+		path string
+		want lang.ID
+	}{
+		{path: "api/search.proto", want: lang.Protobuf},
+		{path: "schema.graphql", want: lang.GraphQL},
+		{path: "schema.graphqls", want: lang.GraphQL},
+		{path: "queries.gql", want: lang.GraphQL},
+		{path: "migrations/001_init.sql", want: lang.SQL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := lang.Detect(tt.path); got != tt.want {
+				t.Fatalf("lang.Detect(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+			if !matchesAnyGlob(schemaIncludeGlobs, filepath.Base(tt.path)) {
+				t.Fatalf("schemaIncludeGlobs do not cover %q", tt.path)
+			}
+		})
+	}
+}
+
+func TestLanguageDeclarationGlobsCoverLanguageFiles(t *testing.T) {
+	tests := []struct {
+		path string
+		want lang.ID
+	}{
+		{path: "lua/plugins/init.lua", want: lang.Lua},
+		{path: "lib/search.ex", want: lang.Elixir},
+		{path: "test/search_test.exs", want: lang.Elixir},
+		{path: "src/main/scala/Jobs.scala", want: lang.Scala},
+		{path: "scripts/run.sc", want: lang.Scala},
+		{path: "build.sbt", want: lang.Scala},
+		{path: "src/Search.hs", want: lang.Haskell},
+		{path: "lib/search.ml", want: lang.OCaml},
+		{path: "lib/search.mli", want: lang.OCaml},
+		{path: "lib/main.dart", want: lang.Dart},
+		{path: "Sources/Store.m", want: lang.ObjC},
+		{path: "Sources/Bridge.mm", want: lang.ObjC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := lang.Detect(tt.path); got != tt.want {
+				t.Fatalf("lang.Detect(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+			for _, l := range declarationLanguages {
+				if l.lang == tt.want && matchesAnyGlob(l.globs, filepath.Base(tt.path)) {
+					return
+				}
+			}
+			t.Fatalf("no language declaration pass covers %q", tt.path)
+		})
+	}
+}
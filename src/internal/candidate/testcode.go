@@ -0,0 +1,165 @@
+package candidate
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// lineRange is an inclusive range of 1-based lines.
+type lineRange struct {
+	First int
+	Last  int
+}
+
+var (
+	goTestFunc        = regexp.MustCompile(`^func\s+(?:Test|Benchmark|Fuzz)[A-Z0-9_]?\w*\s*\(\s*\w+\s+\*testing\.(?:T|B|F)\s*\)`)
+	rustTestModule    = regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?mod\s+\w+\s*\{`)
+	pythonTestCase    = regexp.MustCompile(`^(\s*)class\s+\w+\s*\([^)]*\b(?:unittest\.)?(?:Isolated)?(?:Async)?TestCase\b`)
+	jsTestBlockOpener = regexp.MustCompile(`^(?:(?:describe|context|suite|it|test)(?:\.(?:each|only|skip|concurrent|todo))?\s*\(|if\s*\(\s*import\.meta\.vitest\s*\))`)
+)
+
+// isTestPath reports whether a path looks like a test file or lives in a
+// test directory.
+func isTestPath(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	dirs := strings.Split(path.Dir(rel), "/")
+	for _, dir := range dirs {
+		for _, name := range testDirNames {
+			if dir == name {
+				return true
+			}
+		}
+	}
+	base := path.Base(rel)
+	for _, glob := range testFileGlobs {
+		if ok, _ := path.Match(glob, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isTestCandidate reports whether a candidate is test code: it comes from a
+// test file, is a Go test function, or sits inside an in-file test block such
+// as a Rust #[cfg(test)] module, a unittest.TestCase class or a JavaScript
+// describe block.
+func (s *sourceFile) isTestCandidate(cand Candidate) bool {
+	if s.testPath {
+		return true
+	}
+	if cand.Cell > 0 {
+		return false
+	}
+	if cand.LangID == LangGo {
+		return goTestFunc.MatchString(strings.TrimSpace(cand.Text))
+	}
+	return inLineRanges(s.testRanges(cand.LangID), cand.Line)
+}
+
+func inLineRanges(ranges []lineRange, line int) bool {
+	for _, r := range ranges {
+		if line >= r.First && line <= r.Last {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sourceFile) testRanges(id LangID) []lineRange {
+	if s.tests == nil {
+		s.tests = findTestRanges(id, s.Lines())
+		if s.tests == nil {
+			s.tests = []lineRange{}
+		}
+	}
+	return s.tests
+}
+
+func findTestRanges(id LangID, lines []string) []lineRange {
+	switch id {
+	case LangRust:
+		return rustTestRanges(lines)
+	case LangPython:
+		return pythonTestRanges(lines)
+	case LangJavaScript, LangTypeScript, LangTSX:
+		return braceBlockRanges(lines, jsTestBlockOpener)
+	}
+	return nil
+}
+
+// rustTestRanges finds modules annotated with #[cfg(test)].
+func rustTestRanges(lines []string) []lineRange {
+	var out []lineRange
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "#[cfg(test)]" {
+			continue
+		}
+		n := i + 1
+		for n < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[n]), "#[") {
+			n++
+		}
+		if n < len(lines) && rustTestModule.MatchString(strings.TrimSpace(lines[n])) {
+			last := blockEnd(lines, n)
+			out = append(out, lineRange{First: i + 1, Last: last})
+			i = last - 1
+		}
+	}
+	return out
+}
+
+// pythonTestRanges finds classes deriving from unittest.TestCase, up to the
+// next line indented no deeper than the class.
+func pythonTestRanges(lines []string) []lineRange {
+	var out []lineRange
+	for i := 0; i < len(lines); i++ {
+		m := pythonTestCase.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		indent := len(m[1])
+		last := i + 1
+		for n := i + 1; n < len(lines); n++ {
+			trimmed := strings.TrimSpace(lines[n])
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if _, lineIndent := trimIndent(lines[n]); lineIndent <= indent {
+				break
+			}
+			last = n + 1
+		}
+		out = append(out, lineRange{First: i + 1, Last: last})
+		i = last - 1
+	}
+	return out
+}
+
+// braceBlockRanges finds the blocks whose first line matches opener and that
+// run to the bracket closing that line.
+func braceBlockRanges(lines []string, opener *regexp.Regexp) []lineRange {
+	var out []lineRange
+	for i := 0; i < len(lines); i++ {
+		if !opener.MatchString(strings.TrimSpace(lines[i])) {
+			continue
+		}
+		last := blockEnd(lines, i)
+		out = append(out, lineRange{First: i + 1, Last: last})
+		i = last - 1
+	}
+	return out
+}
+
+// blockEnd returns the 1-based line where the brackets opened on the 0-based
+// line start close, or the last line when they never do.
+func blockEnd(lines []string, start int) int {
+	var sc bracketScanner
+	for n := start; n < len(lines); n++ {
+		sc.scan(lines[n])
+		if sc.depth <= 0 && sc.idle() {
+			return n + 1
+		}
+	}
+	return len(lines)
+}
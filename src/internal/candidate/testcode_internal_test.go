@@ -0,0 +1,82 @@
+package candidate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsTestPath(t *testing.T) {
+	tests := map[string]bool{
+		"pkg/store_test.go":          true,
+		"src/__tests__/app.ts":       true,
+		"web/app.spec.tsx":           true,
+		"tests/integration/setup.rs": true,
+		"tools/test_utils.py":        true,
+		"pkg/store.go":               false,
+		"src/testing/helpers.ts":     false,
+		"src/contest.py":             false,
+	}
+	for path, want := range tests {
+		if got := isTestPath(path); got != want {
+			t.Fatalf("isTestPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestFindTestRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		lang LangID
+		src  string
+		want []lineRange
+	}{
+		{
+			name: "rust cfg(test) module",
+			lang: LangRust,
+			src:  "pub fn add(a: i32) -> i32 { a }\n\n#[cfg(test)]\nmod tests {\n    use super::*;\n\n    #[test]\n    fn adds() { assert_eq!(add(1), 1); }\n}\n\npub fn after() {}",
+			want: []lineRange{{First: 3, Last: 9}},
+		},
+		{
+			name: "python TestCase class",
+			lang: LangPython,
+			src:  "import unittest\n\nclass Parser:\n    pass\n\nclass ParserTest(unittest.TestCase):\n    def test_parse(self):\n        pass\n\n    # helpers\n    def helper(self):\n        pass\n\ndef main():\n    pass",
+			want: []lineRange{{First: 6, Last: 12}},
+		},
+		{
+			name: "javascript describe and in-source vitest",
+			lang: LangTypeScript,
+			src:  "export function add(a: number) { return a }\n\ndescribe('add', () => {\n  const fixture = 1\n  it('adds', () => {})\n})\n\nif (import.meta.vitest) {\n  const { it } = import.meta.vitest\n}\nexport const after = 1",
+			want: []lineRange{{First: 3, Last: 6}, {First: 8, Last: 10}},
+		},
+	}
+	for _, tt := range tests {
+		got := findTestRanges(tt.lang, strings.Split(tt.src, "\n"))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: findTestRanges = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsTestCandidate(t *testing.T) {
+	src := testSourceFile("pkg/store.go", "package pkg\n\nfunc TestStore(t *testing.T) {}\n\nfunc Testify() {}\n")
+	for _, tt := range []struct {
+		text string
+		want bool
+	}{
+		{text: "func TestStore(t *testing.T) {}", want: true},
+		{text: "func BenchmarkGet(b *testing.B) {", want: true},
+		{text: "func Testify() {}", want: false},
+	} {
+		cand := Candidate{File: "pkg/store.go", Line: 3, Text: tt.text, LangID: LangGo}
+		if got := src.isTestCandidate(cand); got != tt.want {
+			t.Fatalf("isTestCandidate(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+
+	pathSrc := testSourceFile("pkg/store_test.go", "package pkg\n\nfunc helper() {}\n")
+	pathSrc.testPath = isTestPath(pathSrc.path)
+	if !pathSrc.isTestCandidate(Candidate{File: "pkg/store_test.go", Line: 3, Text: "func helper() {}", LangID: LangGo}) {
+		t.Fatalf("expected candidates from test files to be tests")
+	}
+}
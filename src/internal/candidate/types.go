@@ -2,8 +2,20 @@ package candidate
 
 import "snav/internal/lang"
 
-const DefaultRGPattern = `^(?:\s*(?:(?:export|default|async|public|private|protected|internal|abstract|final|sealed|partial|static|inline|open|override|readonly|extern|unsafe|suspend|data|pub(?:\([^)]*\))?)\s+)*(?:func|function|type|typealias|var|const|class|interface|enum|record|def|fn|fun|struct|impl|trait|module|mod|let|object|protocol|extension|namespace|test)\b|\s*(?:(?:public|private|protected|internal|static|final|abstract|virtual|override|async|extern|unsafe|sealed|partial|readonly|synchronized|native|strictfp)\s+)+(?:[A-Za-z_][A-Za-z0-9_<>,.?\[\]]*\s+)+[A-Za-z_][A-Za-z0-9_]*\s*\()`
-const DefaultRGConfigPattern = `^\s*(?:\[\[[A-Za-z0-9_.:-]+\]\]\s*$|\[[A-Za-z0-9_.:-]+\]\s*$|"(?:\\.|[^"\\])+"\s*:|'[^']+'\s*:|-\s*(?:"(?:\\.|[^"\\])+"|'[^']+'|[A-Za-z0-9_.-]+)\s*:|(?:export\s+)?[A-Za-z0-9_.-]+\s*(?::|=)|[A-Za-z0-9_.-]+(?:\s+"(?:\\.|[^"\\])+"){0,2}\s*\{|<\s*[A-Za-z_][A-Za-z0-9_.:-]*(?:\s|>|/>))`
+// Identifier classes in the patterns below accept Unicode letters. Their
+// ASCII ranges are kept so that --smart-case still searches case-sensitively.
+const DefaultRGPattern = `^(?:\s*(?:(?:export|default|async|public|private|protected|internal|abstract|final|sealed|partial|static|inline|open|override|readonly|extern|unsafe|suspend|data|pub(?:\([^)]*\))?)\s+)*(?:func|function|type|typealias|var|const|class|interface|enum|record|def|fn|fun|struct|impl|trait|module|mod|let|object|protocol|extension|namespace|test)\b|\s*(?:(?:public|private|protected|internal|static|final|abstract|virtual|override|async|extern|unsafe|sealed|partial|readonly|synchronized|native|strictfp)\s+)+(?:[A-Za-z_\p{L}][A-Za-z0-9_\p{L}\p{M}\p{N}<>,.?\[\]]*\s+)+[A-Za-z_\p{L}][A-Za-z0-9_\p{L}\p{M}\p{N}]*\s*\()`
+const DefaultRGConfigPattern = `^\s*(?:\[\[[A-Za-z0-9_\p{L}\p{M}\p{N}.:-]+\]\]\s*$|\[[A-Za-z0-9_\p{L}\p{M}\p{N}.:-]+\]\s*$|"(?:\\.|[^"\\])+"\s*:|'[^']+'\s*:|-\s*(?:"(?:\\.|[^"\\])+"|'[^']+'|[A-Za-z0-9_\p{L}\p{M}\p{N}.-]+)\s*:|(?:export\s+)?[A-Za-z0-9_\p{L}\p{M}\p{N}.-]+\s*(?::|=)|[A-Za-z0-9_\p{L}\p{M}\p{N}.-]+(?:\s+"(?:\\.|[^"\\])+"){0,2}\s*\{|<\s*[A-Za-z_\p{L}][A-Za-z0-9_\p{L}\p{M}\p{N}.:-]*(?:\s|>|/>))`
+
+const pythonAssignmentPattern = `^(?:[A-Z_]*[A-Z][A-Z0-9_]*\s*(?::[^=]*)?=(?:[^=]|$)|[A-Za-z_\p{L}][A-Za-z0-9_\p{L}\p{M}\p{N}]*\s*:\s*(?:typing\.|t\.)?TypeAlias\b)`
+
+const docHeadingPattern = `^(?: {0,3}#{1,6}[ \t]+\S|={1,6}[ \t]+\S|[=\-~^"'#*+:._]{2,}[ \t]*$)`
+
+const schemaPattern = `^\s*(?:(?:message|service|rpc|enum)\s+[A-Za-z_]|(?:extend\s+)?(?:type|interface|input|union|scalar)\s+[A-Za-z_]|(?:query|mutation|subscription)\s+[A-Za-z_]|(?i:create\s+(?:or\s+replace\s+)?(?:(?:temp|temporary|unique|materialized|unlogged)\s+)*(?:table|view|index|function|procedure)\b))`
+const taskPattern = `^(?:[A-Za-z0-9_.%/$(){}@-][^:=#]*:(?:[^=]|$)|@?[A-Za-z0-9_-]+(?:\s+[*+$]?[A-Za-z0-9_]+(?:=(?:"[^"]*"|'[^']*'|[^\s:]+))?)*\s*:(?:[^=]|$)|\s*(?i:from)\s+.*\s(?i:as)\s+[A-Za-z0-9_.-]+\s*$)`
+const openAPIPathPattern = `^\s*/[^\s:]*\s*:`
+const componentPattern = `^(?:<[A-Za-z]|---\s*$)`
+const notebookPattern = `"nbformat"\s*:\s*[0-9]`
 
 type LangID = lang.ID
 
@@ -28,6 +40,56 @@ const (
 	LangBash       LangID = lang.Bash
 	LangC          LangID = lang.C
 	LangCPP        LangID = lang.CPP
+	LangMarkdown   LangID = lang.Markdown
+	LangRST        LangID = lang.RST
+	LangAsciiDoc   LangID = lang.AsciiDoc
+	LangProtobuf   LangID = lang.Protobuf
+	LangGraphQL    LangID = lang.GraphQL
+	LangSQL        LangID = lang.SQL
+	LangHCL        LangID = lang.HCL
+	LangLua        LangID = lang.Lua
+	LangElixir     LangID = lang.Elixir
+	LangScala      LangID = lang.Scala
+	LangHaskell    LangID = lang.Haskell
+	LangOCaml      LangID = lang.OCaml
+	LangDart       LangID = lang.Dart
+	LangObjC       LangID = lang.ObjC
+	LangVue        LangID = lang.Vue
+	LangSvelte     LangID = lang.Svelte
+	LangAstro      LangID = lang.Astro
+	LangJupyter    LangID = lang.Jupyter
+)
+
+// Kind separates navigable symbols that are not code declarations. The zero
+// value is a code declaration.
+type Kind string
+
+const (
+	KindCode         Kind = ""
+	KindDoc          Kind = "doc"
+	KindMessage      Kind = "message"
+	KindEnum         Kind = "enum"
+	KindService      Kind = "service"
+	KindRPC          Kind = "rpc"
+	KindType         Kind = "type"
+	KindQuery        Kind = "query"
+	KindMutation     Kind = "mutation"
+	KindSubscription Kind = "subscription"
+	KindOperation    Kind = "operation"
+	KindPath         Kind = "path"
+	KindTable        Kind = "table"
+	KindView         Kind = "view"
+	KindIndex        Kind = "index"
+	KindFunction     Kind = "function"
+	KindTask         Kind = "task"
+	KindResource     Kind = "resource"
+	KindJob          Kind = "job"
+	KindStep         Kind = "step"
+	KindModule       Kind = "module"
+	KindVariable     Kind = "variable"
+	KindOutput       Kind = "output"
+	KindLocal        Kind = "local"
+	KindComponent    Kind = "component"
 )
 
 type Candidate struct {
@@ -39,14 +101,56 @@ type Candidate struct {
 	Key           string
 	LangID        LangID
 	SemanticScore int16
+	Container     string
+	Annotations   string
+	Kind          Kind
+	// Cell is the 1-based notebook cell a candidate was found in. Line and
+	// Col are then relative to the cell source. Col is the 1-based byte
+	// column of Key, as rg counts columns.
+	Cell int
+	// Doc is the first sentence of the symbol's doc comment or docstring.
+	Doc string
+	// Signature is the declaration collapsed to one line when it spans
+	// several, and empty otherwise.
+	Signature string
+	// Generated marks candidates from generated files.
+	Generated bool
+	// Test marks test code, found by path or by in-file markers.
+	Test bool
+	// Root labels the workspace root the candidate was found in. File is
+	// relative to that root. It is empty when snav searches a single root.
+	Root string
+	// Dependency marks candidates from third-party package sources.
+	Dependency bool
+	// Change is how a symbol diff row changed between the two revisions,
+	// and empty outside a symbol diff.
+	Change Change
 }
 
 type ProducerConfig struct {
-	Root         string
-	Pattern      string
-	Excludes     []string
-	NoIgnore     bool
-	ExcludeTests bool
+	Root string
+	// RootName labels the candidates found under Root when several roots
+	// are searched.
+	RootName string
+	// Dependency identifies a third-party package root as name@version. Only
+	// declarations are indexed from it.
+	Dependency string
+	Pattern    string
+	Excludes   []string
+	NoIgnore   bool
+	// Hidden searches hidden files and directories, except .git.
+	Hidden bool
+	// Rev is a commit whose tree is indexed instead of the working tree.
+	Rev string
+	// ExcludeGenerated skips files that generated-code markers identify.
+	ExcludeGenerated bool
+	Members          bool
+	// MaxFileSize is passed to rg --max-filesize, such as "1M". Empty
+	// means DefaultMaxFileSize.
+	MaxFileSize string
+	// MaxFileCandidates caps the candidates indexed from one file. Zero
+	// means DefaultMaxFileCandidates.
+	MaxFileCandidates int
 }
 
 type FilteredCandidate struct {
@@ -59,27 +163,39 @@ type FilteredCandidate struct {
 var filterParallelThreshold = 20_000
 var filterMinChunkSize = 4_096
 
-var testExcludeGlobs = []string{
-	"test/**",
-	"tests/**",
-	"__tests__/**",
-	"spec/**",
-	"specs/**",
-	"**/test/**",
-	"**/tests/**",
-	"**/__tests__/**",
-	"**/spec/**",
-	"**/specs/**",
+// generatedFileGlobs are file names that code generators commonly write.
+// They match the base name at any depth.
+var generatedFileGlobs = []string{
+	"zz_generated*",
+	"*.pb.go",
+	"*.pb.gw.go",
+	"*.pb.h",
+	"*.pb.cc",
+	"*_pb2.py",
+	"*_pb2.pyi",
+	"*_pb2_grpc.py",
+	"*_pb.js",
+	"*_pb.d.ts",
+	"*.g.dart",
+	"*.freezed.dart",
+	"*.g.cs",
+	"*.designer.cs",
+	"*.generated.*",
+	"*_generated.*",
+	"mock_*.go",
+	"*_mock.go",
+}
+
+// testDirNames are directory names whose files are tests.
+var testDirNames = []string{"test", "tests", "__tests__", "spec", "specs"}
+
+// testFileGlobs are test file names. They match the base name.
+var testFileGlobs = []string{
 	"*_test.*",
 	"*_spec.*",
 	"*.test.*",
 	"*.spec.*",
 	"test_*.py",
-	"**/*_test.*",
-	"**/*_spec.*",
-	"**/*.test.*",
-	"**/*.spec.*",
-	"**/test_*.py",
 }
 
 var configIncludeGlobs = []string{
@@ -108,6 +224,58 @@ var configIncludeGlobs = []string{
 	"*.config",
 }
 
+var pythonIncludeGlobs = []string{
+	"*.py",
+	"*.pyi",
+}
+
+var docIncludeGlobs = []string{
+	"*.md",
+	"*.markdown",
+	"*.rst",
+	"*.adoc",
+	"*.asciidoc",
+}
+
+var schemaIncludeGlobs = []string{
+	"*.proto",
+	"*.graphql",
+	"*.graphqls",
+	"*.gql",
+	"*.sql",
+}
+
+var openAPIIncludeGlobs = []string{
+	"*.yaml",
+	"*.yml",
+}
+
+var taskIncludeGlobs = []string{
+	"Makefile",
+	"makefile",
+	"GNUmakefile",
+	"*.mk",
+	"justfile",
+	"Justfile",
+	".justfile",
+	"*.just",
+	"Dockerfile",
+	"Dockerfile.*",
+	"*.Dockerfile",
+	"*.dockerfile",
+	"Containerfile",
+}
+
+var componentIncludeGlobs = []string{
+	"*.vue",
+	"*.svelte",
+	"*.astro",
+}
+
+var notebookIncludeGlobs = []string{
+	"*.ipynb",
+}
+
 var declarationIncludeGlobs = []string{
 	"*.c",
 	"*.cc",
@@ -125,6 +293,7 @@ var declarationIncludeGlobs = []string{
 	"*.zig",
 	"build.zig",
 	"*.py",
+	"*.pyi",
 	"*.js",
 	"*.jsx",
 	"*.mjs",
@@ -150,4 +319,7 @@ var declarationIncludeGlobs = []string{
 	"*.zsh",
 	".bashrc",
 	".zshrc",
+	"*.vue",
+	"*.svelte",
+	"*.astro",
 }
@@ -0,0 +1,92 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cargoCrates lists the registry crates pinned in Cargo.lock that are
+// unpacked under the Cargo registry. Workspace members and git dependencies
+// are left out.
+func cargoCrates(_ context.Context, root string) ([]Dependency, error) {
+	data, err := os.ReadFile(filepath.Join(root, "Cargo.lock"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	srcDirs, err := cargoRegistrySources()
+	if err != nil || len(srcDirs) == 0 {
+		return nil, err
+	}
+
+	var out []Dependency
+	for _, pkg := range parseCargoLock(data) {
+		for _, src := range srcDirs {
+			dir := filepath.Join(src, pkg.Name+"-"+pkg.Version)
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				out = append(out, Dependency{Name: pkg.Name, Version: pkg.Version, Dir: dir})
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// parseCargoLock returns the registry packages of a Cargo.lock file.
+func parseCargoLock(data []byte) []Dependency {
+	var out []Dependency
+	var pkg Dependency
+	registry := false
+	flush := func() {
+		if registry && pkg.Name != "" && pkg.Version != "" {
+			out = append(out, pkg)
+		}
+		pkg, registry = Dependency{}, false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			flush()
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.TrimSpace(key) {
+		case "name":
+			pkg.Name = value
+		case "version":
+			pkg.Version = value
+		case "source":
+			registry = strings.HasPrefix(value, "registry+") || strings.HasPrefix(value, "sparse+")
+		}
+	}
+	flush()
+	return out
+}
+
+// cargoRegistrySources returns the directories crates are unpacked into, one
+// per registry index.
+func cargoRegistrySources() ([]string, error) {
+	home := os.Getenv("CARGO_HOME")
+	if home == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		home = filepath.Join(userHome, ".cargo")
+	}
+	return filepath.Glob(filepath.Join(home, "registry", "src", "*"))
+}
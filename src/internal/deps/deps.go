@@ -0,0 +1,32 @@
+// Package deps resolves the third-party dependencies of a project to the
+// directories holding their source.
+package deps
+
+import (
+	"context"
+	"errors"
+)
+
+// Dependency is a resolved package whose source is on disk.
+type Dependency struct {
+	Name    string
+	Version string
+	Dir     string
+}
+
+// Resolve finds the dependencies of the project at root: Go modules from
+// `go list -m -json all`, npm packages listed in package.json and installed
+// under node_modules, and crates pinned in Cargo.lock and unpacked in the
+// Cargo registry. Dependencies whose source is not on disk are left out.
+func Resolve(ctx context.Context, root string) ([]Dependency, error) {
+	var out []Dependency
+	var errs []error
+	for _, resolve := range []func(context.Context, string) ([]Dependency, error){goModules, nodePackages, cargoCrates} {
+		found, err := resolve(ctx, root)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		out = append(out, found...)
+	}
+	return out, errors.Join(errs...)
+}
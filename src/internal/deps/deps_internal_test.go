@@ -0,0 +1,119 @@
+package deps
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+}
+
+func TestParseGoModules(t *testing.T) {
+	data := []byte(`{"Path": "example.com/app", "Main": true, "Dir": "/src/app"}
+{"Path": "github.com/spf13/cobra", "Version": "v1.8.0", "Dir": "/mod/github.com/spf13/cobra@v1.8.0"}
+{"Path": "golang.org/x/sys", "Version": "v0.20.0"}
+{"Path": "example.com/lib", "Version": "v1.0.0", "Replace": {"Path": "../lib", "Dir": "/src/lib"}}
+`)
+	got, err := parseGoModules(data)
+	if err != nil {
+		t.Fatalf("parseGoModules: %v", err)
+	}
+	want := []Dependency{
+		{Name: "github.com/spf13/cobra", Version: "v1.8.0", Dir: "/mod/github.com/spf13/cobra@v1.8.0"},
+		{Name: "example.com/lib", Version: "v1.0.0", Dir: "/src/lib"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("modules = %#v, want %#v", got, want)
+	}
+}
+
+func TestNodePackagesListsInstalledManifestDependencies(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json":                          `{"dependencies": {"react": "^18.0.0", "left-pad": "1.0.0"}, "devDependencies": {"@types/node": "^20"}}`,
+		"node_modules/react/package.json":       `{"name": "react", "version": "18.2.0"}`,
+		"node_modules/@types/node/package.json": `{"name": "@types/node", "version": "20.1.0"}`,
+		"node_modules/transitive/package.json":  `{"name": "transitive", "version": "1.0.0"}`,
+	})
+
+	got, err := nodePackages(context.Background(), root)
+	if err != nil {
+		t.Fatalf("nodePackages: %v", err)
+	}
+	want := []Dependency{
+		{Name: "@types/node", Version: "20.1.0", Dir: filepath.Join(root, "node_modules", "@types", "node")},
+		{Name: "react", Version: "18.2.0", Dir: filepath.Join(root, "node_modules", "react")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("packages = %#v, want %#v", got, want)
+	}
+}
+
+func TestCargoCratesFindsRegistrySources(t *testing.T) {
+	root := t.TempDir()
+	cargoHome := t.TempDir()
+	t.Setenv("CARGO_HOME", cargoHome)
+	writeFiles(t, root, map[string]string{
+		"Cargo.lock": `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "serde",
+]
+
+[[package]]
+name = "serde"
+version = "1.0.200"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "abc"
+
+[[package]]
+name = "missing"
+version = "0.2.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "forked"
+version = "0.3.0"
+source = "git+https://example.com/forked#abc"
+`,
+	})
+	writeFiles(t, cargoHome, map[string]string{
+		"registry/src/index.crates.io-6f17d22bba15001f/serde-1.0.200/src/lib.rs": "pub trait Serialize {}\n",
+	})
+
+	got, err := cargoCrates(context.Background(), root)
+	if err != nil {
+		t.Fatalf("cargoCrates: %v", err)
+	}
+	want := []Dependency{{
+		Name:    "serde",
+		Version: "1.0.200",
+		Dir:     filepath.Join(cargoHome, "registry", "src", "index.crates.io-6f17d22bba15001f", "serde-1.0.200"),
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("crates = %#v, want %#v", got, want)
+	}
+}
+
+func TestResolveWithoutManifests(t *testing.T) {
+	got, err := Resolve(context.Background(), t.TempDir())
+	if err != nil || len(got) != 0 {
+		t.Fatalf("Resolve = %#v, %v; want nothing", got, err)
+	}
+}
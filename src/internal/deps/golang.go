@@ -0,0 +1,76 @@
+package deps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type goModule struct {
+	Path    string
+	Version string
+	Main    bool
+	Dir     string
+	Replace *goModule
+}
+
+// goModules lists the modules in the build list of a Go module. The go
+// command runs offline, so modules missing from the module cache are left
+// out rather than downloaded.
+func goModules(ctx context.Context, root string) ([]Dependency, error) {
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "list", "-m", "-e", "-json", "all")
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=readonly", "GOPROXY=off")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("go list modules: %s", msg)
+		}
+		return nil, fmt.Errorf("go list modules: %w", err)
+	}
+	return parseGoModules(data)
+}
+
+func parseGoModules(data []byte) ([]Dependency, error) {
+	var out []Dependency
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var mod goModule
+		if err := dec.Decode(&mod); err != nil {
+			if errors.Is(err, io.EOF) {
+				return out, nil
+			}
+			return nil, fmt.Errorf("parse go list output: %w", err)
+		}
+		if mod.Main {
+			continue
+		}
+		dir, version := mod.Dir, mod.Version
+		if mod.Replace != nil {
+			dir = mod.Replace.Dir
+			if mod.Replace.Version != "" {
+				version = mod.Replace.Version
+			}
+		}
+		if dir == "" {
+			continue
+		}
+		out = append(out, Dependency{Name: mod.Path, Version: version, Dir: dir})
+	}
+}
@@ -0,0 +1,62 @@
+package deps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+type packageManifest struct {
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// nodePackages lists the packages named in package.json that are installed
+// in node_modules.
+func nodePackages(_ context.Context, root string) ([]Dependency, error) {
+	manifest, err := readPackageManifest(filepath.Join(root, "package.json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, deps := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.OptionalDependencies} {
+		for name := range deps {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	var out []Dependency
+	for _, name := range names {
+		dir := filepath.Join(root, "node_modules", filepath.FromSlash(name))
+		installed, err := readPackageManifest(filepath.Join(dir, "package.json"))
+		if err != nil {
+			continue
+		}
+		out = append(out, Dependency{Name: name, Version: installed.Version, Dir: dir})
+	}
+	return out, nil
+}
+
+func readPackageManifest(path string) (packageManifest, error) {
+	var manifest packageManifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,178 @@
+// Package gitrev reads the files of a git revision through git plumbing,
+// without checking the revision out.
+package gitrev
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// File is a regular file in a revision's tree. Path is relative to the
+// directory the tree was listed from.
+type File struct {
+	Path   string
+	Object string
+	Size   int64
+}
+
+// Resolve returns the commit hash rev names in the repository holding dir.
+func Resolve(ctx context.Context, dir string, rev string) (string, error) {
+	if strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("invalid revision %q", rev)
+	}
+	out, err := run(ctx, dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	commit := strings.TrimSpace(string(out))
+	if err != nil || commit == "" {
+		return "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return commit, nil
+}
+
+// ListFiles lists the regular files of rev under dir. Symlinks and
+// submodules are left out.
+func ListFiles(ctx context.Context, dir string, rev string) ([]File, error) {
+	out, err := run(ctx, dir, "ls-tree", "-r", "-z", "--long", rev)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", rev, err)
+	}
+	return parseTree(out)
+}
+
+// parseTree parses `git ls-tree -r -z --long` output.
+func parseTree(out []byte) ([]File, error) {
+	var files []File
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		if len(entry) == 0 {
+			continue
+		}
+		meta, name, ok := bytes.Cut(entry, []byte{'\t'})
+		if !ok {
+			return nil, fmt.Errorf("parse tree entry %q", entry)
+		}
+		fields := strings.Fields(string(meta))
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("parse tree entry %q", entry)
+		}
+		if fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse tree entry %q: %w", entry, err)
+		}
+		files = append(files, File{Path: string(name), Object: fields[2], Size: size})
+	}
+	return files, nil
+}
+
+// ReadFile reads the file at rel, relative to dir, as of rev.
+func ReadFile(ctx context.Context, dir string, rev string, rel string) ([]byte, error) {
+	rel = path.Clean(strings.ReplaceAll(rel, "\\", "/"))
+	out, err := run(ctx, dir, "cat-file", "blob", rev+":./"+rel)
+	if err != nil {
+		return nil, fmt.Errorf("read %s at %s: %w", rel, shortRev(rev), err)
+	}
+	return out, nil
+}
+
+// Reader reads blobs through one long-running `git cat-file --batch`.
+type Reader struct {
+	cmd    *exec.Cmd
+	in     io.WriteCloser
+	out    *bufio.Reader
+	stderr bytes.Buffer
+}
+
+// NewReader starts a blob reader for the repository holding dir.
+func NewReader(ctx context.Context, dir string) (*Reader, error) {
+	r := &Reader{cmd: exec.CommandContext(ctx, "git", "cat-file", "--batch")}
+	r.cmd.Dir = dir
+	r.cmd.Stderr = &r.stderr
+	in, err := r.cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("open git cat-file stdin: %w", err)
+	}
+	out, err := r.cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("open git cat-file stdout: %w", err)
+	}
+	if err := r.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start git cat-file: %w", err)
+	}
+	r.in = in
+	r.out = bufio.NewReaderSize(out, 1<<16)
+	return r, nil
+}
+
+// Read returns the content of a blob.
+func (r *Reader) Read(object string) ([]byte, error) {
+	if _, err := io.WriteString(r.in, object+"\n"); err != nil {
+		return nil, r.failure(err)
+	}
+	header, err := r.out.ReadString('\n')
+	if err != nil {
+		return nil, r.failure(err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return nil, fmt.Errorf("object %s is missing", object)
+	}
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("parse git cat-file header %q", strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("parse git cat-file header %q: %w", strings.TrimSpace(header), err)
+	}
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(r.out, data); err != nil {
+		return nil, r.failure(err)
+	}
+	return data[:size], nil
+}
+
+// Close stops the reader.
+func (r *Reader) Close() error {
+	if err := r.in.Close(); err != nil {
+		_ = r.cmd.Wait()
+		return err
+	}
+	return r.cmd.Wait()
+}
+
+func (r *Reader) failure(err error) error {
+	if msg := strings.TrimSpace(r.stderr.String()); msg != "" {
+		return fmt.Errorf("git cat-file: %s", msg)
+	}
+	return fmt.Errorf("git cat-file: %w", err)
+}
+
+func run(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func shortRev(rev string) string {
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
+}
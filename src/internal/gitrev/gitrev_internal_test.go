@@ -0,0 +1,97 @@
+package gitrev
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTreeKeepsRegularFiles(t *testing.T) {
+	out := []byte("100644 blob 1111111111111111111111111111111111111111      12\tmain.go\x00" +
+		"120000 blob 2222222222222222222222222222222222222222       7\tlink.go\x00" +
+		"160000 commit 3333333333333333333333333333333333333333       -\tvendor/lib\x00" +
+		"100755 blob 4444444444444444444444444444444444444444     300\tscripts/run me.sh\x00")
+
+	got, err := parseTree(out)
+	if err != nil {
+		t.Fatalf("parseTree: %v", err)
+	}
+	want := []File{
+		{Path: "main.go", Object: "1111111111111111111111111111111111111111", Size: 12},
+		{Path: "scripts/run me.sh", Object: "4444444444444444444444444444444444444444", Size: 300},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseTree = %#v, want %#v", got, want)
+	}
+}
+
+func TestReadsFilesOfAnOlderCommit(t *testing.T) {
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=snav", "-c", "user.email=snav@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name string, content string) {
+		t.Helper()
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	git("init", "-q")
+	write("pkg/old.go", "package pkg\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	git("tag", "v1")
+	write("pkg/old.go", "package pkg // changed\n")
+	write("pkg/new.go", "package pkg\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "second")
+
+	ctx := context.Background()
+	commit, err := Resolve(ctx, repo, "v1")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if _, err := Resolve(ctx, repo, "no-such-branch"); err == nil {
+		t.Fatalf("Resolve of an unknown revision should fail")
+	}
+
+	files, err := ListFiles(ctx, filepath.Join(repo, "pkg"), commit)
+	if err != nil {
+		t.Fatalf("ListFiles: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "old.go" {
+		t.Fatalf("ListFiles = %#v, want only old.go relative to pkg", files)
+	}
+
+	r, err := NewReader(ctx, repo)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		data, err := r.Read(files[0].Object)
+		if err != nil || string(data) != "package pkg\n" {
+			t.Fatalf("Read = %q, %v", data, err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := ReadFile(ctx, filepath.Join(repo, "pkg"), commit, "old.go")
+	if err != nil || string(data) != "package pkg\n" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+}
@@ -55,6 +55,41 @@ func classifyLeaf(lang LangID, node *sitter.Node, parentType string, grandType s
 	if nodeType == "error" || strings.Contains(nodeType, "invalid") {
 		return TokenError
 	}
+	if lang == LangMarkdown {
+		return classifyMarkdownLeaf(nodeType, parentType, grandType)
+	}
+	if lang == LangProtobuf && (parentType == "type" || (!node.IsNamed() && isAlphaWord(nodeType))) {
+		if parentType == "type" {
+			return TokenType
+		}
+		return TokenKeyword
+	}
+	if lang == LangSQL && strings.HasPrefix(nodeType, "keyword_") {
+		return TokenKeyword
+	}
+	if lang == LangHCL {
+		if cat, ok := classifyHCLLeaf(nodeType, parentType); ok {
+			return cat
+		}
+	}
+	if lang == LangLua && nodeType == "local" {
+		return TokenKeyword
+	}
+	if lang == LangElixir {
+		if cat, ok := classifyElixirLeaf(nodeType, parentType, lexeme); ok {
+			return cat
+		}
+	}
+	if lang == LangOCaml {
+		if cat, ok := classifyOCamlLeaf(nodeType, parentType); ok {
+			return cat
+		}
+	}
+	if lang == LangHTML || lang == LangCSS {
+		if cat, ok := classifyMarkupLeaf(nodeType); ok {
+			return cat
+		}
+	}
 	if strings.Contains(nodeType, "comment") {
 		return TokenComment
 	}
@@ -70,6 +105,9 @@ func classifyLeaf(lang LangID, node *sitter.Node, parentType string, grandType s
 	if lexeme == "true" || lexeme == "false" || lexeme == "null" || lexeme == "nil" || lexeme == "none" {
 		return TokenNumber
 	}
+	if keywordTokenLangs[lang] && !node.IsNamed() && isAlphaWord(nodeType) {
+		return TokenKeyword
+	}
 
 	if strings.HasSuffix(nodeType, "keyword") {
 		return TokenKeyword
@@ -105,6 +143,92 @@ func classifyLeaf(lang LangID, node *sitter.Node, parentType string, grandType s
 	return TokenPlain
 }
 
+func classifyMarkdownLeaf(nodeType string, parentType string, grandType string) TokenCategory {
+	switch {
+	case strings.HasSuffix(nodeType, "_marker"), strings.HasSuffix(nodeType, "_underline"), nodeType == "fenced_code_block_delimiter", nodeType == "thematic_break":
+		return TokenKeyword
+	case nodeType == "inline" && (parentType == "atx_heading" || grandType == "setext_heading"):
+		return TokenType
+	case nodeType == "language" || nodeType == "info_string":
+		return TokenType
+	case parentType == "code_fence_content" || nodeType == "code_fence_content" || nodeType == "indented_code_block":
+		return TokenString
+	case nodeType == "html_block" || nodeType == "link_destination" || nodeType == "link_label":
+		return TokenString
+	}
+	return TokenPlain
+}
+
+func classifyHCLLeaf(nodeType string, parentType string) (TokenCategory, bool) {
+	switch {
+	case nodeType == "identifier" && parentType == "block":
+		return TokenKeyword, true
+	case nodeType == "identifier" && parentType == "attribute":
+		return TokenType, true
+	case nodeType == "template_literal" || strings.HasPrefix(nodeType, "quoted_template_") || strings.HasPrefix(nodeType, "heredoc_"):
+		return TokenString, true
+	case strings.HasPrefix(nodeType, "template_interpolation_") || strings.HasPrefix(nodeType, "template_directive_"):
+		return TokenOperator, true
+	}
+	return TokenPlain, false
+}
+
+// classifyMarkupLeaf highlights HTML tags and attributes and CSS selectors
+// and properties.
+func classifyMarkupLeaf(nodeType string) (TokenCategory, bool) {
+	switch nodeType {
+	case "tag_name", "doctype":
+		return TokenKeyword, true
+	case "attribute_name", "class_name", "id_name", "property_name":
+		return TokenType, true
+	case "attribute_value":
+		return TokenString, true
+	case "text", "raw_text":
+		return TokenPlain, true
+	}
+	return TokenPlain, false
+}
+
+// keywordTokenLangs lists grammars whose anonymous word tokens are all
+// keywords.
+var keywordTokenLangs = map[LangID]bool{
+	LangLua:    true,
+	LangElixir: true,
+	LangScala:  true,
+	LangOCaml:  true,
+}
+
+// classifyElixirLeaf highlights definition macros such as defmodule and def,
+// which the grammar parses as ordinary calls, and module aliases.
+func classifyElixirLeaf(nodeType string, parentType string, lexeme string) (TokenCategory, bool) {
+	switch {
+	case nodeType == "identifier" && parentType == "call" && (strings.HasPrefix(lexeme, "def") || lexeme == "alias" || lexeme == "import" || lexeme == "require" || lexeme == "use"):
+		return TokenKeyword, true
+	case nodeType == "alias":
+		return TokenType, true
+	}
+	return TokenPlain, false
+}
+
+func classifyOCamlLeaf(nodeType string, parentType string) (TokenCategory, bool) {
+	switch {
+	case nodeType == "type_constructor" || nodeType == "module_name" || nodeType == "module_type_name" || nodeType == "constructor_name":
+		return TokenType, true
+	case nodeType == "value_name" && parentType == "let_binding":
+		return TokenFunction, true
+	}
+	return TokenPlain, false
+}
+
+func isAlphaWord(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < 'a' || s[i] > 'z') && s[i] != '_' {
+			return false
+		}
+	}
+	return s != ""
+}
+
 func isIdentifierNode(nodeType string) bool {
 	return nodeType == "identifier" || nodeType == "property_identifier" || strings.HasSuffix(nodeType, "identifier") || strings.HasSuffix(nodeType, "name")
 }
@@ -225,6 +349,13 @@ var functionContextByLang = map[LangID]map[string]bool{
 	},
 	LangC:   cFamilyFunctionContexts,
 	LangCPP: cFamilyFunctionContexts,
+	LangProtobuf: {
+		"rpc_name": true,
+	},
+	LangSQL: {
+		"create_function": true,
+		"invocation":      true,
+	},
 }
 
 var typeContextByLang = map[LangID]map[string]bool{
@@ -291,6 +422,14 @@ var typeContextByLang = map[LangID]map[string]bool{
 	LangPython: {
 		"class_definition": true,
 	},
+	LangProtobuf: {
+		"message_name": true,
+		"enum_name":    true,
+		"service_name": true,
+	},
+	LangSQL: {
+		"object_reference": true,
+	},
 }
 
 var jsLikeFunctionContexts = map[string]bool{
@@ -16,13 +16,23 @@ import (
 	clang "github.com/smacker/go-tree-sitter/c"
 	cpplang "github.com/smacker/go-tree-sitter/cpp"
 	csharplang "github.com/smacker/go-tree-sitter/csharp"
+	csslang "github.com/smacker/go-tree-sitter/css"
+	elixirlang "github.com/smacker/go-tree-sitter/elixir"
 	golang "github.com/smacker/go-tree-sitter/golang"
+	hcllang "github.com/smacker/go-tree-sitter/hcl"
+	htmllang "github.com/smacker/go-tree-sitter/html"
 	javalang "github.com/smacker/go-tree-sitter/java"
 	kotlinlang "github.com/smacker/go-tree-sitter/kotlin"
+	lualang "github.com/smacker/go-tree-sitter/lua"
+	markdownlang "github.com/smacker/go-tree-sitter/markdown/tree-sitter-markdown"
+	ocamllang "github.com/smacker/go-tree-sitter/ocaml"
 	phplang "github.com/smacker/go-tree-sitter/php"
+	protobuflang "github.com/smacker/go-tree-sitter/protobuf"
 	python "github.com/smacker/go-tree-sitter/python"
 	rubylang "github.com/smacker/go-tree-sitter/ruby"
 	rust "github.com/smacker/go-tree-sitter/rust"
+	scalalang "github.com/smacker/go-tree-sitter/scala"
+	sqllang "github.com/smacker/go-tree-sitter/sql"
 	swiftlang "github.com/smacker/go-tree-sitter/swift"
 	toml "github.com/smacker/go-tree-sitter/toml"
 	tsxlang "github.com/smacker/go-tree-sitter/typescript/tsx"
@@ -55,6 +65,16 @@ const (
 	LangBash       LangID = lang.Bash
 	LangC          LangID = lang.C
 	LangCPP        LangID = lang.CPP
+	LangMarkdown   LangID = lang.Markdown
+	LangProtobuf   LangID = lang.Protobuf
+	LangSQL        LangID = lang.SQL
+	LangHCL        LangID = lang.HCL
+	LangLua        LangID = lang.Lua
+	LangElixir     LangID = lang.Elixir
+	LangScala      LangID = lang.Scala
+	LangOCaml      LangID = lang.OCaml
+	LangHTML       LangID = lang.HTML
+	LangCSS        LangID = lang.CSS
 )
 
 type HighlightContextMode string
@@ -179,6 +199,9 @@ type HighlighterConfig struct {
 	Root          string
 	DefaultMode   HighlightContextMode
 	ContextRadius int
+	// ReadLines loads a file for file context highlighting. Nil reads it
+	// from disk.
+	ReadLines func(path string) ([]string, error)
 }
 
 type Highlighter struct {
@@ -193,6 +216,7 @@ type Highlighter struct {
 	root          string
 	defaultMode   HighlightContextMode
 	contextRadius int
+	readLines     func(path string) ([]string, error)
 
 	fileMu    sync.RWMutex
 	fileLines map[string][]string
@@ -214,6 +238,11 @@ func NewHighlighter(cfg HighlighterConfig) *Highlighter {
 		contextRadius = 40
 	}
 
+	readLines := cfg.ReadLines
+	if readLines == nil {
+		readLines = readfile.ReadLinesNormalized
+	}
+
 	root := strings.TrimSpace(cfg.Root)
 	if root != "" {
 		if abs, err := filepath.Abs(root); err == nil {
@@ -245,10 +274,21 @@ func NewHighlighter(cfg HighlighterConfig) *Highlighter {
 			LangBash:       bashlang.GetLanguage(),
 			LangC:          clang.GetLanguage(),
 			LangCPP:        cpplang.GetLanguage(),
+			LangMarkdown:   markdownlang.GetLanguage(),
+			LangProtobuf:   protobuflang.GetLanguage(),
+			LangSQL:        sqllang.GetLanguage(),
+			LangHCL:        hcllang.GetLanguage(),
+			LangLua:        lualang.GetLanguage(),
+			LangElixir:     elixirlang.GetLanguage(),
+			LangScala:      scalalang.GetLanguage(),
+			LangOCaml:      ocamllang.GetLanguage(),
+			LangHTML:       htmllang.GetLanguage(),
+			LangCSS:        csslang.GetLanguage(),
 		},
 		root:          root,
 		defaultMode:   mode,
 		contextRadius: contextRadius,
+		readLines:     readLines,
 		fileLines:     make(map[string][]string),
 	}
 
@@ -390,6 +430,10 @@ func (h *Highlighter) highlightFromFileContext(parser *sitter.Parser, req Highli
 
 	startLine := max(1, req.Line-h.contextRadius)
 	endLine := min(len(lines), req.Line+h.contextRadius)
+	if region, ok := embeddedRegionAt(req.File, lines, req.Line); ok {
+		startLine = max(startLine, region.First)
+		endLine = min(endLine, region.Last)
+	}
 	source, targetStart, targetEnd, ok := buildSliceSource(lines[startLine-1:endLine], req.Line-startLine)
 	if !ok {
 		return nil, false
@@ -436,7 +480,7 @@ func (h *Highlighter) loadFileLines(path string) ([]string, error) {
 	}
 	h.fileMu.RUnlock()
 
-	lines, err := readfile.ReadLinesNormalized(path)
+	lines, err := h.readLines(path)
 	if err != nil {
 		return nil, err
 	}
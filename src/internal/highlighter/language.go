@@ -9,3 +9,39 @@ func DetectLanguage(path string) LangID {
 func DetectLanguageWithShebang(path string, firstLine string) LangID {
 	return lang.DetectWithShebang(path, firstLine)
 }
+
+func DetectLanguageContent(path string, lines []string) LangID {
+	return lang.DetectContent(path, lines)
+}
+
+// Region is a script or style block embedded in a component file.
+type Region = lang.Region
+
+// IsComponentLanguage reports whether id is a single-file component format
+// whose lines switch between markup, script and style.
+func IsComponentLanguage(id LangID) bool {
+	return lang.IsComponent(id)
+}
+
+// EmbeddedRegions returns the script and style blocks of a Vue, Svelte or
+// Astro file, or nil when id is not a component language.
+func EmbeddedRegions(id LangID, lines []string) []Region {
+	return lang.EmbeddedRegions(id, lines)
+}
+
+// LineLanguage returns the language a line of a component file is written
+// in. Lines outside every region are highlighted as HTML.
+func LineLanguage(regions []Region, line int) LangID {
+	return lang.RegionLanguage(regions, line, LangHTML)
+}
+
+// embeddedRegionAt finds the region holding line so that file context
+// highlighting does not parse the surrounding markup.
+func embeddedRegionAt(path string, lines []string, line int) (Region, bool) {
+	for _, r := range lang.EmbeddedRegions(lang.Detect(path), lines) {
+		if line >= r.First && line <= r.Last {
+			return r, true
+		}
+	}
+	return Region{}, false
+}
@@ -1,6 +1,7 @@
 package highlighter
 
 import (
+	"reflect"
 	"testing"
 
 	sitter "github.com/smacker/go-tree-sitter"
@@ -25,6 +26,16 @@ func TestAdditionalLanguageTreeSitterHighlighting(t *testing.T) {
 		{name: "php", lang: LangPHP, text: "function search_index() {}"},
 		{name: "ruby", lang: LangRuby, text: "def search_index; end"},
 		{name: "swift", lang: LangSwift, text: "final class ServiceManager {}"},
+		{name: "protobuf", lang: LangProtobuf, text: "message SearchRequest {}"},
+		{name: "protobuf rpc", lang: LangProtobuf, text: "rpc Search(SearchRequest) returns (SearchResponse);"},
+		{name: "hcl", lang: LangHCL, text: `resource "aws_s3_bucket" "logs" {`},
+		{name: "sql", lang: LangSQL, text: "CREATE TABLE search_index (id integer);"},
+		{name: "lua", lang: LangLua, text: "local function search_index(opts)"},
+		{name: "elixir", lang: LangElixir, text: "defmodule Search.Index do"},
+		{name: "scala", lang: LangScala, text: "final case class SearchIndex(id: Int)"},
+		{name: "ocaml", lang: LangOCaml, text: "let rec search_index q ="},
+		{name: "html", lang: LangHTML, text: `<div class="card">`},
+		{name: "css", lang: LangCSS, text: ".card { color: red; }"},
 	}
 
 	for _, tt := range tests {
@@ -52,3 +63,25 @@ func TestAdditionalLanguageTreeSitterHighlighting(t *testing.T) {
 		})
 	}
 }
+
+func TestMarkdownHeadingHighlighting(t *testing.T) {
+	h := NewHighlighter(HighlighterConfig{
+		CacheSize:   8,
+		Workers:     1,
+		DefaultMode: HighlightContextSynthetic,
+	})
+
+	spans := h.HighlightWithParser(sitter.NewParser(), HighlightRequest{
+		Lang: LangMarkdown,
+		Text: "## Rollback procedure",
+		Mode: HighlightContextSynthetic,
+	})
+	want := []Span{
+		{Start: 0, End: 2, Cat: TokenKeyword},
+		{Start: 2, End: 3, Cat: TokenPlain},
+		{Start: 3, End: 21, Cat: TokenType},
+	}
+	if !reflect.DeepEqual(spans, want) {
+		t.Fatalf("spans = %#v, want %#v", spans, want)
+	}
+}
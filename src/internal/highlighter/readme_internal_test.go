@@ -72,6 +72,16 @@ func implementedSupportedLanguages(t *testing.T) []string {
 		LangJSON:       "JSON",
 		LangYAML:       "YAML",
 		LangTOML:       "TOML",
+		LangMarkdown:   "Markdown",
+		LangProtobuf:   "Protobuf",
+		LangSQL:        "SQL",
+		LangHCL:        "HCL",
+		LangLua:        "Lua",
+		LangElixir:     "Elixir",
+		LangScala:      "Scala",
+		LangOCaml:      "OCaml",
+		LangHTML:       "HTML",
+		LangCSS:        "CSS",
 	}
 
 	h := NewHighlighter(HighlighterConfig{
@@ -2,6 +2,7 @@ package highlighter
 
 import (
 	"sort"
+	"strings"
 	"unicode/utf8"
 )
 
@@ -28,6 +29,15 @@ func scaffoldLine(lang LangID, line string) ([]byte, int, int) {
 	case LangJSON:
 		prefix = "{\n"
 		suffix = "\n}\n"
+	case LangProtobuf:
+		prefix = "syntax = \"proto3\";\n"
+		if strings.HasPrefix(strings.TrimSpace(line), "rpc ") {
+			prefix += "service Snav {\n"
+			suffix = "\n}\n"
+		}
+		suffix = closeOpenBraces(line, suffix)
+	case LangHCL:
+		suffix = closeOpenBraces(line, suffix)
 	}
 
 	source := []byte(prefix + line + suffix)
@@ -36,6 +46,16 @@ func scaffoldLine(lang LangID, line string) ([]byte, int, int) {
 	return source, start, end
 }
 
+// closeOpenBraces prepends a closing brace to suffix for every block the line
+// leaves open, so a block header parses as a complete block.
+func closeOpenBraces(line string, suffix string) string {
+	open := strings.Count(line, "{") - strings.Count(line, "}")
+	if open <= 0 {
+		return suffix
+	}
+	return "\n" + strings.Repeat("}\n", open) + strings.TrimPrefix(suffix, "\n")
+}
+
 func plainSpans(text string) []Span {
 	runeLen := utf8.RuneCountInString(text)
 	if runeLen == 0 {
@@ -0,0 +1,102 @@
+package lang
+
+import "strings"
+
+// Region is a block of lines in a single-file component that is written in
+// another language, such as the body of a <script> or <style> element. First
+// and Last are 1-based and inclusive and exclude the enclosing tags.
+type Region struct {
+	First int
+	Last  int
+	Lang  ID
+}
+
+// IsComponent reports whether id is a single-file component format.
+func IsComponent(id ID) bool {
+	return id == Vue || id == Svelte || id == Astro
+}
+
+// EmbeddedRegions finds the script and style blocks of a Vue, Svelte or Astro
+// component, and the frontmatter of an Astro component. It returns nil for
+// other languages.
+func EmbeddedRegions(id ID, lines []string) []Region {
+	if !IsComponent(id) {
+		return nil
+	}
+
+	var regions []Region
+	start := 0
+	if id == Astro && len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				regions = append(regions, Region{First: 2, Last: i, Lang: TypeScript})
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		var tag string
+		var lang ID
+		switch {
+		case hasTagPrefix(trimmed, "<script"):
+			tag, lang = "</script", scriptLanguage(id, trimmed)
+		case hasTagPrefix(trimmed, "<style"):
+			tag, lang = "</style", CSS
+		default:
+			continue
+		}
+		if strings.Contains(trimmed, tag) {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), tag) {
+			end++
+		}
+		if end > i+1 {
+			regions = append(regions, Region{First: i + 2, Last: end, Lang: lang})
+		}
+		i = end
+	}
+	return regions
+}
+
+// RegionLanguage returns the language of a 1-based line, or markup when the
+// line is outside every region.
+func RegionLanguage(regions []Region, line int, markup ID) ID {
+	for _, r := range regions {
+		if line >= r.First && line <= r.Last {
+			return r.Lang
+		}
+	}
+	return markup
+}
+
+func hasTagPrefix(s string, tag string) bool {
+	rest, ok := strings.CutPrefix(s, tag)
+	return ok && (rest == "" || rest[0] == '>' || rest[0] == ' ' || rest[0] == '\t')
+}
+
+// scriptLanguage reads the lang attribute of a <script> tag. Astro scripts
+// are TypeScript by default.
+func scriptLanguage(id ID, tag string) ID {
+	for _, quote := range []string{`"`, `'`} {
+		if _, rest, ok := strings.Cut(tag, " lang="+quote); ok {
+			value, _, _ := strings.Cut(rest, quote)
+			switch strings.ToLower(value) {
+			case "ts", "typescript":
+				return TypeScript
+			case "tsx":
+				return TSX
+			case "jsx":
+				return JavaScript
+			}
+		}
+	}
+	if id == Astro {
+		return TypeScript
+	}
+	return JavaScript
+}
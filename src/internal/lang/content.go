@@ -0,0 +1,180 @@
+package lang
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ambiguousExts are C-family extensions whose language depends on content.
+var ambiguousExts = map[string]bool{
+	".h":   true,
+	".inc": true,
+	".def": true,
+	".td":  true,
+}
+
+// contentScanLines bounds how much of a file the heuristics read.
+const contentScanLines = 400
+
+// modelineLines is how many lines at either end of a file may hold a vim
+// modeline.
+const modelineLines = 5
+
+// names maps language names used by modelines and linguist to IDs.
+var names = map[string]ID{
+	"go":               Go,
+	"golang":           Go,
+	"rust":             Rust,
+	"rs":               Rust,
+	"zig":              Zig,
+	"c#":               CSharp,
+	"csharp":           CSharp,
+	"cs":               CSharp,
+	"java":             Java,
+	"kotlin":           Kotlin,
+	"kt":               Kotlin,
+	"php":              PHP,
+	"ruby":             Ruby,
+	"rb":               Ruby,
+	"python":           Python,
+	"py":               Python,
+	"javascript":       JavaScript,
+	"js":               JavaScript,
+	"js2":              JavaScript,
+	"typescript":       TypeScript,
+	"ts":               TypeScript,
+	"tsx":              TSX,
+	"swift":            Swift,
+	"yaml":             YAML,
+	"yml":              YAML,
+	"toml":             TOML,
+	"json":             JSON,
+	"bash":             Bash,
+	"sh":               Bash,
+	"shell":            Bash,
+	"shell-script":     Bash,
+	"zsh":              Bash,
+	"c":                C,
+	"c++":              CPP,
+	"cpp":              CPP,
+	"objc":             ObjC,
+	"objective-c":      ObjC,
+	"objective-c++":    ObjC,
+	"objcpp":           ObjC,
+	"markdown":         Markdown,
+	"md":               Markdown,
+	"rst":              RST,
+	"restructuredtext": RST,
+	"asciidoc":         AsciiDoc,
+	"protobuf":         Protobuf,
+	"proto":            Protobuf,
+	"protocol-buffer":  Protobuf,
+	"graphql":          GraphQL,
+	"sql":              SQL,
+	"hcl":              HCL,
+	"terraform":        HCL,
+	"lua":              Lua,
+	"elixir":           Elixir,
+	"scala":            Scala,
+	"haskell":          Haskell,
+	"ocaml":            OCaml,
+	"dart":             Dart,
+	"vue":              Vue,
+	"svelte":           Svelte,
+	"astro":            Astro,
+	"html":             HTML,
+	"css":              CSS,
+}
+
+// LookupName returns the language for a modeline or linguist language name.
+func LookupName(name string) (ID, bool) {
+	id, ok := names[strings.ToLower(strings.TrimSpace(name))]
+	return id, ok
+}
+
+// DetectContent detects the language of path from its name and content. A
+// vim or emacs modeline wins over the extension, ambiguous C-family headers
+// are resolved by their content, and files without a known extension fall
+// back to their shebang.
+func DetectContent(path string, lines []string) ID {
+	if id, ok := modelineLanguage(lines); ok {
+		return id
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ambiguousExts[ext] {
+		return cFamilyLanguage(lines)
+	}
+	if id := Detect(path); id != Plain {
+		return id
+	}
+	if len(lines) == 0 {
+		return Plain
+	}
+	return shebangLanguage(lines[0])
+}
+
+// cFamilyLanguage tells C, C++ and Objective-C headers apart.
+func cFamilyLanguage(lines []string) ID {
+	id := C
+	for i, line := range lines {
+		if i == contentScanLines {
+			break
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "@interface") || strings.HasPrefix(trimmed, "@protocol") || strings.HasPrefix(trimmed, "@implementation") || strings.HasPrefix(trimmed, "#import"):
+			return ObjC
+		case hasWordPrefix(trimmed, "class") || hasWordPrefix(trimmed, "namespace") || strings.HasPrefix(trimmed, "template<") || hasWordPrefix(trimmed, "template") || strings.HasPrefix(trimmed, "using namespace"):
+			id = CPP
+		}
+	}
+	return id
+}
+
+func hasWordPrefix(s string, word string) bool {
+	rest, ok := strings.CutPrefix(s, word)
+	return ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t')
+}
+
+var (
+	vimModeline   = regexp.MustCompile(`(?:^|\s)(?:vim?|ex):.*?\b(?:ft|filetype|syntax)=([A-Za-z0-9_+.#-]+)`)
+	emacsModeline = regexp.MustCompile(`-\*-(.*?)-\*-`)
+)
+
+// modelineLanguage reads a vim modeline from the first or last lines, or an
+// emacs -*- mode -*- line from the first two lines.
+func modelineLanguage(lines []string) (ID, bool) {
+	for i := 0; i < len(lines) && i < 2; i++ {
+		if m := emacsModeline.FindStringSubmatch(lines[i]); m != nil {
+			if id, ok := emacsMode(m[1]); ok {
+				return id, true
+			}
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		if i == modelineLines && len(lines)-modelineLines > i {
+			i = len(lines) - modelineLines
+		}
+		if m := vimModeline.FindStringSubmatch(lines[i]); m != nil {
+			if id, ok := LookupName(m[1]); ok {
+				return id, true
+			}
+		}
+	}
+	return "", false
+}
+
+func emacsMode(vars string) (ID, bool) {
+	if !strings.Contains(vars, ":") {
+		return LookupName(strings.TrimSuffix(strings.TrimSpace(vars), "-mode"))
+	}
+	for _, field := range strings.Split(vars, ";") {
+		key, value, ok := strings.Cut(field, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "mode") {
+			return LookupName(strings.TrimSuffix(strings.TrimSpace(value), "-mode"))
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,134 @@
+package lang
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Overrides holds the linguist-language and linguist-generated attributes of
+// a repository's root .gitattributes file.
+type Overrides struct {
+	rules     []overrideRule
+	generated []generatedRule
+}
+
+type overrideRule struct {
+	pattern string
+	lang    ID
+}
+
+type generatedRule struct {
+	pattern   string
+	generated bool
+}
+
+// LoadOverrides reads root/.gitattributes. A missing file yields no
+// overrides.
+func LoadOverrides(root string) (*Overrides, error) {
+	data, err := os.ReadFile(filepath.Join(root, ".gitattributes"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Overrides{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read .gitattributes: %w", err)
+	}
+	return ParseOverrides(string(data)), nil
+}
+
+// ParseOverrides parses .gitattributes content, keeping the patterns that set
+// linguist-language to a known language or set or unset linguist-generated.
+func ParseOverrides(data string) *Overrides {
+	o := &Overrides{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			switch attr {
+			case "linguist-generated", "linguist-generated=true":
+				o.generated = append(o.generated, generatedRule{pattern: fields[0], generated: true})
+				continue
+			case "-linguist-generated", "linguist-generated=false":
+				o.generated = append(o.generated, generatedRule{pattern: fields[0], generated: false})
+				continue
+			}
+			name, ok := strings.CutPrefix(attr, "linguist-language=")
+			if !ok {
+				continue
+			}
+			if id, ok := LookupName(name); ok {
+				o.rules = append(o.rules, overrideRule{pattern: fields[0], lang: id})
+			}
+		}
+	}
+	return o
+}
+
+// Lookup returns the language set for the slash-separated path relative to
+// the root. As in git, the last matching pattern wins.
+func (o *Overrides) Lookup(rel string) (ID, bool) {
+	if o == nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	for i := len(o.rules) - 1; i >= 0; i-- {
+		if MatchPattern(o.rules[i].pattern, rel) {
+			return o.rules[i].lang, true
+		}
+	}
+	return "", false
+}
+
+// Generated reports whether linguist-generated is set or unset for the
+// slash-separated path relative to the root. ok is false when no pattern
+// mentions the attribute.
+func (o *Overrides) Generated(rel string) (generated bool, ok bool) {
+	if o == nil {
+		return false, false
+	}
+	rel = filepath.ToSlash(rel)
+	for i := len(o.generated) - 1; i >= 0; i-- {
+		if MatchPattern(o.generated[i].pattern, rel) {
+			return o.generated[i].generated, true
+		}
+	}
+	return false, false
+}
+
+// MatchPattern matches a gitattributes or ripgrep glob pattern. Patterns
+// without a slash match the file name at any depth; others are anchored at
+// the root, where ** matches any number of directories.
+func MatchPattern(pattern string, rel string) bool {
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern []string, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(parts); i >= 0; i-- {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -28,47 +28,104 @@ const (
 	Bash       ID = "bash"
 	C          ID = "c"
 	CPP        ID = "cpp"
+	Markdown   ID = "markdown"
+	RST        ID = "rst"
+	AsciiDoc   ID = "asciidoc"
+	Protobuf   ID = "protobuf"
+	GraphQL    ID = "graphql"
+	SQL        ID = "sql"
+	HCL        ID = "hcl"
+	Lua        ID = "lua"
+	Elixir     ID = "elixir"
+	Scala      ID = "scala"
+	Haskell    ID = "haskell"
+	OCaml      ID = "ocaml"
+	Dart       ID = "dart"
+	ObjC       ID = "objc"
+	Vue        ID = "vue"
+	Svelte     ID = "svelte"
+	Astro      ID = "astro"
+	HTML       ID = "html"
+	CSS        ID = "css"
+	Jupyter    ID = "jupyter"
 )
 
 var extMap = map[string]ID{
-	".go":    Go,
-	".rs":    Rust,
-	".zig":   Zig,
-	".cs":    CSharp,
-	".csx":   CSharp,
-	".java":  Java,
-	".kt":    Kotlin,
-	".kts":   Kotlin,
-	".php":   PHP,
-	".php4":  PHP,
-	".php5":  PHP,
-	".phtml": PHP,
-	".rb":    Ruby,
-	".py":    Python,
-	".js":    JavaScript,
-	".jsx":   JavaScript,
-	".mjs":   JavaScript,
-	".cjs":   JavaScript,
-	".ts":    TypeScript,
-	".tsx":   TSX,
-	".swift": Swift,
-	".yaml":  YAML,
-	".yml":   YAML,
-	".toml":  TOML,
-	".json":  JSON,
-	".jsonc": JSON,
-	".json5": JSON,
-	".sh":    Bash,
-	".bash":  Bash,
-	".zsh":   Bash,
-	".c":     C,
-	".h":     C,
-	".cpp":   CPP,
-	".cc":    CPP,
-	".cxx":   CPP,
-	".hpp":   CPP,
-	".hh":    CPP,
-	".hxx":   CPP,
+	".go":       Go,
+	".rs":       Rust,
+	".zig":      Zig,
+	".cs":       CSharp,
+	".csx":      CSharp,
+	".java":     Java,
+	".kt":       Kotlin,
+	".kts":      Kotlin,
+	".php":      PHP,
+	".php4":     PHP,
+	".php5":     PHP,
+	".phtml":    PHP,
+	".rb":       Ruby,
+	".py":       Python,
+	".pyi":      Python,
+	".js":       JavaScript,
+	".jsx":      JavaScript,
+	".mjs":      JavaScript,
+	".cjs":      JavaScript,
+	".ts":       TypeScript,
+	".tsx":      TSX,
+	".swift":    Swift,
+	".yaml":     YAML,
+	".yml":      YAML,
+	".toml":     TOML,
+	".json":     JSON,
+	".jsonc":    JSON,
+	".json5":    JSON,
+	".sh":       Bash,
+	".bash":     Bash,
+	".zsh":      Bash,
+	".c":        C,
+	".h":        C,
+	".inc":      C,
+	".def":      C,
+	".td":       C,
+	".cpp":      CPP,
+	".cc":       CPP,
+	".cxx":      CPP,
+	".hpp":      CPP,
+	".hh":       CPP,
+	".hxx":      CPP,
+	".md":       Markdown,
+	".markdown": Markdown,
+	".rst":      RST,
+	".adoc":     AsciiDoc,
+	".asciidoc": AsciiDoc,
+	".proto":    Protobuf,
+	".graphql":  GraphQL,
+	".graphqls": GraphQL,
+	".gql":      GraphQL,
+	".sql":      SQL,
+	".tf":       HCL,
+	".tfvars":   HCL,
+	".hcl":      HCL,
+	".lua":      Lua,
+	".ex":       Elixir,
+	".exs":      Elixir,
+	".scala":    Scala,
+	".sc":       Scala,
+	".sbt":      Scala,
+	".hs":       Haskell,
+	".lhs":      Haskell,
+	".ml":       OCaml,
+	".mli":      OCaml,
+	".dart":     Dart,
+	".m":        ObjC,
+	".mm":       ObjC,
+	".vue":      Vue,
+	".svelte":   Svelte,
+	".astro":    Astro,
+	".html":     HTML,
+	".htm":      HTML,
+	".css":      CSS,
+	".ipynb":    Jupyter,
 }
 
 var fileMap = map[string]ID{
@@ -95,11 +152,13 @@ func Detect(path string) ID {
 	return Plain
 }
 
+// DetectWithShebang is DetectContent for callers that only have the first
+// line of a file.
 func DetectWithShebang(path string, firstLine string) ID {
-	if id := Detect(path); id != Plain {
-		return id
-	}
+	return DetectContent(path, []string{firstLine})
+}
 
+func shebangLanguage(firstLine string) ID {
 	if !strings.HasPrefix(firstLine, "#!") {
 		return Plain
 	}
@@ -111,6 +170,10 @@ func DetectWithShebang(path string, firstLine string) ID {
 		return PHP
 	case strings.Contains(lower, "python"):
 		return Python
+	case strings.Contains(lower, "lua"):
+		return Lua
+	case strings.Contains(lower, "elixir"):
+		return Elixir
 	case strings.Contains(lower, "bash") || strings.Contains(lower, "zsh") || strings.Contains(lower, "sh"):
 		return Bash
 	case strings.Contains(lower, "node"):
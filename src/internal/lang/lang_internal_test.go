@@ -1,9 +1,141 @@
 package lang
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestDetectSwiftByExtension(t *testing.T) {
 	if got := Detect("App/Core/Service.swift"); got != Swift {
 		t.Fatalf("Detect(.swift) = %q, want %q", got, Swift)
 	}
 }
+
+func TestDetectWithShebangLuaAndElixir(t *testing.T) {
+	if got := DetectWithShebang("bin/fmt", "#!/usr/bin/env lua"); got != Lua {
+		t.Fatalf("DetectWithShebang(lua) = %q, want %q", got, Lua)
+	}
+	if got := DetectWithShebang("bin/release", "#!/usr/bin/env elixir"); got != Elixir {
+		t.Fatalf("DetectWithShebang(elixir) = %q, want %q", got, Elixir)
+	}
+}
+
+func TestDetectContent(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		lines []string
+		want  ID
+	}{
+		{name: "c header", path: "point.h", lines: []string{"struct point { int x; };"}, want: C},
+		{name: "cpp header", path: "widget.h", lines: []string{"#pragma once", "namespace ui {", "class Widget;"}, want: CPP},
+		{name: "cpp template", path: "vec.inc", lines: []string{"template <typename T>", "T max(T a, T b);"}, want: CPP},
+		{name: "objc header", path: "Store.h", lines: []string{"#import <Foundation/Foundation.h>", "@interface Store : NSObject"}, want: ObjC},
+		{name: "vim modeline", path: "ops.def", lines: []string{"// vim: set ft=cpp:", "OP(add)"}, want: CPP},
+		{name: "trailing vim modeline", path: "run", lines: []string{"a", "b", "c", "d", "e", "f", "g", "# vim: ft=python"}, want: Python},
+		{name: "emacs modeline", path: "build.txt", lines: []string{"# -*- mode: ruby; indent-tabs-mode: nil -*-"}, want: Ruby},
+		{name: "emacs short modeline", path: "widget.h", lines: []string{"/* -*- C++ -*- */"}, want: CPP},
+		{name: "shebang", path: "bin/tool", lines: []string{"#!/usr/bin/env python3"}, want: Python},
+		{name: "extension", path: "main.go", lines: []string{"package main"}, want: Go},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectContent(tt.path, tt.lines); got != tt.want {
+				t.Fatalf("DetectContent(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverridesLookup(t *testing.T) {
+	o := ParseOverrides(`# generated
+*.h linguist-language=C++
+vendor/**/*.h linguist-language=Objective-C
+/tools/*.inc linguist-language=cpp
+*.txt text eol=lf
+`)
+	tests := []struct {
+		path string
+		want ID
+		ok   bool
+	}{
+		{path: "src/widget.h", want: CPP, ok: true},
+		{path: "vendor/sdk/include/Store.h", want: ObjC, ok: true},
+		{path: "tools/ops.inc", want: CPP, ok: true},
+		{path: "lib/tools/ops.inc"},
+		{path: "notes.txt"},
+	}
+	for _, tt := range tests {
+		got, ok := o.Lookup(tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Fatalf("Lookup(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestOverridesGenerated(t *testing.T) {
+	o := ParseOverrides(`api/** linguist-generated
+api/handwritten.go -linguist-generated
+*.snap linguist-generated=true linguist-language=JSON
+`)
+	tests := []struct {
+		path string
+		want bool
+		ok   bool
+	}{
+		{path: "api/v1/types.go", want: true, ok: true},
+		{path: "api/handwritten.go", want: false, ok: true},
+		{path: "ui/__snapshots__/app.snap", want: true, ok: true},
+		{path: "cmd/main.go"},
+	}
+	for _, tt := range tests {
+		got, ok := o.Generated(tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Fatalf("Generated(%q) = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+	if id, ok := o.Lookup("ui/__snapshots__/app.snap"); !ok || id != JSON {
+		t.Fatalf("Lookup(app.snap) = %q, %v, want JSON", id, ok)
+	}
+}
+
+func TestEmbeddedRegions(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    ID
+		lines []string
+		want  []Region
+	}{
+		{
+			name:  "vue",
+			id:    Vue,
+			lines: []string{"<template><div/></template>", `<script setup lang="ts">`, "const a = 1", "</script>", "<style scoped>", ".a {}", "</style>"},
+			want:  []Region{{First: 3, Last: 3, Lang: TypeScript}, {First: 6, Last: 6, Lang: CSS}},
+		},
+		{
+			name:  "svelte",
+			id:    Svelte,
+			lines: []string{"<script context=\"module\">", "export let x", "</script>", "<script lang='tsx'>", "let y", "</script>"},
+			want:  []Region{{First: 2, Last: 2, Lang: JavaScript}, {First: 5, Last: 5, Lang: TSX}},
+		},
+		{
+			name:  "astro",
+			id:    Astro,
+			lines: []string{"---", "const t = 1", "---", "<h1>{t}</h1>", "<script>", "go()", "</script>"},
+			want:  []Region{{First: 2, Last: 2, Lang: TypeScript}, {First: 6, Last: 6, Lang: TypeScript}},
+		},
+		{
+			name:  "not a component",
+			id:    HTML,
+			lines: []string{"<script>", "go()", "</script>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EmbeddedRegions(tt.id, tt.lines)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("EmbeddedRegions = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
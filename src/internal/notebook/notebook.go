@@ -0,0 +1,177 @@
+// Package notebook reads the cells of Jupyter notebooks.
+package notebook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"snav/internal/lang"
+)
+
+// Cell is one notebook cell. Source lines have their trailing newlines
+// removed.
+type Cell struct {
+	// Index is the 1-based position of the cell among all cells.
+	Index  int
+	Kind   string
+	Source []string
+	// RawLines holds the line of the notebook file each source line is
+	// stored on, or 0 when the notebook does not keep one source line per
+	// file line.
+	RawLines []int
+}
+
+// Notebook is a parsed .ipynb file.
+type Notebook struct {
+	// Lang is the kernel language of the code cells.
+	Lang  lang.ID
+	Cells []Cell
+}
+
+type rawNotebook struct {
+	Cells []struct {
+		CellType string          `json:"cell_type"`
+		Source   json.RawMessage `json:"source"`
+	} `json:"cells"`
+	Metadata struct {
+		Kernelspec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+// Load reads and parses the notebook at path.
+func Load(path string) (*Notebook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses notebook JSON. Code cells default to Python when the kernel
+// language is missing or unknown.
+func Parse(data []byte) (*Notebook, error) {
+	var raw rawNotebook
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse notebook: %w", err)
+	}
+
+	nb := &Notebook{Lang: lang.Python}
+	for _, name := range []string{raw.Metadata.Kernelspec.Language, raw.Metadata.LanguageInfo.Name} {
+		if id, ok := lang.LookupName(name); ok {
+			nb.Lang = id
+			break
+		}
+	}
+
+	locator := newLineLocator(data)
+	for i, c := range raw.Cells {
+		cell := Cell{Index: i + 1, Kind: c.CellType}
+		var parts []string
+		if err := json.Unmarshal(c.Source, &parts); err != nil {
+			var whole string
+			if err := json.Unmarshal(c.Source, &whole); err != nil {
+				return nil, fmt.Errorf("parse notebook cell %d: %w", i+1, err)
+			}
+			parts = strings.SplitAfter(whole, "\n")
+			cell.Source = trimNewlines(parts)
+			cell.RawLines = make([]int, len(cell.Source))
+		} else {
+			cell.Source = trimNewlines(parts)
+			cell.RawLines = locator.locate(parts)
+		}
+		nb.Cells = append(nb.Cells, cell)
+	}
+	return nb, nil
+}
+
+// Cell returns the cell with the given 1-based index.
+func (nb *Notebook) Cell(index int) (Cell, bool) {
+	if nb == nil || index < 1 || index > len(nb.Cells) {
+		return Cell{}, false
+	}
+	return nb.Cells[index-1], true
+}
+
+// RawLine maps a 1-based line of the cell source to its line in the notebook
+// file, falling back to line 1.
+func (c Cell) RawLine(line int) int {
+	if line >= 1 && line <= len(c.RawLines) && c.RawLines[line-1] > 0 {
+		return c.RawLines[line-1]
+	}
+	return 1
+}
+
+// RawColumn maps a 1-based byte column of a source line to its byte column
+// in rawLine, the notebook file line holding the line as a JSON string.
+func RawColumn(rawLine string, source string, col int) int {
+	quote := strings.IndexByte(rawLine, '"')
+	if quote < 0 || col < 1 {
+		return 1
+	}
+	prefix := source[:min(col-1, len(source))]
+	return quote + len(encodeString(prefix))
+}
+
+func trimNewlines(parts []string) []string {
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		out = append(out, strings.TrimSuffix(strings.TrimSuffix(part, "\n"), "\r"))
+	}
+	return out
+}
+
+// lineLocator finds the file lines of source strings. Notebooks written by
+// Jupyter store each element of a source array on its own line, in order, so
+// a forward search for each encoded string finds it.
+type lineLocator struct {
+	lines [][]byte
+	next  int
+}
+
+func newLineLocator(data []byte) *lineLocator {
+	return &lineLocator{lines: bytes.Split(data, []byte("\n"))}
+}
+
+func (l *lineLocator) locate(parts []string) []int {
+	out := make([]int, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		out = append(out, l.find(encodeString(part)))
+	}
+	return out
+}
+
+func (l *lineLocator) find(encoded []byte) int {
+	for i := l.next; i < len(l.lines); i++ {
+		trimmed := bytes.TrimSpace(l.lines[i])
+		trimmed = bytes.TrimSuffix(trimmed, []byte(","))
+		if bytes.Equal(trimmed, encoded) {
+			l.next = i + 1
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func encodeString(s string) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return nil
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
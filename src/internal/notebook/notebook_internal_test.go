@@ -0,0 +1,101 @@
+package notebook
+
+import (
+	"reflect"
+	"testing"
+
+	"snav/internal/lang"
+)
+
+const testNotebook = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "source": ["# Title\n", "<b>bold</b>"]
+  },
+  {
+   "cell_type": "code",
+   "source": [
+    "import os\n",
+    "\n",
+    "def load(path):\n",
+    "    return open(path)"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "source": "x = 1\ny = 2\n"
+  }
+ ],
+ "metadata": {"kernelspec": {"language": "python"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`
+
+func TestParse(t *testing.T) {
+	nb, err := Parse([]byte(testNotebook))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if nb.Lang != lang.Python || len(nb.Cells) != 3 {
+		t.Fatalf("got lang %q and %d cells", nb.Lang, len(nb.Cells))
+	}
+
+	code, ok := nb.Cell(2)
+	if !ok || code.Kind != "code" {
+		t.Fatalf("Cell(2) = %#v, %v", code, ok)
+	}
+	if want := []string{"import os", "", "def load(path):", "    return open(path)"}; !reflect.DeepEqual(code.Source, want) {
+		t.Fatalf("Source = %#v, want %#v", code.Source, want)
+	}
+	if want := []int{10, 11, 12, 13}; !reflect.DeepEqual(code.RawLines, want) {
+		t.Fatalf("RawLines = %#v, want %#v", code.RawLines, want)
+	}
+
+	str, _ := nb.Cell(3)
+	if want := []string{"x = 1", "y = 2"}; !reflect.DeepEqual(str.Source, want) {
+		t.Fatalf("string Source = %#v, want %#v", str.Source, want)
+	}
+	if got := str.RawLine(2); got != 1 {
+		t.Fatalf("RawLine for a string source = %d, want 1", got)
+	}
+	if _, ok := nb.Cell(4); ok {
+		t.Fatalf("Cell(4) should not exist")
+	}
+}
+
+func TestParseKernelLanguage(t *testing.T) {
+	nb, err := Parse([]byte(`{"cells": [], "metadata": {"language_info": {"name": "R"}, "kernelspec": {"language": "julia"}}}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if nb.Lang != lang.Python {
+		t.Fatalf("unknown kernel language = %q, want python", nb.Lang)
+	}
+
+	nb, err = Parse([]byte(`{"cells": [], "metadata": {"language_info": {"name": "scala"}}}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if nb.Lang != lang.Scala {
+		t.Fatalf("kernel language = %q, want scala", nb.Lang)
+	}
+}
+
+func TestRawColumn(t *testing.T) {
+	tests := []struct {
+		raw    string
+		source string
+		col    int
+		want   int
+	}{
+		{raw: `    "def load(path):\n",`, source: "def load(path):", col: 5, want: 10},
+		{raw: `    "msg = \"hi\"; def run():\n",`, source: `msg = "hi"; def run():`, col: 17, want: 24},
+		{raw: `    "名前 = 1; x = 2\n",`, source: "名前 = 1; x = 2", col: 13, want: 18},
+	}
+	for _, tt := range tests {
+		if got := RawColumn(tt.raw, tt.source, tt.col); got != tt.want {
+			t.Fatalf("RawColumn(%q, %d) = %d, want %d", tt.raw, tt.col, got, tt.want)
+		}
+	}
+}
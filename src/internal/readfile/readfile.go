@@ -1,6 +1,9 @@
 package readfile
 
 import (
+	"bufio"
+	"errors"
+	"io"
 	"os"
 	"strings"
 )
@@ -10,7 +13,36 @@ func ReadLinesNormalized(path string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return SplitLinesNormalized(data), nil
+}
+
+// ReadHeadNormalized reads at most the first n lines of a file, split the
+// way ReadLinesNormalized does, without reading the rest of it.
+func ReadHeadNormalized(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	lines := make([]string, 0, n)
+	for len(lines) < n {
+		line, err := r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if err != nil {
+			return append(lines, line), nil
+		}
+		lines = append(lines, strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+	}
+	return lines, nil
+}
 
+// SplitLinesNormalized splits file content into lines the way
+// ReadLinesNormalized does.
+func SplitLinesNormalized(data []byte) []string {
 	normalized := strings.ReplaceAll(string(data), "\r\n", "\n")
-	return strings.Split(normalized, "\n"), nil
+	return strings.Split(normalized, "\n")
 }
@@ -57,3 +57,27 @@ func TestReadLinesNormalized(t *testing.T) {
 		})
 	}
 }
+
+func TestReadHeadNormalized(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte("one\r\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	for n, want := range map[int][]string{
+		2: {"one", "two"},
+		5: {"one", "two", "three", ""},
+	} {
+		got, err := ReadHeadNormalized(path, n)
+		if err != nil {
+			t.Fatalf("ReadHeadNormalized: %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("ReadHeadNormalized(%d) = %q, want %q", n, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("ReadHeadNormalized(%d) = %q, want %q", n, got, want)
+			}
+		}
+	}
+}
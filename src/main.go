@@ -8,11 +8,14 @@ import (
 	"path/filepath"
 	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"snav/internal/candidate"
 	"snav/internal/highlighter"
+	"snav/internal/notebook"
 	"snav/internal/readfile"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -22,6 +25,7 @@ import (
 
 type config struct {
 	Root          string
+	Workspace     string
 	Pattern       string
 	Preview       bool
 	CacheSize     int
@@ -32,19 +36,45 @@ type config struct {
 	ContextRadius int
 	EditorCmd     string
 	NoIgnore      bool
-	ExcludeTests  bool
-	Theme         string
+	Hidden        bool
+	// Rev indexes the roots as of a git revision instead of the working
+	// tree.
+	Rev string
+	// Deps also indexes the sources of the project's dependencies.
+	Deps         bool
+	ExcludeTests bool
+	// ExcludeGenerated skips generated files during the scan.
+	ExcludeGenerated bool
+	Members          bool
+	MaxFileSize      string
+	MaxFileSymbols   int
+	Theme            string
 }
 
 type previewState struct {
+	// File is the candidate's path within Root; Path is where it is read
+	// from.
 	File         string
+	Root         string
+	Path         string
 	Lang         highlighter.LangID
 	StartLine    int
 	Lines        []string
 	SelectedLine int
+	Regions      []highlighter.Region
+	Cell         int
 	Err          string
 }
 
+// lineLang returns the language of a preview line, which varies by embedded
+// region in component files.
+func (p previewState) lineLang(lineNo int) highlighter.LangID {
+	if highlighter.IsComponentLanguage(p.Lang) {
+		return highlighter.LineLanguage(p.Regions, lineNo)
+	}
+	return p.Lang
+}
+
 type model struct {
 	cfg config
 
@@ -62,12 +92,30 @@ type model struct {
 	cursor int
 	offset int
 
-	producerOut     <-chan []candidate.Candidate
-	producerDone    <-chan error
-	scanDone        bool
-	producerCfg     candidate.ProducerConfig
-	rebuildFromScan bool
-	scanCandidates  []candidate.Candidate
+	producerOut  <-chan []candidate.Candidate
+	producerDone <-chan candidate.ScanResult
+	scanDone     bool
+	producerCfg  candidate.ProducerConfig
+	roots        []workspaceRoot
+	// rebuildRoots holds the roots whose shown candidates are replaced when
+	// their scan finishes. scanned collects the candidates of the running
+	// scan by root, for those roots and, with several roots, to save each
+	// root's index.
+	rebuildRoots map[string]bool
+	scanned      map[string][]candidate.Candidate
+	scanSkipped  candidate.SkipStats
+	nextID       int
+	// scanCtx is the parent of every scan; cancelScan stops the running one
+	// when a toggle restarts it. scanIndexes keeps the finished index of each
+	// config scanned this session.
+	scanCtx     context.Context
+	cancelScan  context.CancelFunc
+	scanIndexes map[string][]candidate.Candidate
+	// pendingDeps holds resolved dependency roots until the running scan
+	// finishes, as a scan saves each root's index by the roots it started
+	// with. sharedRoots publishes m.roots to the highlighter's workers.
+	pendingDeps []workspaceRoot
+	sharedRoots *atomic.Pointer[[]workspaceRoot]
 
 	highlighter *highlighter.Highlighter
 
@@ -76,11 +124,23 @@ type model struct {
 	resetSelectionOnFilter bool
 	lastFilterQueryRunes   []rune
 	lastFilterCandidateN   int
+	// hideGenerated and hideTests drop candidates from generated files and
+	// test code from the results.
+	hideGenerated bool
+	hideTests     bool
+
+	// diff is set when browsing the changes between two revisions.
+	// diffChanges maps candidate IDs to their change, and diffPreview shows
+	// the older version of the selected symbol.
+	diff        *symbolDiff
+	diffChanges map[int]candidate.SymbolChange
+	diffPreview previewState
 
 	previewEnabled bool
 	preview        previewState
 	fileCache      map[string][]string
 	fileLangCache  map[string]highlighter.LangID
+	notebookCache  map[string]*notebook.Notebook
 	previewKey     string
 
 	status string
@@ -89,6 +149,12 @@ type model struct {
 
 type tickMsg struct{}
 
+// dependenciesMsg reports the dependency roots resolved in the background.
+type dependenciesMsg struct {
+	roots []workspaceRoot
+	err   error
+}
+
 const (
 	producerDrainItemsDefault = 4000
 	producerDrainItemsStartup = 0
@@ -100,7 +166,10 @@ func printUsageWithLongFlags(fs *flag.FlagSet, program string) {
 	if _, err := fmt.Fprintf(out, "Usage of %s:\n", name); err != nil {
 		fatalf("write usage: %v", err)
 	}
-	if _, err := fmt.Fprintf(out, "  %s [flags] [root]\n", name); err != nil {
+	if _, err := fmt.Fprintf(out, "  %s [flags] [root...]\n", name); err != nil {
+		fatalf("write usage: %v", err)
+	}
+	if _, err := fmt.Fprintf(out, "  %s diff [flags] <from> <to>\n", name); err != nil {
 		fatalf("write usage: %v", err)
 	}
 	if _, err := fmt.Fprintf(out, "  %s update\n\n", name); err != nil {
@@ -109,6 +178,9 @@ func printUsageWithLongFlags(fs *flag.FlagSet, program string) {
 	if _, err := fmt.Fprintln(out, "Commands:"); err != nil {
 		fatalf("write usage: %v", err)
 	}
+	if _, err := fmt.Fprintln(out, "  diff    list the symbols added, removed, changed or moved between two git revisions"); err != nil {
+		fatalf("write usage: %v", err)
+	}
 	if _, err := fmt.Fprintln(out, "  update  reinstall the latest release into the current executable directory"); err != nil {
 		fatalf("write usage: %v", err)
 	}
@@ -140,7 +212,7 @@ func tickCmd() tea.Cmd {
 	return tea.Tick(16*time.Millisecond, func(time.Time) tea.Msg { return tickMsg{} })
 }
 
-func newModel(cfg config, out <-chan []candidate.Candidate, done <-chan error, hl *highlighter.Highlighter) model {
+func newModel(cfg config, out <-chan []candidate.Candidate, done <-chan candidate.ScanResult, hl *highlighter.Highlighter) model {
 	input := textinput.New()
 	input.Prompt = "query> "
 	input.Focus()
@@ -158,31 +230,113 @@ func newModel(cfg config, out <-chan []candidate.Candidate, done <-chan error, h
 		producerDone:   done,
 		highlighter:    hl,
 		previewEnabled: cfg.Preview,
+		hideTests:      cfg.ExcludeTests,
 		fileCache:      make(map[string][]string),
 		fileLangCache:  make(map[string]highlighter.LangID),
+		notebookCache:  make(map[string]*notebook.Notebook),
 	}
 }
 
-func (m *model) useCachedIndex(candidates []candidate.Candidate) {
+// useCachedIndex shows the cached candidates of a root until its scan
+// finishes.
+func (m *model) useCachedIndex(root string, candidates []candidate.Candidate) {
 	if len(candidates) == 0 {
 		return
 	}
 
-	m.rebuildFromScan = true
-	m.scanCandidates = make([]candidate.Candidate, 0, len(candidates))
-	m.candidates = candidates
-	m.filtered = make([]candidate.FilteredCandidate, len(candidates))
-	for i := range candidates {
-		m.filtered[i] = candidate.FilteredCandidate{Index: int32(i)}
+	if m.rebuildRoots == nil {
+		m.rebuildRoots = make(map[string]bool)
 	}
-	m.lastFilterCandidateN = len(candidates)
-	m.status = fmt.Sprintf("using cached index (%d symbols)", len(candidates))
+	m.rebuildRoots[root] = true
+	m.replaceRootCandidates(root, candidates)
+	m.status = fmt.Sprintf("using cached index (%d symbols)", len(m.candidates))
+}
+
+// addCandidates appends the candidates of a root that has none shown yet.
+func (m *model) addCandidates(root string, candidates []candidate.Candidate) {
+	for _, cand := range candidates {
+		cand.Root = root
+		m.appendCandidate(cand)
+	}
+	if len(m.queryRunes) > 0 {
+		m.scheduleFilter(0)
+	}
+}
+
+// appendCandidate numbers cand and adds it to the list, and to the results
+// while no query narrows them.
+func (m *model) appendCandidate(cand candidate.Candidate) {
+	m.nextID++
+	cand.ID = m.nextID
+	m.candidates = append(m.candidates, cand)
+	if len(m.queryRunes) == 0 && !m.isHidden(&cand) {
+		m.filtered = append(m.filtered, candidate.FilteredCandidate{Index: int32(len(m.candidates) - 1)})
+	}
+}
+
+// replaceRootCandidates swaps the candidates of a root for candidates and
+// filters the result again. When no other root has candidates, the slice is
+// used as is and renumbered in place.
+func (m *model) replaceRootCandidates(root string, candidates []candidate.Candidate) {
+	kept := 0
+	for _, cand := range m.candidates {
+		if cand.Root != root {
+			kept++
+		}
+	}
+	next := candidates[:len(candidates):len(candidates)]
+	if kept > 0 {
+		next = make([]candidate.Candidate, 0, kept+len(candidates))
+		for _, cand := range m.candidates {
+			if cand.Root != root {
+				next = append(next, cand)
+			}
+		}
+		next = append(next, candidates...)
+	}
+	for i := kept; i < len(next); i++ {
+		next[i].Root = root
+		m.nextID++
+		next[i].ID = m.nextID
+	}
+	m.candidates = next
+
+	if len(m.queryRunes) == 0 {
+		m.filtered = make([]candidate.FilteredCandidate, len(next))
+		for i := range next {
+			m.filtered[i] = candidate.FilteredCandidate{Index: int32(i)}
+		}
+		m.filtered = m.dropHidden(m.filtered)
+		m.lastFilterCandidateN = len(next)
+		m.lastFilterQueryRunes = nil
+		m.resetSelection()
+		return
+	}
+	m.filtered = nil
+	m.resetSelectionOnFilter = true
+	m.refilter()
 }
 
 func (m model) Init() tea.Cmd {
+	if m.cfg.Deps && m.diff == nil {
+		return tea.Batch(tickCmd(), resolveDependencies(m.scanCtx, m.roots))
+	}
 	return tickCmd()
 }
 
+// resolveDependencies resolves the dependencies of roots off the UI
+// goroutine, so that the project is searchable meanwhile.
+func resolveDependencies(ctx context.Context, roots []workspaceRoot) tea.Cmd {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	roots = slices.Clone(roots)
+	return func() tea.Msg {
+		found, err := dependencyRoots(ctx, roots)
+		return dependenciesMsg{roots: found, err: err}
+	}
+}
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -191,6 +345,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.input.Width = max(16, m.width-16)
 		m.scheduleFilter(0)
 
+	case dependenciesMsg:
+		if msg.err != nil {
+			m.errMsg = joinStatus(m.errMsg, "dependencies: "+msg.err.Error())
+		}
+		m.pendingDeps = append(m.pendingDeps, msg.roots...)
+		if m.scanDone || m.producerDone == nil {
+			m.scanDependencies()
+		}
+
 	case tickMsg:
 		m.drainProducer(m.producerDrainLimit())
 		m.drainProducerDone()
@@ -234,6 +397,50 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor = len(m.filtered) - 1
 			}
 			return returnAfterPreview()
+		case "ctrl+g":
+			m.hideGenerated = !m.hideGenerated
+			m.refilter()
+			if m.hideGenerated {
+				m.status = "hiding generated files"
+			} else {
+				m.status = "showing generated files"
+			}
+			return m, nil
+		case "ctrl+t":
+			m.hideTests = !m.hideTests
+			m.refilter()
+			if m.hideTests {
+				m.status = "hiding tests"
+			} else {
+				m.status = "showing tests"
+			}
+			return m, nil
+		case "ctrl+o":
+			if m.diff != nil {
+				return m, nil
+			}
+			cfg := m.producerCfg
+			cfg.NoIgnore = !cfg.NoIgnore
+			m.restartScan(cfg)
+			if cfg.NoIgnore {
+				m.status = joinStatus(m.status, "including ignored files")
+			} else {
+				m.status = joinStatus(m.status, "respecting ignore files")
+			}
+			return m, nil
+		case "ctrl+r":
+			if m.diff != nil {
+				return m, nil
+			}
+			cfg := m.producerCfg
+			cfg.Hidden = !cfg.Hidden
+			m.restartScan(cfg)
+			if cfg.Hidden {
+				m.status = joinStatus(m.status, "including hidden files")
+			} else {
+				m.status = joinStatus(m.status, "skipping hidden files")
+			}
+			return m, nil
 		case "tab":
 			m.previewEnabled = !m.previewEnabled
 			m.previewKey = ""
@@ -243,8 +450,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if !ok {
 				return m, nil
 			}
-			abs := filepath.Join(m.cfg.Root, cand.File)
-			if err := openLocation(abs, cand.Line, cand.Col, m.cfg.EditorCmd); err != nil {
+			roots := m.roots
+			if change, ok := m.diffChanges[cand.ID]; ok && change.New == nil {
+				roots = []workspaceRoot{{Path: m.cfg.Root, Rev: m.diff.FromRev}}
+			}
+			abs, err := materializeRevFile(context.Background(), roots, m.absPath(m.candidatePath(cand)))
+			if err != nil {
+				m.status = "open failed: " + err.Error()
+				return m, nil
+			}
+			line, col := m.fileLocation(cand)
+			if err := openLocation(abs, line, col, cand.Cell, m.cfg.EditorCmd); err != nil {
 				m.status = "open failed: " + err.Error()
 				return m, nil
 			}
@@ -254,7 +470,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if !ok {
 				return m, nil
 			}
-			loc := fmt.Sprintf("%s:%d:%d", cand.File, cand.Line, cand.Col)
+			line, col := m.fileLocation(cand)
+			loc := fmt.Sprintf("%s:%d:%d", m.candidatePath(cand), line, col)
 			if err := copyToClipboard(loc); err != nil {
 				m.status = "copy failed: " + err.Error()
 			} else {
@@ -281,7 +498,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) producerDrainLimit() int {
-	if !m.rebuildFromScan && len(m.queryRunes) == 0 {
+	if len(m.rebuildRoots) == 0 && len(m.queryRunes) == 0 {
 		return producerDrainItemsStartup
 	}
 	return producerDrainItemsDefault
@@ -309,19 +526,21 @@ func (m *model) ensureCursor() {
 		m.cursor = len(m.filtered) - 1
 	}
 
-	page := m.rowsPerPage()
+	_, listH, _, _ := m.layout()
 	if m.cursor < m.offset {
 		m.offset = m.cursor
 	}
-	if m.cursor >= m.offset+page {
-		m.offset = m.cursor - page + 1
+	if !m.rowsFit(m.offset, m.cursor+1, listH) {
+		m.offset = m.cursor
+		for m.offset > 0 && m.rowsFit(m.offset-1, m.cursor+1, listH) {
+			m.offset--
+		}
 	}
 	if m.offset < 0 {
 		m.offset = 0
 	}
-	maxOffset := max(0, len(m.filtered)-page)
-	if m.offset > maxOffset {
-		m.offset = maxOffset
+	for m.offset > 0 && m.rowsFit(m.offset-1, len(m.filtered), listH) {
+		m.offset--
 	}
 }
 
@@ -343,15 +562,16 @@ func (m *model) drainProducer(maxItems int) {
 			}
 			for _, cand := range batch {
 				processed++
-				if m.rebuildFromScan {
-					m.scanCandidates = append(m.scanCandidates, cand)
-				} else {
-					m.candidates = append(m.candidates, cand)
-					if len(m.queryRunes) == 0 {
-						m.filtered = append(m.filtered, candidate.FilteredCandidate{Index: int32(len(m.candidates) - 1)})
-					} else {
-						needFilter = true
+				rebuilding := m.rebuildRoots[cand.Root]
+				if rebuilding || len(m.roots) > 1 {
+					if m.scanned == nil {
+						m.scanned = make(map[string][]candidate.Candidate)
 					}
+					m.scanned[cand.Root] = append(m.scanned[cand.Root], cand)
+				}
+				if !rebuilding {
+					m.appendCandidate(cand)
+					needFilter = needFilter || len(m.queryRunes) > 0
 				}
 			}
 		default:
@@ -364,34 +584,245 @@ func (m *model) drainProducerDone() {
 	if m.scanDone || m.producerDone == nil {
 		return
 	}
-	select {
-	case err, ok := <-m.producerDone:
-		m.scanDone = true
-		m.producerDone = nil
-		if ok && err != nil {
-			m.errMsg = err.Error()
+	for {
+		select {
+		case result, ok := <-m.producerDone:
+			if !ok {
+				m.scanDone = true
+				m.producerDone = nil
+				if summary := m.scanSkipped.Summary(); summary != "" {
+					m.status = joinStatus(m.status, summary)
+				}
+				m.scanDependencies()
+				return
+			}
+			// A root reports its result after sending its last batch, so
+			// everything it found is queued by now.
+			m.drainProducer(0)
+			m.finishRoot(result)
+		default:
 			return
 		}
+	}
+}
 
-		if m.rebuildFromScan {
-			m.rebuildFromScan = false
-			m.candidates = m.scanCandidates
-			m.scanCandidates = nil
-			m.filtered = nil
-			m.lastFilterCandidateN = 0
-			m.lastFilterQueryRunes = nil
-			m.resetSelectionOnFilter = true
-			m.scheduleFilter(0)
-			m.status = fmt.Sprintf("index refreshed (%d symbols)", len(m.candidates))
+// finishRoot swaps in the rescanned candidates of a root shown from a cache
+// and saves the root's index.
+func (m *model) finishRoot(result candidate.ScanResult) {
+	root := result.Root
+	rebuilding := m.rebuildRoots[root]
+	delete(m.rebuildRoots, root)
+
+	fresh := m.scanned[root]
+	delete(m.scanned, root)
+
+	if result.Err != nil {
+		msg := result.Err.Error()
+		if root != "" {
+			msg = root + ": " + msg
+		}
+		m.errMsg = joinStatus(m.errMsg, msg)
+		return
+	}
+	m.scanSkipped = m.scanSkipped.Add(result.Skipped)
+
+	if rebuilding {
+		m.replaceRootCandidates(root, fresh)
+		m.status = fmt.Sprintf("index refreshed (%d symbols)", len(m.candidates))
+	}
+
+	cacheCfg := m.rootConfig(root)
+	cacheCandidates := fresh
+	if len(m.roots) <= 1 {
+		cacheCandidates = m.candidates
+	}
+	if m.scanIndexes == nil {
+		m.scanIndexes = make(map[string][]candidate.Candidate)
+	}
+	m.scanIndexes[scanIndexKey(cacheCfg)] = cacheCandidates
+	go func() {
+		_ = SaveIndexCache(cacheCfg, cacheCandidates)
+	}()
+}
+
+// restartScan cancels the running scan and starts one with cfg for every
+// root. Roots with a cached index for cfg show it right away; the others keep
+// their current candidates until the new scan replaces them. Dependencies and
+// revisions do not change, so once indexed, this session or in the cache,
+// they are not scanned again.
+func (m *model) restartScan(cfg candidate.ProducerConfig) {
+	if m.cancelScan != nil {
+		m.cancelScan()
+	}
+	parent := m.scanCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	m.cancelScan = cancel
+	m.producerCfg = cfg
+	m.scanDone = false
+	m.errMsg = ""
+	m.status = ""
+	m.scanned = nil
+	m.scanSkipped = candidate.SkipStats{}
+	m.rebuildRoots = make(map[string]bool)
+	m.producerOut, m.producerDone = candidate.StartProducers(ctx, m.prepareScan(m.rootConfigs(), len(m.candidates) > 0))
+}
+
+// scanDependencies adds the resolved dependency roots once no scan is
+// running and indexes those without an index.
+func (m *model) scanDependencies() {
+	if len(m.pendingDeps) == 0 {
+		return
+	}
+	cfgs := make([]candidate.ProducerConfig, 0, len(m.pendingDeps))
+	m.roots = append(m.roots, m.pendingDeps...)
+	for _, root := range m.pendingDeps {
+		cfgs = append(cfgs, m.rootConfig(root.Name))
+	}
+	m.pendingDeps = nil
+	if m.sharedRoots != nil {
+		roots := slices.Clone(m.roots)
+		m.sharedRoots.Store(&roots)
+	}
+
+	scan := m.prepareScan(cfgs, false)
+	if len(scan) == 0 {
+		return
+	}
+	parent := m.scanCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	m.cancelScan = cancel
+	m.scanDone = false
+	m.scanSkipped = candidate.SkipStats{}
+	noun := "dependencies"
+	if len(scan) == 1 {
+		noun = "dependency"
+	}
+	m.status = joinStatus(m.status, fmt.Sprintf("indexing %d %s", len(scan), noun))
+	m.producerOut, m.producerDone = candidate.StartProducers(ctx, scan)
+}
+
+// prepareScan shows the indexes already at hand for cfgs and returns the
+// configs left to scan. When shown, roots without one keep their current
+// candidates until their scan replaces them.
+func (m *model) prepareScan(cfgs []candidate.ProducerConfig, shown bool) []candidate.ProducerConfig {
+	if m.scanIndexes == nil {
+		m.scanIndexes = make(map[string][]candidate.Candidate)
+	}
+	var scan []candidate.ProducerConfig
+	for _, rootCfg := range cfgs {
+		key := scanIndexKey(rootCfg)
+		if rootCfg.Dependency != "" || rootCfg.Rev != "" {
+			if _, ok := m.scanIndexes[key]; ok {
+				continue
+			}
+			if cached, ok := m.loadIndexCache(rootCfg); ok {
+				m.addCandidates(rootCfg.RootName, cached)
+				m.scanIndexes[key] = cached
+				continue
+			}
 		}
 
-		cacheCfg := m.producerCfg
-		cacheCandidates := m.candidates
-		go func() {
-			_ = SaveIndexCache(cacheCfg, cacheCandidates)
-		}()
-	default:
+		scan = append(scan, rootCfg)
+		cached, ok := m.scanIndexes[key]
+		if !ok {
+			cached, ok = m.loadIndexCache(rootCfg)
+		}
+		if ok && len(cached) > 0 {
+			m.useCachedIndex(rootCfg.RootName, cached)
+		} else if shown {
+			m.rebuildRoots[rootCfg.RootName] = true
+		}
+	}
+	return scan
+}
+
+func (m *model) loadIndexCache(cfg candidate.ProducerConfig) ([]candidate.Candidate, bool) {
+	cached, ok, err := LoadIndexCache(cfg)
+	if err != nil {
+		m.status = "index cache unavailable: " + err.Error()
+	}
+	return cached, ok
+}
+
+// rootConfigs returns the producer config of every root.
+func (m *model) rootConfigs() []candidate.ProducerConfig {
+	if len(m.roots) == 0 {
+		return []candidate.ProducerConfig{m.producerCfg}
+	}
+	cfgs := make([]candidate.ProducerConfig, 0, len(m.roots))
+	for _, root := range m.roots {
+		cfgs = append(cfgs, m.rootConfig(root.Name))
+	}
+	return cfgs
+}
+
+// rootConfig returns the producer config of a root. Dependency sources are
+// often ignored by the project, so ignore files are not applied to them.
+func (m *model) rootConfig(name string) candidate.ProducerConfig {
+	cfg := m.producerCfg
+	for _, root := range m.roots {
+		if root.Name == name {
+			cfg.Root = root.Path
+			cfg.RootName = root.Name
+			cfg.Dependency = root.Dependency
+			cfg.Rev = root.Rev
+		}
+	}
+	if cfg.Dependency != "" {
+		cfg.NoIgnore = true
+		cfg.Hidden = false
+	}
+	return cfg
+}
+
+// candidatePath returns where a candidate's file is read from: its path
+// within cfg.Root, or an absolute path for candidates of a named root.
+func (m *model) candidatePath(cand candidate.Candidate) string {
+	if cand.Root == "" {
+		return cand.File
+	}
+	for _, root := range m.roots {
+		if root.Name == cand.Root {
+			return filepath.Join(root.Path, cand.File)
+		}
 	}
+	return cand.File
+}
+
+// scanIndexKey identifies a root and the scan options that change which
+// candidates are indexed. RootName only labels candidates, so it is left out.
+// The key names each option so that a field added to ProducerConfig does not
+// silently change it.
+func scanIndexKey(cfg candidate.ProducerConfig) string {
+	fields := []string{
+		"root=" + filepath.Clean(cfg.Root),
+		"dependency=" + cfg.Dependency,
+		"rev=" + cfg.Rev,
+		"pattern=" + cfg.Pattern,
+		"no-ignore=" + strconv.FormatBool(cfg.NoIgnore),
+		"hidden=" + strconv.FormatBool(cfg.Hidden),
+		"exclude-generated=" + strconv.FormatBool(cfg.ExcludeGenerated),
+		"members=" + strconv.FormatBool(cfg.Members),
+		"max-filesize=" + cfg.MaxFileSize,
+		"max-file-symbols=" + strconv.Itoa(cfg.MaxFileCandidates),
+	}
+	for _, glob := range cfg.Excludes {
+		fields = append(fields, "exclude="+glob)
+	}
+	return strings.Join(fields, "\x00")
+}
+
+func joinStatus(status string, more string) string {
+	if status == "" {
+		return more
+	}
+	return status + "; " + more
 }
 
 func (m *model) scheduleFilter(delay time.Duration) {
@@ -425,6 +856,7 @@ func (m *model) applyFilter() {
 	} else {
 		m.filtered = candidate.FilterCandidatesWithQueryRunes(m.candidates, m.queryRaw, m.queryRunes)
 	}
+	m.filtered = m.dropHidden(m.filtered)
 	m.lastFilterQueryRunes = copyRunesReuse(m.lastFilterQueryRunes, m.queryRunes)
 	m.lastFilterCandidateN = candidateN
 
@@ -448,6 +880,34 @@ func (m *model) applyFilter() {
 	m.resetSelection()
 }
 
+// refilter reruns the filter over every candidate, for when the set of
+// hidden candidates changes.
+func (m *model) refilter() {
+	m.lastFilterCandidateN = 0
+	m.lastFilterQueryRunes = nil
+	m.scheduleFilter(0)
+}
+
+// dropHidden removes the candidates hidden by the generated and test
+// toggles, reusing filtered.
+func (m *model) dropHidden(filtered []candidate.FilteredCandidate) []candidate.FilteredCandidate {
+	if !m.hideGenerated && !m.hideTests {
+		return filtered
+	}
+	out := filtered[:0]
+	for _, item := range filtered {
+		if m.isHidden(&m.candidates[int(item.Index)]) {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func (m *model) isHidden(cand *candidate.Candidate) bool {
+	return (m.hideGenerated && cand.Generated) || (m.hideTests && cand.Test)
+}
+
 func (m *model) queueVisibleHighlights() {
 	if m.highlighter == nil {
 		return
@@ -465,8 +925,7 @@ func (m *model) queueVisibleHighlights() {
 		if !ok {
 			continue
 		}
-		text := truncateText(cand.Text, listW)
-		m.highlighter.Queue(m.highlightRequest(cand.LangID, cand.File, cand.Line, text))
+		m.highlighter.Queue(m.candidateHighlightRequest(cand, listW))
 	}
 
 	if !m.previewEnabled || len(m.preview.Lines) == 0 || previewH <= 1 {
@@ -479,7 +938,7 @@ func (m *model) queueVisibleHighlights() {
 	for i := range visible {
 		lineNo := m.preview.StartLine + i
 		text := truncateText(lines[i], maxCode)
-		m.highlighter.Queue(m.highlightRequest(m.preview.Lang, m.preview.File, lineNo, text))
+		m.highlighter.Queue(m.highlightRequest(m.preview.lineLang(lineNo), m.preview.Path, m.preview.Cell, lineNo, text))
 	}
 }
 
@@ -491,60 +950,159 @@ func (m *model) updatePreview() {
 		return
 	}
 
-	key := fmt.Sprintf("%s:%d:%d", cand.File, cand.Line, m.height)
+	path := m.candidatePath(cand)
+	key := fmt.Sprintf("%s:%s:%d:%d:%d", cand.Root, path, cand.Cell, cand.Line, m.height)
 	if key == m.previewKey {
 		return
 	}
 	m.previewKey = key
 
-	fileLines, err := m.loadFile(cand.File)
+	if m.diff != nil {
+		m.updateDiffPreview(cand)
+		return
+	}
+
+	if cand.Cell > 0 {
+		m.updateCellPreview(cand)
+		return
+	}
+
+	fileLines, err := m.loadFile(path)
 	if err != nil {
-		m.preview = previewState{File: cand.File, Err: err.Error()}
+		m.preview = previewState{File: cand.File, Root: cand.Root, Err: err.Error()}
 		return
 	}
 	if len(fileLines) == 0 {
-		m.preview = previewState{File: cand.File, Err: "empty file"}
+		m.preview = previewState{File: cand.File, Root: cand.Root, Err: "empty file"}
 		return
 	}
 
-	lang := m.fileLangCache[cand.File]
+	lang := m.fileLangCache[path]
 	if lang == "" {
-		lang = highlighter.DetectLanguageWithShebang(cand.File, fileLines[0])
-		m.fileLangCache[cand.File] = lang
+		lang = highlighter.DetectLanguageContent(cand.File, fileLines)
+		if !highlighter.IsComponentLanguage(lang) && cand.LangID != highlighter.LangPlain && cand.LangID != "" {
+			lang = cand.LangID
+		}
+		m.fileLangCache[path] = lang
 	}
 
-	_, _, _, previewH := m.layout()
-	visible := max(1, previewH-1)
-	before := visible / 4
-	start := max(1, cand.Line-before)
-	end := min(len(fileLines), start+visible-1)
-	if end-start+1 < visible {
-		start = max(1, end-visible+1)
+	start, end := m.previewWindow(cand.Line, len(fileLines))
+	m.preview = previewState{
+		File:         cand.File,
+		Root:         cand.Root,
+		Path:         path,
+		Lang:         lang,
+		StartLine:    start,
+		Lines:        fileLines[start-1 : end],
+		SelectedLine: cand.Line,
+		Regions:      highlighter.EmbeddedRegions(lang, fileLines),
 	}
+}
 
-	lines := fileLines[start-1 : end]
+// updateCellPreview shows the source of the notebook cell a candidate was
+// found in, rather than the notebook JSON.
+func (m *model) updateCellPreview(cand candidate.Candidate) {
+	path := m.candidatePath(cand)
+	cell, err := m.loadNotebookCell(path, cand.Cell)
+	if err != nil {
+		m.preview = previewState{File: cand.File, Root: cand.Root, Err: err.Error()}
+		return
+	}
+	if len(cell.Source) == 0 {
+		m.preview = previewState{File: cand.File, Root: cand.Root, Err: "empty cell"}
+		return
+	}
+
+	start, end := m.previewWindow(cand.Line, len(cell.Source))
 	m.preview = previewState{
 		File:         cand.File,
-		Lang:         lang,
+		Root:         cand.Root,
+		Path:         path,
+		Lang:         cand.LangID,
 		StartLine:    start,
-		Lines:        lines,
+		Lines:        cell.Source[start-1 : end],
 		SelectedLine: cand.Line,
+		Cell:         cand.Cell,
 	}
 }
 
-func (m *model) loadFile(rel string) ([]string, error) {
-	if lines, ok := m.fileCache[rel]; ok {
+// previewWindow picks the lines of a file of n lines to preview around line.
+func (m *model) previewWindow(line int, n int) (int, int) {
+	_, _, _, previewH := m.layout()
+	return previewWindowLines(line, n, max(1, previewH-1))
+}
+
+// previewWindowLines picks visible lines of a file of n lines around line.
+func previewWindowLines(line int, n int, visible int) (int, int) {
+	before := visible / 4
+	start := max(1, line-before)
+	end := min(n, start+visible-1)
+	if end-start+1 < visible {
+		start = max(1, end-visible+1)
+	}
+	return start, end
+}
+
+// loadFile reads a file by its path within cfg.Root or its absolute path.
+func (m *model) loadFile(path string) ([]string, error) {
+	if lines, ok := m.fileCache[path]; ok {
 		return lines, nil
 	}
-	abs := filepath.Join(m.cfg.Root, rel)
-	lines, err := readfile.ReadLinesNormalized(abs)
+	data, err := readRootFile(context.Background(), m.roots, m.absPath(path))
 	if err != nil {
 		return nil, err
 	}
-	m.fileCache[rel] = lines
+	lines := readfile.SplitLinesNormalized(data)
+	m.fileCache[path] = lines
 	return lines, nil
 }
 
+func (m *model) absPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(m.cfg.Root, path)
+}
+
+// fileLocation returns where a candidate is in its file. Notebook candidates
+// are mapped from their cell to the line of the notebook JSON holding it.
+func (m *model) fileLocation(cand candidate.Candidate) (int, int) {
+	if cand.Cell == 0 {
+		return cand.Line, cand.Col
+	}
+	path := m.candidatePath(cand)
+	cell, err := m.loadNotebookCell(path, cand.Cell)
+	if err != nil {
+		return 1, 1
+	}
+	line := cell.RawLine(cand.Line)
+	fileLines, err := m.loadFile(path)
+	if err != nil || line > len(fileLines) || cand.Line > len(cell.Source) || cand.Line > len(cell.RawLines) || cell.RawLines[cand.Line-1] == 0 {
+		return line, 1
+	}
+	return line, notebook.RawColumn(fileLines[line-1], cell.Source[cand.Line-1], cand.Col)
+}
+
+func (m *model) loadNotebookCell(path string, index int) (notebook.Cell, error) {
+	nb, ok := m.notebookCache[path]
+	if !ok {
+		data, err := readRootFile(context.Background(), m.roots, m.absPath(path))
+		if err != nil {
+			return notebook.Cell{}, err
+		}
+		nb, err = notebook.Parse(data)
+		if err != nil {
+			return notebook.Cell{}, err
+		}
+		m.notebookCache[path] = nb
+	}
+	cell, ok := nb.Cell(index)
+	if !ok {
+		return notebook.Cell{}, fmt.Errorf("notebook has no cell %d", index)
+	}
+	return cell, nil
+}
+
 func fatalf(format string, args ...any) {
 	if _, err := fmt.Fprintf(os.Stderr, format+"\n", args...); err != nil {
 		os.Exit(1)
@@ -563,15 +1121,23 @@ func main() {
 
 	var cfg config
 	flag.StringVar(&cfg.Root, "root", ".", "search root")
+	flag.StringVar(&cfg.Workspace, "workspace", "", "workspace file listing the roots to search by name and path")
 	flag.StringVar(&cfg.Pattern, "pattern", candidate.DefaultRGPattern, "ripgrep regex pattern")
 	flag.BoolVar(&cfg.Preview, "preview", true, "show preview pane")
 	flag.IntVar(&cfg.CacheSize, "cache-size", 20000, "highlight cache entries")
 	flag.IntVar(&cfg.Workers, "workers", max(1, runtime.GOMAXPROCS(0)-1), "highlight workers")
 	flag.IntVar(&cfg.VisibleBuffer, "visible-buffer", 30, "extra rows to pre-highlight")
 	flag.IntVar(&cfg.ContextRadius, "context-radius", 40, "line radius for file context highlighting")
-	flag.StringVar(&cfg.EditorCmd, "editor-cmd", "", "override open command, supports {file} {line} {col} {target}")
+	flag.StringVar(&cfg.EditorCmd, "editor-cmd", "", "override open command, supports {file} {line} {col} {target} {cell}")
 	flag.BoolVar(&cfg.NoIgnore, "no-ignore", false, "disable rg ignore files (.gitignore/.ignore/.rgignore)")
-	flag.BoolVar(&cfg.ExcludeTests, "exclude-tests", false, "exclude common test directories and test filename patterns")
+	flag.BoolVar(&cfg.Hidden, "hidden", false, "search hidden files and directories (except .git)")
+	flag.StringVar(&cfg.Rev, "rev", "", "index a git revision, such as main or a commit, instead of the working tree")
+	flag.BoolVar(&cfg.Deps, "deps", false, "also index dependency sources: Go modules, node_modules packages and Cargo registry crates")
+	flag.BoolVar(&cfg.ExcludeTests, "exclude-tests", false, "hide tests: test files and directories, and in-file tests such as Rust #[cfg(test)] modules")
+	flag.BoolVar(&cfg.ExcludeGenerated, "exclude-generated", false, "exclude generated files (Code generated ... DO NOT EDIT, @generated, linguist-generated)")
+	flag.BoolVar(&cfg.Members, "members", false, "also index exported struct fields and interface methods")
+	flag.StringVar(&cfg.MaxFileSize, "max-filesize", candidate.DefaultMaxFileSize, "skip files larger than this size (rg --max-filesize syntax, such as 500K or 2M); raise it to index files over the 1M default")
+	flag.IntVar(&cfg.MaxFileSymbols, "max-file-symbols", candidate.DefaultMaxFileCandidates, "index at most this many symbols per file")
 	flag.StringVar(&cfg.Theme, "theme", "nord", "color theme (for example: nord, dracula, monokai, github, solarized-dark)")
 	highlightContext := flag.String("highlight-context", string(highlighter.HighlightContextFile), "highlight mode: synthetic or file")
 	debounceMs := flag.Int("debounce-ms", 100, "query debounce in milliseconds")
@@ -591,15 +1157,11 @@ func main() {
 	}
 	cfg.HighlightMode = mode
 
-	if flag.NArg() > 0 {
-		cfg.Root = flag.Arg(0)
-	}
-
-	absRoot, err := filepath.Abs(cfg.Root)
+	roots, err := resolveRoots(cfg.Workspace, flag.Args(), cfg.Root)
 	if err != nil {
-		fatalf("resolve root: %v", err)
+		fatalf("%v", err)
 	}
-	cfg.Root = absRoot
+	cfg.Root = roots[0].Path
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -610,30 +1172,48 @@ func main() {
 	}
 
 	producerCfg := candidate.ProducerConfig{
-		Root:         cfg.Root,
-		Pattern:      producerPattern,
-		NoIgnore:     cfg.NoIgnore,
-		ExcludeTests: cfg.ExcludeTests,
+		Root:              cfg.Root,
+		Pattern:           producerPattern,
+		NoIgnore:          cfg.NoIgnore,
+		Hidden:            cfg.Hidden,
+		ExcludeGenerated:  cfg.ExcludeGenerated,
+		Members:           cfg.Members,
+		MaxFileSize:       cfg.MaxFileSize,
+		MaxFileCandidates: cfg.MaxFileSymbols,
+	}
+
+	if cfg.Rev != "" {
+		if err := resolveRevisions(ctx, roots, cfg.Rev); err != nil {
+			fatalf("invalid --rev: %v", err)
+		}
 	}
 
-	cachedCandidates, cacheLoaded, cacheErr := LoadIndexCache(producerCfg)
-	out, done := candidate.StartProducer(ctx, producerCfg)
+	m := newModel(cfg, nil, nil, nil)
+	m.roots = roots
+	m.sharedRoots = new(atomic.Pointer[[]workspaceRoot])
+	m.sharedRoots.Store(&roots)
+	m.scanCtx = ctx
+	m.restartScan(producerCfg)
 
-	highlighter := highlighter.NewHighlighter(highlighter.HighlighterConfig{
+	highlighterCfg := highlighter.HighlighterConfig{
 		CacheSize:     cfg.CacheSize,
 		Workers:       cfg.Workers,
 		Root:          cfg.Root,
 		DefaultMode:   cfg.HighlightMode,
 		ContextRadius: cfg.ContextRadius,
-	})
-	m := newModel(cfg, out, done, highlighter)
-	m.producerCfg = producerCfg
-	if cacheErr != nil {
-		m.status = "index cache unavailable: " + cacheErr.Error()
 	}
-	if cacheLoaded {
-		m.useCachedIndex(cachedCandidates)
+	if cfg.Rev != "" {
+		sharedRoots := m.sharedRoots
+		highlighterCfg.ReadLines = func(path string) ([]string, error) {
+			data, err := readRootFile(ctx, *sharedRoots.Load(), path)
+			if err != nil {
+				return nil, err
+			}
+			return readfile.SplitLinesNormalized(data), nil
+		}
 	}
+	highlighter := highlighter.NewHighlighter(highlighterCfg)
+	m.highlighter = highlighter
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
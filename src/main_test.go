@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"snav/internal/candidate"
 
@@ -11,7 +15,7 @@ import (
 
 func TestBuildEditorCommandSupportsQuotedPathAndArgs(t *testing.T) {
 	template := `"/Applications/Visual Studio Code.app/Contents/Resources/app/bin/code" -g "{target}" --reuse-window`
-	name, args, err := buildEditorCommand(template, "/tmp/my file.go", 12, 4, "/tmp/my file.go:12:4")
+	name, args, err := buildEditorCommand(template, "/tmp/my file.go", 12, 4, 0, "/tmp/my file.go:12:4")
 	if err != nil {
 		t.Fatalf("buildEditorCommand returned error: %v", err)
 	}
@@ -28,7 +32,7 @@ func TestBuildEditorCommandSupportsQuotedPathAndArgs(t *testing.T) {
 
 func TestBuildEditorCommandPreservesEmptyArgument(t *testing.T) {
 	template := `cmd /C start "" "{file}"`
-	name, args, err := buildEditorCommand(template, `C:\Program Files\Editor\file.go`, 8, 1, `C:\Program Files\Editor\file.go:8:1`)
+	name, args, err := buildEditorCommand(template, `C:\Program Files\Editor\file.go`, 8, 1, 0, `C:\Program Files\Editor\file.go:8:1`)
 	if err != nil {
 		t.Fatalf("buildEditorCommand returned error: %v", err)
 	}
@@ -44,13 +48,13 @@ func TestBuildEditorCommandPreservesEmptyArgument(t *testing.T) {
 }
 
 func TestBuildEditorCommandRejectsUnclosedQuote(t *testing.T) {
-	if _, _, err := buildEditorCommand(`code -g "{target}`, "file.go", 1, 1, "file.go:1:1"); err == nil {
+	if _, _, err := buildEditorCommand(`code -g "{target}`, "file.go", 1, 1, 0, "file.go:1:1"); err == nil {
 		t.Fatalf("expected error for unclosed quote")
 	}
 }
 
 func TestBuildEditorCommandKeepsBackslashes(t *testing.T) {
-	name, args, err := buildEditorCommand(`C:\tools\code.exe -g {target}`, `C:\repo\file.go`, 3, 2, `C:\repo\file.go:3:2`)
+	name, args, err := buildEditorCommand(`C:\tools\code.exe -g {target}`, `C:\repo\file.go`, 3, 2, 0, `C:\repo\file.go:3:2`)
 	if err != nil {
 		t.Fatalf("buildEditorCommand returned error: %v", err)
 	}
@@ -64,6 +68,21 @@ func TestBuildEditorCommandKeepsBackslashes(t *testing.T) {
 	}
 }
 
+func TestBuildEditorCommandSubstitutesNotebookCell(t *testing.T) {
+	name, args, err := buildEditorCommand(`nbopen {file} --cell {cell} --line {line}`, "analysis.ipynb", 42, 5, 3, "analysis.ipynb:42:5")
+	if err != nil {
+		t.Fatalf("buildEditorCommand returned error: %v", err)
+	}
+	if name != "nbopen" {
+		t.Fatalf("name = %q", name)
+	}
+
+	wantArgs := []string{"analysis.ipynb", "--cell", "3", "--line", "42"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args = %#v, want %#v", args, wantArgs)
+	}
+}
+
 func TestShouldUseIncrementalFilter(t *testing.T) {
 	if !shouldUseIncrementalFilter([]rune("handler"), []rune("hand"), 100, 100) {
 		t.Fatalf("expected incremental filter to be used for growing prefix query")
@@ -77,6 +96,21 @@ func TestShouldUseIncrementalFilter(t *testing.T) {
 	if shouldUseIncrementalFilter([]rune("parser"), []rune("hand"), 100, 100) {
 		t.Fatalf("did not expect incremental filter when query is not prefixed")
 	}
+	if shouldUseIncrementalFilter([]rune("doc:"), []rune("doc"), 100, 100) {
+		t.Fatalf("did not expect incremental filter when a doc scope is added")
+	}
+	if !shouldUseIncrementalFilter([]rune("doc:pars"), []rune("doc:"), 100, 100) {
+		t.Fatalf("expected incremental filter within a doc scope")
+	}
+	if shouldUseIncrementalFilter([]rune("root:api"), []rune("root"), 100, 100) {
+		t.Fatalf("did not expect incremental filter when a root scope is added")
+	}
+	if !shouldUseIncrementalFilter([]rune("root:api user"), []rune("root:api"), 100, 100) {
+		t.Fatalf("expected incremental filter within a root scope")
+	}
+	if shouldUseIncrementalFilter([]rune("root:api doc:"), []rune("root:api doc"), 100, 100) {
+		t.Fatalf("did not expect incremental filter when a doc scope is added after a root scope")
+	}
 }
 
 func TestCopyRunesReuse(t *testing.T) {
@@ -137,3 +171,275 @@ func TestModelUpdateArrowNavigationStillWorks(t *testing.T) {
 		t.Fatalf("cursor after up = %d, want 0", m2.cursor)
 	}
 }
+
+func TestEnsureCursorAccountsForDocLines(t *testing.T) {
+	m := newModel(config{}, nil, nil, nil)
+	m.height = 3 + 8
+	for i := 0; i < 6; i++ {
+		cand := candidate.Candidate{ID: i + 1, File: "a.go", Line: i + 1, Key: "f"}
+		if i%2 == 0 {
+			cand.Doc = "Documented."
+		}
+		m.candidates = append(m.candidates, cand)
+		m.filtered = append(m.filtered, candidate.FilteredCandidate{Index: int32(i)})
+	}
+
+	m.cursor = 3
+	m.ensureCursor()
+	if m.offset != 1 {
+		t.Fatalf("offset = %d, want 1 so rows 1-3 fill 8 lines", m.offset)
+	}
+
+	m.cursor = 5
+	m.ensureCursor()
+	if m.offset != 3 {
+		t.Fatalf("offset = %d, want 3", m.offset)
+	}
+
+	m.cursor = 0
+	m.ensureCursor()
+	if m.offset != 0 {
+		t.Fatalf("offset = %d, want 0", m.offset)
+	}
+}
+
+func TestToggleHidesGeneratedCandidates(t *testing.T) {
+	m := newModel(config{}, nil, nil, nil)
+	m.candidates = []candidate.Candidate{
+		{ID: 1, File: "user.pb.go", Key: "User", Generated: true},
+		{ID: 2, File: "user.go", Key: "User"},
+	}
+	m.applyFilter()
+	if len(m.filtered) != 2 {
+		t.Fatalf("visible = %d, want 2", len(m.filtered))
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m = updated.(model)
+	m.applyFilter()
+	if len(m.filtered) != 1 || m.candidates[int(m.filtered[0].Index)].ID != 2 {
+		t.Fatalf("filtered after hiding = %#v, want only the hand-written candidate", m.filtered)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m = updated.(model)
+	m.applyFilter()
+	if len(m.filtered) != 2 {
+		t.Fatalf("visible after showing = %d, want 2", len(m.filtered))
+	}
+}
+
+func TestExcludeTestsHidesTestCandidatesWithoutRescan(t *testing.T) {
+	m := newModel(config{ExcludeTests: true}, nil, nil, nil)
+	m.useCachedIndex("", []candidate.Candidate{
+		{ID: 1, File: "store.rs", Key: "adds", Test: true},
+		{ID: 2, File: "store.rs", Key: "add"},
+	})
+	if len(m.filtered) != 1 || m.candidates[int(m.filtered[0].Index)].ID != 2 {
+		t.Fatalf("filtered = %#v, want only the non-test candidate", m.filtered)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = updated.(model)
+	m.applyFilter()
+	if len(m.filtered) != 2 {
+		t.Fatalf("visible after showing tests = %d, want 2", len(m.filtered))
+	}
+}
+
+func TestToggleNoIgnoreRestartsScanWithSessionIndex(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc Visible() {}\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	m := newModel(config{Root: root}, nil, nil, nil)
+	m.producerCfg = candidate.ProducerConfig{Root: root, Pattern: candidate.DefaultRGPattern}
+	m.scanDone = true
+	m.useCachedIndex("", []candidate.Candidate{{ID: 1, File: "main.go", Key: "Visible"}})
+
+	noIgnore := m.producerCfg
+	noIgnore.NoIgnore = true
+	m.scanIndexes = map[string][]candidate.Candidate{
+		scanIndexKey(noIgnore): {
+			{ID: 1, File: "main.go", Key: "Visible"},
+			{ID: 2, File: "vendor/lib.go", Key: "Ignored"},
+		},
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = updated.(model)
+	t.Cleanup(m.cancelScan)
+	if !m.producerCfg.NoIgnore || m.scanDone || !m.rebuildRoots[""] {
+		t.Fatalf("after ctrl+o: cfg = %#v, scanDone = %v, rebuild = %v", m.producerCfg, m.scanDone, m.rebuildRoots)
+	}
+	if len(m.candidates) != 2 {
+		t.Fatalf("candidates = %d, want the 2 from the session index", len(m.candidates))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !m.scanDone && time.Now().Before(deadline) {
+		m.drainProducer(0)
+		m.drainProducerDone()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !m.scanDone || m.errMsg != "" {
+		t.Fatalf("scan did not finish cleanly: done = %v, err = %q", m.scanDone, m.errMsg)
+	}
+	if len(m.candidates) != 1 || m.candidates[0].Key != "Visible" {
+		t.Fatalf("candidates after rescan = %#v, want only Visible", m.candidates)
+	}
+	if got := m.scanIndexes[scanIndexKey(noIgnore)]; len(got) != 1 {
+		t.Fatalf("session index = %#v, want the rescanned candidates", got)
+	}
+}
+
+func TestFinishedRootReplacesOnlyItsCandidates(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+	m := newModel(config{}, nil, nil, nil)
+	m.roots = []workspaceRoot{{Name: "api", Path: t.TempDir()}, {Name: "web", Path: t.TempDir()}}
+	m.useCachedIndex("api", []candidate.Candidate{{File: "old.go", Key: "Old"}})
+	m.useCachedIndex("web", []candidate.Candidate{{File: "app.ts", Key: "App"}})
+
+	out := make(chan []candidate.Candidate, 1)
+	done := make(chan candidate.ScanResult, 2)
+	out <- []candidate.Candidate{{File: "new.go", Key: "New", Root: "api"}}
+	done <- candidate.ScanResult{Root: "api"}
+	m.producerOut, m.producerDone = out, done
+
+	m.drainProducerDone()
+	keys := map[string]string{}
+	ids := map[int]bool{}
+	for _, cand := range m.candidates {
+		keys[cand.Key] = cand.Root
+		ids[cand.ID] = true
+	}
+	want := map[string]string{"New": "api", "App": "web"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("candidates = %v, want %v", keys, want)
+	}
+	if len(ids) != len(m.candidates) {
+		t.Fatalf("candidate IDs are not unique: %#v", m.candidates)
+	}
+	if m.rebuildRoots["api"] || !m.rebuildRoots["web"] || m.scanDone {
+		t.Fatalf("rebuildRoots = %v, scanDone = %v; want only web pending", m.rebuildRoots, m.scanDone)
+	}
+	if got := m.scanIndexes[scanIndexKey(m.rootConfig("api"))]; len(got) != 1 || got[0].Key != "New" {
+		t.Fatalf("api session index = %#v, want only New", got)
+	}
+}
+
+func TestDependenciesWaitForTheRunningScan(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+	root := t.TempDir()
+	dep := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc Visible() {}\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dep, "flags.go"), []byte("package pflag\n\nfunc Parse() {}\n"), 0o644); err != nil {
+		t.Fatalf("write dependency: %v", err)
+	}
+
+	m := newModel(config{Root: root}, nil, nil, nil)
+	m.roots = []workspaceRoot{{Path: root}}
+	m.restartScan(candidate.ProducerConfig{Root: root, Pattern: candidate.DefaultRGPattern})
+	t.Cleanup(func() { m.cancelScan() })
+
+	updated, _ := m.Update(dependenciesMsg{roots: []workspaceRoot{{Name: "pflag", Path: dep, Dependency: "github.com/spf13/pflag@v1.0.5"}}})
+	m = updated.(model)
+	if len(m.roots) != 1 || len(m.pendingDeps) != 1 {
+		t.Fatalf("roots = %d, pending = %d; want the dependency held until the scan finishes", len(m.roots), len(m.pendingDeps))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for (!m.scanDone || len(m.roots) < 2) && time.Now().Before(deadline) {
+		m.drainProducer(0)
+		m.drainProducerDone()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !m.scanDone || m.errMsg != "" {
+		t.Fatalf("scan did not finish cleanly: done = %v, err = %q", m.scanDone, m.errMsg)
+	}
+	roots := map[string]string{}
+	for _, cand := range m.candidates {
+		roots[cand.Key] = cand.Root
+	}
+	if len(roots) != 2 || roots["Visible"] != "" || roots["Parse"] != "pflag" {
+		t.Fatalf("candidates = %#v, want Visible and the dependency's Parse", m.candidates)
+	}
+}
+
+func TestRestartScanKeepsIndexedDependencies(t *testing.T) {
+	withIndexCacheDir(t, t.TempDir())
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc Visible() {}\n"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	m := newModel(config{Root: root}, nil, nil, nil)
+	m.roots = []workspaceRoot{
+		{Path: root},
+		{Name: "react", Path: t.TempDir(), Dependency: "react@18.2.0"},
+	}
+	m.producerCfg = candidate.ProducerConfig{Root: root, Pattern: candidate.DefaultRGPattern}
+	m.scanIndexes = map[string][]candidate.Candidate{}
+	depCfg := m.rootConfig("react")
+	if !depCfg.NoIgnore {
+		t.Fatalf("dependency config should not apply ignore files: %#v", depCfg)
+	}
+	m.scanIndexes[scanIndexKey(depCfg)] = []candidate.Candidate{{File: "index.js", Key: "useState", Dependency: true}}
+	m.addCandidates("react", m.scanIndexes[scanIndexKey(depCfg)])
+	depID := m.candidates[0].ID
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = updated.(model)
+	t.Cleanup(m.cancelScan)
+	if m.rebuildRoots["react"] || !m.rebuildRoots[""] {
+		t.Fatalf("rebuildRoots = %v, want only the project root rescanned", m.rebuildRoots)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !m.scanDone && time.Now().Before(deadline) {
+		m.drainProducer(0)
+		m.drainProducerDone()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !m.scanDone || m.errMsg != "" {
+		t.Fatalf("scan did not finish cleanly: done = %v, err = %q", m.scanDone, m.errMsg)
+	}
+	keys := map[string]int{}
+	for _, cand := range m.candidates {
+		keys[cand.Key] = cand.ID
+	}
+	if len(keys) != 2 || keys["useState"] != depID || keys["Visible"] == 0 {
+		t.Fatalf("candidates = %#v, want Visible and the untouched useState", m.candidates)
+	}
+}
+
+func TestIndexAndPreviewAgreeOnModelineLanguage(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "widget.txt"), []byte("// vim: set ft=cpp:\nclass Widget {};\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	out, done := candidate.StartProducer(context.Background(), candidate.ProducerConfig{Root: root, Pattern: `^class `})
+	var found []candidate.Candidate
+	for batch := range out {
+		found = append(found, batch...)
+	}
+	if err := (<-done).Err; err != nil {
+		t.Fatalf("StartProducer error: %v", err)
+	}
+	if len(found) != 1 || found[0].LangID != candidate.LangCPP {
+		t.Fatalf("indexed %+v, want one C++ candidate", found)
+	}
+
+	m := newModel(config{Root: root, Preview: true}, nil, nil, nil)
+	m.width, m.height = 120, 30
+	m.appendCandidate(found[0])
+	m.updatePreview()
+	if m.preview.Lang != found[0].LangID {
+		t.Fatalf("preview language = %q, index language = %q", m.preview.Lang, found[0].LangID)
+	}
+}
@@ -39,6 +39,40 @@ func (m model) renderHeader() string {
 		scanState = "done"
 	}
 	status := fmt.Sprintf("%s | candidates %d | visible %d", scanState, len(m.candidates), len(m.filtered))
+	projectRoots, depRoots := 0, 0
+	for _, root := range m.roots {
+		if root.Dependency != "" {
+			depRoots++
+		} else {
+			projectRoots++
+		}
+	}
+	if projectRoots > 1 {
+		status += fmt.Sprintf(" | roots %d", projectRoots)
+	}
+	if depRoots > 0 {
+		status += fmt.Sprintf(" | deps %d", depRoots)
+	}
+	if m.cfg.Rev != "" {
+		status += " | rev " + m.cfg.Rev
+	}
+	if m.diff != nil {
+		status += " | diff " + m.diff.From + ".." + m.diff.To
+	}
+	if m.producerCfg.ExcludeGenerated {
+		status += " | generated excluded"
+	} else if m.hideGenerated {
+		status += " | generated hidden"
+	}
+	if m.hideTests {
+		status += " | tests hidden"
+	}
+	if m.producerCfg.NoIgnore {
+		status += " | ignored files included"
+	}
+	if m.producerCfg.Hidden {
+		status += " | hidden files included"
+	}
 	if m.status != "" {
 		status += " | " + m.status
 	}
@@ -53,7 +87,10 @@ func (m model) renderHeader() string {
 
 func (m model) renderFooter() string {
 	footerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(appTheme.Muted))
-	text := "up/down move  pgup/pgdn jump  tab preview  ctrl+space copy  enter open file  esc quit"
+	text := "up/down move  pgup/pgdn jump  tab preview  ctrl+g generated  ctrl+t tests  ctrl+o ignored  ctrl+r hidden  ctrl+space copy  enter open file  esc quit"
+	if m.diff != nil {
+		text = "up/down move  pgup/pgdn jump  tab preview  ctrl+g generated  ctrl+t tests  ctrl+space copy  enter open file  esc quit"
+	}
 	return footerStyle.Render(truncateText(text, m.width))
 }
 
@@ -77,10 +114,11 @@ func (m model) renderList(width int, height int) string {
 		if !ok {
 			continue
 		}
-		lineA, lineB := m.renderCandidateLines(cand, i == m.cursor, width)
-		lines = append(lines, lineA)
-		if len(lines) < height {
-			lines = append(lines, lineB)
+		for _, line := range m.renderCandidateLines(cand, i == m.cursor, width) {
+			if len(lines) >= height {
+				break
+			}
+			lines = append(lines, line)
 		}
 		if len(lines) >= height {
 			break
@@ -114,50 +152,86 @@ func (m model) candidateForFiltered(i int) (candidate.Candidate, bool) {
 	return cand, true
 }
 
-func (m model) renderCandidateLines(cand candidate.Candidate, selected bool, width int) (string, string) {
-	lineA := renderLocationLine(cand.File, cand.Line, cand.Col, width, selected, m.queryRunes)
+// renderCandidateLines renders the location and code lines of a candidate,
+// followed by its doc comment summary when it has one.
+func (m model) renderCandidateLines(cand candidate.Candidate, selected bool, width int) []string {
+	query, docQuery := m.queryRunes, []rune(nil)
+	if _, rest, ok := candidate.CutRootScope(query); ok {
+		query = rest
+	}
+	if _, rest, ok := candidate.CutChangeScope(query); ok {
+		query = rest
+	}
+	if scoped, ok := candidate.CutDocScope(query); ok {
+		query, docQuery = nil, scoped
+	}
 
-	text := truncateText(cand.Text, width)
-	req := m.highlightRequest(cand.LangID, cand.File, cand.Line, text)
-	spans := m.lookupHighlightSpans(req)
+	lineA := renderLocationLine(cand.File, cand.Line, cand.Col, width, selected, query)
+	if meta := candidateMeta(cand); meta != "" {
+		lineA += renderMetaSuffix(meta, width-lipgloss.Width(lineA), selected)
+	}
 
-	lineB := renderTokenLine(text, spans, selected, m.queryRunes)
-	lineA = padRightANSI(lineA, width)
-	lineB = padRightANSI(lineB, width)
+	req := m.candidateHighlightRequest(cand, width)
+	text := req.Text
+	spans := m.lookupHighlightSpans(req)
 
-	return lineA, lineB
+	lineB := renderTokenLine(text, spans, selected, query)
+	lines := []string{padRightANSI(lineA, width), padRightANSI(lineB, width)}
+	if cand.Doc != "" {
+		lines = append(lines, padRightANSI(renderDocLine(cand.Doc, width, selected, docQuery), width))
+	}
+	return lines
 }
 
 func (m model) renderPreview(width int, height int) string {
 	if width <= 0 || height <= 0 {
 		return ""
 	}
+	if m.diff != nil {
+		top := max(1, height/2)
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.renderPreviewPane(m.diffPreview, "before  "+m.diff.From, width, top),
+			m.renderPreviewPane(m.preview, "after  "+m.diff.To, width, height-top),
+		)
+	}
+	return m.renderPreviewPane(m.preview, "preview", width, height)
+}
 
+// renderPreviewPane renders the lines of p under a header that starts with
+// title. Lines of a preview with no Path are highlighted on their own.
+func (m model) renderPreviewPane(p previewState, title string, width int, height int) string {
 	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(appTheme.Header)).Bold(true)
 	numStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(appTheme.Dim))
 
-	if m.preview.Err != "" {
+	if p.Err != "" {
 		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(appTheme.Error))
-		msg := headerStyle.Render("preview") + "\n" + errStyle.Render(truncateText(m.preview.Err, width))
+		msg := headerStyle.Render(truncateText(title, width)) + "\n" + errStyle.Render(truncateText(p.Err, width))
 		return lipgloss.NewStyle().Width(width).Height(height).Render(msg)
 	}
-	if len(m.preview.Lines) == 0 {
+	if len(p.Lines) == 0 {
 		return lipgloss.NewStyle().Width(width).Height(height).Render("")
 	}
 
 	lines := make([]string, 0, height)
-	lines = append(lines, headerStyle.Render(truncateText("preview  "+m.preview.File, width)))
+	header := title + "  " + p.File
+	if p.Root != "" {
+		header = title + "  [" + p.Root + "] " + p.File
+	}
+	if p.Cell > 0 {
+		header += fmt.Sprintf("  cell %d", p.Cell)
+	}
+	lines = append(lines, headerStyle.Render(truncateText(header, width)))
 
 	avail := height - 1
 	maxCode := max(0, width-7)
-	for i := 0; i < avail && i < len(m.preview.Lines); i++ {
-		lineNo := m.preview.StartLine + i
+	for i := 0; i < avail && i < len(p.Lines); i++ {
+		lineNo := p.StartLine + i
 		prefix := fmt.Sprintf("%6d ", lineNo)
 		prefixRendered := numStyle.Render(prefix)
 
-		selected := lineNo == m.preview.SelectedLine
-		text := truncateText(m.preview.Lines[i], maxCode)
-		req := m.highlightRequest(m.preview.Lang, m.preview.File, lineNo, text)
+		selected := lineNo == p.SelectedLine
+		text := truncateText(p.Lines[i], maxCode)
+		req := m.highlightRequest(p.lineLang(lineNo), p.Path, p.Cell, lineNo, text)
 		spans := m.lookupHighlightSpans(req)
 		code := renderTokenLine(text, spans, selected, nil)
 		lines = append(lines, prefixRendered+padRightANSI(code, maxCode))
@@ -177,13 +251,26 @@ func (m model) selectedCandidate() (candidate.Candidate, bool) {
 	return m.candidateForFiltered(m.cursor)
 }
 
-func (m model) highlightRequest(lang highlighter.LangID, file string, line int, text string) highlighter.HighlightRequest {
+// candidateHighlightRequest builds the request for the code line of a list
+// entry: the matched line, or the collapsed signature when there is one.
+// A signature is not a line of the file, so it is highlighted on its own.
+func (m model) candidateHighlightRequest(cand candidate.Candidate, width int) highlighter.HighlightRequest {
+	if cand.Signature != "" {
+		return m.highlightRequest(cand.LangID, m.candidatePath(cand), cand.Cell, 0, truncateText(cand.Signature, width))
+	}
+	return m.highlightRequest(cand.LangID, m.candidatePath(cand), cand.Cell, cand.Line, truncateText(cand.Text, width))
+}
+
+// highlightRequest builds a request for one line. Lines of notebook cells,
+// and text with no line, are highlighted on their own, since the file around
+// them is notebook JSON or does not contain them.
+func (m model) highlightRequest(lang highlighter.LangID, file string, cell int, line int, text string) highlighter.HighlightRequest {
 	req := highlighter.HighlightRequest{
 		Lang: lang,
 		Text: text,
 		Mode: m.cfg.HighlightMode,
 	}
-	if m.cfg.HighlightMode == highlighter.HighlightContextFile {
+	if m.cfg.HighlightMode == highlighter.HighlightContextFile && cell == 0 && line > 0 {
 		req.File = file
 		req.Line = line
 	}
@@ -204,10 +291,34 @@ func (m model) rowsPerPage() int {
 	return m.rowsPerPageWithHeight(listH)
 }
 
+// rowsPerPageWithHeight is the most candidates a list of height h can show.
+// Candidates with a doc summary take a third line, so fewer may fit.
 func (m model) rowsPerPageWithHeight(h int) int {
 	return max(1, h/2)
 }
 
+// rowHeight is the number of list lines the filtered candidate i takes.
+func (m model) rowHeight(i int) int {
+	cand, ok := m.candidateForFiltered(i)
+	if ok && cand.Doc != "" {
+		return 3
+	}
+	return 2
+}
+
+// rowsFit reports whether filtered candidates start through end-1 fit in a
+// list of height h.
+func (m model) rowsFit(start int, end int, h int) bool {
+	used := 0
+	for i := start; i < end; i++ {
+		used += m.rowHeight(i)
+		if used > h {
+			return false
+		}
+	}
+	return true
+}
+
 func (m model) layout() (listWidth int, listHeight int, previewWidth int, previewHeight int) {
 	headerHeight := 2
 	footerHeight := 1
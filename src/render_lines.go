@@ -115,6 +115,71 @@ func formatLocationWithVisibleFilename(path string, line int, col int, width int
 	return loc, fileStart, fileEnd
 }
 
+// candidateMeta describes what kind of symbol a candidate is, where it lives
+// and how it is decorated, for display after its location.
+func candidateMeta(cand candidate.Candidate) string {
+	parts := make([]string, 0, 6)
+	if cand.Change != "" {
+		parts = append(parts, string(cand.Change))
+	}
+	if cand.Root != "" {
+		parts = append(parts, "["+cand.Root+"]")
+	}
+	if cand.Kind != candidate.KindCode {
+		parts = append(parts, string(cand.Kind))
+	}
+	if cand.Cell > 0 {
+		parts = append(parts, fmt.Sprintf("cell %d", cand.Cell))
+	}
+	if cand.Container != "" {
+		parts = append(parts, "in "+cand.Container)
+	}
+	if cand.Annotations != "" {
+		parts = append(parts, cand.Annotations)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// renderDocLine renders the doc comment summary shown under a candidate.
+func renderDocLine(doc string, width int, selected bool, queryRunes []rune) string {
+	text := truncateText("  "+doc, width)
+	runes := []rune(text)
+	emphasis := buildEmphasisMask(len(runes), candidate.FuzzyPositionsRunes(text, queryRunes))
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color(appTheme.Dim)).Italic(true)
+	if selected {
+		style = style.Background(lipgloss.Color(appTheme.SelectionBG))
+	}
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		emph := emphasisAt(emphasis, i)
+		j := i + 1
+		for j < len(runes) && emphasisAt(emphasis, j) == emph {
+			j++
+		}
+		segment := style
+		if emph {
+			segment = segment.Bold(true).Underline(true)
+		}
+		b.WriteString(segment.Render(string(runes[i:j])))
+		i = j
+	}
+	return b.String()
+}
+
+func renderMetaSuffix(meta string, width int, selected bool) string {
+	if meta == "" || width <= len("  ")+1 {
+		return ""
+	}
+	text := truncateText("  "+meta, width)
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color(appTheme.Dim))
+	if selected {
+		style = style.Background(lipgloss.Color(appTheme.SelectionBG))
+	}
+	return style.Render(text)
+}
+
 func renderTokenLine(text string, spans []highlighter.Span, selected bool, queryRunes []rune) string {
 	runes := []rune(text)
 	if len(runes) == 0 {
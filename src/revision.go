@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"snav/internal/gitrev"
+)
+
+// resolveRevisions sets the commit that rev names in the repository of each
+// root, so that the roots are indexed and previewed as of that commit.
+func resolveRevisions(ctx context.Context, roots []workspaceRoot, rev string) error {
+	for i := range roots {
+		commit, err := gitrev.Resolve(ctx, roots[i].Path, rev)
+		if err != nil {
+			return fmt.Errorf("%s: %w", roots[i].Path, err)
+		}
+		roots[i].Rev = commit
+	}
+	return nil
+}
+
+// rootOf returns the root holding an absolute path and the path within it.
+// Dependency roots nested in a project root take precedence over it.
+func rootOf(roots []workspaceRoot, abs string) (workspaceRoot, string, bool) {
+	var best workspaceRoot
+	bestRel := ""
+	found := false
+	for _, root := range roots {
+		rel, err := filepath.Rel(root.Path, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if !found || len(root.Path) > len(best.Path) {
+			best, bestRel, found = root, rel, true
+		}
+	}
+	return best, bestRel, found
+}
+
+// readRootFile reads the file at an absolute path. Files of a root indexed
+// at a revision are read from that commit rather than the working tree.
+func readRootFile(ctx context.Context, roots []workspaceRoot, abs string) ([]byte, error) {
+	root, rel, ok := rootOf(roots, abs)
+	if !ok || root.Rev == "" {
+		return os.ReadFile(abs)
+	}
+	return gitrev.ReadFile(ctx, root.Path, root.Rev, filepath.ToSlash(rel))
+}
+
+// materializeRevFile writes the file at an absolute path, as of its root's
+// revision, to a read-only temp file that an editor can open. The temp path
+// keeps the file's relative path so editors still detect its language.
+func materializeRevFile(ctx context.Context, roots []workspaceRoot, abs string) (string, error) {
+	root, rel, ok := rootOf(roots, abs)
+	if !ok || root.Rev == "" {
+		return abs, nil
+	}
+	data, err := gitrev.ReadFile(ctx, root.Path, root.Rev, filepath.ToSlash(rel))
+	if err != nil {
+		return "", err
+	}
+
+	commit := root.Rev
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	path := filepath.Join(os.TempDir(), "snav-rev", filepath.Base(root.Path)+"-"+commit, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("create revision file: %w", err)
+	}
+	_ = os.Remove(path)
+	if err := os.WriteFile(path, data, 0o444); err != nil {
+		return "", fmt.Errorf("write revision file: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestRevisionRootsReadFilesFromTheCommit(t *testing.T) {
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=snav", "-c", "user.email=snav@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	path := filepath.Join(repo, "pkg", "server.go")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("package pkg\n\nfunc Serve() {}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "first")
+	if err := os.WriteFile(path, []byte("package pkg\n\nfunc Listen() {}\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ctx := context.Background()
+	roots := []workspaceRoot{{Path: repo}}
+	if err := resolveRevisions(ctx, roots, "HEAD"); err != nil {
+		t.Fatalf("resolveRevisions: %v", err)
+	}
+	if roots[0].Rev == "" {
+		t.Fatalf("expected HEAD to resolve to a commit")
+	}
+	if err := resolveRevisions(ctx, []workspaceRoot{{Path: repo}}, "no-such-branch"); err == nil {
+		t.Fatalf("expected an unknown revision to fail")
+	}
+
+	m := newModel(config{Root: repo}, nil, nil, nil)
+	m.roots = roots
+	lines, err := m.loadFile("pkg/server.go")
+	if err != nil || len(lines) < 3 || lines[2] != "func Serve() {}" {
+		t.Fatalf("loadFile = %q, %v, want the committed file", lines, err)
+	}
+
+	opened, err := materializeRevFile(ctx, roots, path)
+	if err != nil {
+		t.Fatalf("materializeRevFile: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(filepath.Dir(filepath.Dir(opened))) })
+	if opened == path || filepath.Base(opened) != "server.go" {
+		t.Fatalf("materializeRevFile = %s, want a temp copy named server.go", opened)
+	}
+	data, err := os.ReadFile(opened)
+	if err != nil || string(data) != "package pkg\n\nfunc Serve() {}\n" {
+		t.Fatalf("temp copy = %q, %v", data, err)
+	}
+
+	working, err := materializeRevFile(ctx, []workspaceRoot{{Path: repo}}, path)
+	if err != nil || working != path {
+		t.Fatalf("materializeRevFile without a revision = %s, %v, want the working file", working, err)
+	}
+}
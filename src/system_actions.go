@@ -8,11 +8,11 @@ import (
 	"strings"
 )
 
-func openLocation(path string, line int, col int, editorCmd string) error {
+func openLocation(path string, line int, col int, cell int, editorCmd string) error {
 	target := fmt.Sprintf("%s:%d:%d", path, line, col)
 
 	if strings.TrimSpace(editorCmd) != "" {
-		name, args, err := buildEditorCommand(editorCmd, path, line, col, target)
+		name, args, err := buildEditorCommand(editorCmd, path, line, col, cell, target)
 		if err != nil {
 			return err
 		}
@@ -30,7 +30,7 @@ func openLocation(path string, line int, col int, editorCmd string) error {
 	return unavailable
 }
 
-func buildEditorCommand(template string, file string, line int, col int, target string) (string, []string, error) {
+func buildEditorCommand(template string, file string, line int, col int, cell int, target string) (string, []string, error) {
 	parts, err := splitCommandLine(strings.TrimSpace(template))
 	if err != nil {
 		return "", nil, err
@@ -44,6 +44,7 @@ func buildEditorCommand(template string, file string, line int, col int, target
 		"{line}":   fmt.Sprintf("%d", line),
 		"{col}":    fmt.Sprintf("%d", col),
 		"{target}": target,
+		"{cell}":   fmt.Sprintf("%d", cell),
 	}
 
 	for i := range parts {
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"snav/internal/candidate"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mattn/go-runewidth"
 )
@@ -58,6 +60,23 @@ func shouldUseIncrementalFilter(current []rune, previous []rune, candidateN int,
 	if candidateN != previousCandidateN {
 		return false
 	}
+	// Typing a scope prefix changes which fields match, so the earlier
+	// results are not a superset.
+	_, currentRest, currentRootScoped := candidate.CutRootScope(current)
+	_, previousRest, previousRootScoped := candidate.CutRootScope(previous)
+	if currentRootScoped != previousRootScoped {
+		return false
+	}
+	_, currentRest, currentChangeScoped := candidate.CutChangeScope(currentRest)
+	_, previousRest, previousChangeScoped := candidate.CutChangeScope(previousRest)
+	if currentChangeScoped != previousChangeScoped {
+		return false
+	}
+	_, currentScoped := candidate.CutDocScope(currentRest)
+	_, previousScoped := candidate.CutDocScope(previousRest)
+	if currentScoped != previousScoped {
+		return false
+	}
 	return slices.Equal(current[:len(previous)], previous)
 }
 
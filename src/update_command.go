@@ -17,15 +17,20 @@ const updateInstallScriptURL = "https://raw.githubusercontent.com/oomathias/snav
 type updateInstallRunner func(context.Context, string, io.Writer, io.Writer) error
 
 func maybeHandleCommand(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (bool, error) {
-	if len(args) == 0 || args[0] != "update" {
+	if len(args) == 0 {
 		return false, nil
 	}
-
-	executable, err := os.Executable()
-	if err != nil {
-		return true, fmt.Errorf("resolve executable: %w", err)
+	switch args[0] {
+	case "update":
+		executable, err := os.Executable()
+		if err != nil {
+			return true, fmt.Errorf("resolve executable: %w", err)
+		}
+		return true, runUpdateCommand(ctx, args[1:], stdout, stderr, executable, runUpdateInstaller)
+	case "diff":
+		return true, runDiffCommand(ctx, args[1:], stdout, stderr)
 	}
-	return true, runUpdateCommand(ctx, args[1:], stdout, stderr, executable, runUpdateInstaller)
+	return false, nil
 }
 
 func runUpdateCommand(
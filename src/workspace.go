@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"snav/internal/deps"
+)
+
+// dependencyTimeout bounds how long resolving dependencies may take. They
+// are resolved in the background while the project is searchable.
+const dependencyTimeout = 30 * time.Second
+
+// workspaceRoot is one search root. Name labels its candidates and is empty
+// when snav searches a single root. Dependency is the name@version of a
+// dependency root, and Rev the commit indexed instead of the working tree.
+type workspaceRoot struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Dependency string `json:"-"`
+	Rev        string `json:"-"`
+}
+
+type workspaceFile struct {
+	Roots []workspaceRoot `json:"roots"`
+}
+
+// loadWorkspace reads a workspace file listing roots by name and path.
+// Relative paths are resolved against the file's directory.
+func loadWorkspace(path string) ([]workspaceRoot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read workspace: %w", err)
+	}
+	var ws workspaceFile
+	if err := json.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("parse workspace %s: %w", path, err)
+	}
+	if len(ws.Roots) == 0 {
+		return nil, fmt.Errorf("workspace %s lists no roots", path)
+	}
+
+	dir := filepath.Dir(path)
+	roots := make([]workspaceRoot, 0, len(ws.Roots))
+	for i, root := range ws.Roots {
+		if strings.TrimSpace(root.Path) == "" {
+			return nil, fmt.Errorf("workspace %s: root %d has no path", path, i+1)
+		}
+		if !filepath.IsAbs(root.Path) {
+			root.Path = filepath.Join(dir, root.Path)
+		}
+		roots = append(roots, root)
+	}
+	return roots, nil
+}
+
+// resolveRoots combines the workspace roots and positional root arguments,
+// falling back to fallback when there are neither. Paths are made absolute
+// and, with more than one root, every root gets a unique name.
+func resolveRoots(workspace string, args []string, fallback string) ([]workspaceRoot, error) {
+	var roots []workspaceRoot
+	if workspace != "" {
+		loaded, err := loadWorkspace(workspace)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, loaded...)
+	}
+	for _, arg := range args {
+		roots = append(roots, workspaceRoot{Path: arg})
+	}
+	if len(roots) == 0 {
+		roots = append(roots, workspaceRoot{Path: fallback})
+	}
+
+	seen := make(map[string]bool, len(roots))
+	for i := range roots {
+		abs, err := filepath.Abs(roots[i].Path)
+		if err != nil {
+			return nil, fmt.Errorf("resolve root %s: %w", roots[i].Path, err)
+		}
+		roots[i].Path = abs
+		if len(roots) == 1 {
+			roots[i].Name = ""
+			continue
+		}
+
+		name := strings.TrimSpace(roots[i].Name)
+		if name == "" {
+			name = filepath.Base(abs)
+		}
+		name = strings.ReplaceAll(name, " ", "-")
+		unique := name
+		for n := 2; seen[unique]; n++ {
+			unique = name + "-" + strconv.Itoa(n)
+		}
+		seen[unique] = true
+		roots[i].Name = unique
+	}
+	return roots, nil
+}
+
+// dependencyRoots resolves the dependencies of every root to roots labelled
+// with the package name, adding the version when two versions of a package
+// are found. Roots whose dependencies cannot be resolved are reported in the
+// error while the others are still returned.
+func dependencyRoots(ctx context.Context, roots []workspaceRoot) ([]workspaceRoot, error) {
+	ctx, cancel := context.WithTimeout(ctx, dependencyTimeout)
+	defer cancel()
+
+	taken := make(map[string]bool, len(roots))
+	for _, root := range roots {
+		taken[root.Name] = true
+	}
+	seenDirs := make(map[string]bool)
+	var out []workspaceRoot
+	var errs []error
+	for _, root := range roots {
+		found, err := deps.Resolve(ctx, root.Path)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, dep := range found {
+			dir := filepath.Clean(dep.Dir)
+			if seenDirs[dir] {
+				continue
+			}
+			seenDirs[dir] = true
+
+			id := dep.Name + "@" + dep.Version
+			name := strings.ReplaceAll(dep.Name, " ", "-")
+			if taken[name] {
+				name = strings.ReplaceAll(id, " ", "-")
+			}
+			for n := 2; taken[name]; n++ {
+				name = strings.ReplaceAll(id, " ", "-") + "-" + strconv.Itoa(n)
+			}
+			taken[name] = true
+			out = append(out, workspaceRoot{Name: name, Path: dir, Dependency: id})
+		}
+	}
+	return out, errors.Join(errs...)
+}
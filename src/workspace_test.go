@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveRootsFromWorkspaceAndArgs(t *testing.T) {
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "product.json")
+	content := `{"roots": [{"name": "api", "path": "services/api"}, {"path": "/srv/web"}]}`
+	if err := os.WriteFile(workspace, []byte(content), 0o644); err != nil {
+		t.Fatalf("write workspace: %v", err)
+	}
+
+	got, err := resolveRoots(workspace, []string{"/src/api"}, ".")
+	if err != nil {
+		t.Fatalf("resolveRoots: %v", err)
+	}
+	want := []workspaceRoot{
+		{Name: "api", Path: filepath.Join(dir, "services", "api")},
+		{Name: "web", Path: "/srv/web"},
+		{Name: "api-2", Path: "/src/api"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("roots = %#v, want %#v", got, want)
+	}
+}
+
+func TestResolveRootsSingleRootHasNoName(t *testing.T) {
+	got, err := resolveRoots("", nil, "/repo/project")
+	if err != nil {
+		t.Fatalf("resolveRoots: %v", err)
+	}
+	want := []workspaceRoot{{Path: "/repo/project"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("roots = %#v, want %#v", got, want)
+	}
+}
+
+func TestLoadWorkspaceRejectsRootWithoutPath(t *testing.T) {
+	workspace := filepath.Join(t.TempDir(), "ws.json")
+	if err := os.WriteFile(workspace, []byte(`{"roots": [{"name": "api"}]}`), 0o644); err != nil {
+		t.Fatalf("write workspace: %v", err)
+	}
+	if _, err := loadWorkspace(workspace); err == nil {
+		t.Fatalf("expected an error for a root without a path")
+	}
+}
+
+func TestDependencyRootsLabelsPackages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"package.json":                    `{"dependencies": {"react": "^18.0.0", "api": "1.0.0"}}`,
+		"node_modules/react/package.json": `{"version": "18.2.0"}`,
+		"node_modules/api/package.json":   `{"version": "1.0.0"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	got, err := dependencyRoots(context.Background(), []workspaceRoot{{Name: "api", Path: dir}})
+	if err != nil {
+		t.Fatalf("dependencyRoots: %v", err)
+	}
+	want := []workspaceRoot{
+		{Name: "api@1.0.0", Path: filepath.Join(dir, "node_modules", "api"), Dependency: "api@1.0.0"},
+		{Name: "react", Path: filepath.Join(dir, "node_modules", "react"), Dependency: "react@18.2.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("roots = %#v, want %#v", got, want)
+	}
+}